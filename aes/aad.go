@@ -0,0 +1,125 @@
+package aes
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// AADVersion is the version of the canonical encoding produced by AAD
+const AADVersion = 1
+
+const (
+	aadKindString byte = iota + 1
+	aadKindInt64
+	aadKindBytes
+)
+
+type (
+	// AAD is a builder of additional authenticated data that binds a ciphertext to its context (e.g., row ID,
+	// tenant ID, column name). Entries are canonically encoded, so the insertion order does not matter
+	AAD struct {
+		entries map[string]aadEntry
+	}
+
+	// aadEntry is a typed value of an AAD entry
+	aadEntry struct {
+		kind  byte
+		value []byte
+	}
+)
+
+// NewAAD creates a new empty AAD builder
+//
+// Returns:
+//
+//   - A pointer to the AAD builder
+func NewAAD() *AAD {
+	return &AAD{
+		entries: make(map[string]aadEntry),
+	}
+}
+
+// SetString sets a string value for the given key, replacing any previous value
+//
+// Parameters:
+//
+//   - key: The context key
+//   - value: The context value
+//
+// Returns:
+//
+//   - The AAD builder
+func (a *AAD) SetString(key, value string) *AAD {
+	a.entries[key] = aadEntry{kind: aadKindString, value: []byte(value)}
+	return a
+}
+
+// SetInt64 sets an integer value for the given key, replacing any previous value
+//
+// Parameters:
+//
+//   - key: The context key
+//   - value: The context value
+//
+// Returns:
+//
+//   - The AAD builder
+func (a *AAD) SetInt64(key string, value int64) *AAD {
+	encoded := make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, uint64(value))
+	a.entries[key] = aadEntry{kind: aadKindInt64, value: encoded}
+	return a
+}
+
+// SetBytes sets a byte slice value for the given key, replacing any previous value
+//
+// Parameters:
+//
+//   - key: The context key
+//   - value: The context value
+//
+// Returns:
+//
+//   - The AAD builder
+func (a *AAD) SetBytes(key string, value []byte) *AAD {
+	a.entries[key] = aadEntry{
+		kind:  aadKindBytes,
+		value: append([]byte(nil), value...),
+	}
+	return a
+}
+
+// Bytes returns the canonical encoding of the context, to be used as additional authenticated data
+//
+// The encoding is: version (1 byte) || entry count (4 bytes) || entries sorted by key, each encoded as
+// key length (4 bytes) || key || value type (1 byte) || value length (4 bytes) || value
+//
+// Returns:
+//
+//   - The canonically encoded additional authenticated data
+func (a *AAD) Bytes() []byte {
+	// Sort the keys so the encoding does not depend on the insertion order
+	keys := make([]string, 0, len(a.entries))
+	for key := range a.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Encode the version and the entry count
+	encoded := []byte{AADVersion}
+	encoded = binary.BigEndian.AppendUint32(encoded, uint32(len(keys)))
+
+	// Encode each entry with length prefixes, so no two contexts share an encoding
+	for _, key := range keys {
+		entry := a.entries[key]
+		encoded = binary.BigEndian.AppendUint32(encoded, uint32(len(key)))
+		encoded = append(encoded, key...)
+		encoded = append(encoded, entry.kind)
+		encoded = binary.BigEndian.AppendUint32(
+			encoded,
+			uint32(len(entry.value)),
+		)
+		encoded = append(encoded, entry.value...)
+	}
+	return encoded
+}
@@ -0,0 +1,114 @@
+package aes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestAADInsertionOrder(t *testing.T) {
+	first := NewAAD().
+		SetString("table", "users").
+		SetInt64("row", 42).
+		SetBytes("tenant", []byte{1, 2, 3})
+	second := NewAAD().
+		SetBytes("tenant", []byte{1, 2, 3}).
+		SetInt64("row", 42).
+		SetString("table", "users")
+
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Fatalf("AAD.Bytes depends on the insertion order: %x != %x", first.Bytes(), second.Bytes())
+	}
+}
+
+func TestAADEncoding(t *testing.T) {
+	got := NewAAD().SetString("a", "bc").Bytes()
+	want := mustDecodeHex(t, "01"+"00000001"+"00000001"+"61"+"01"+"00000002"+"6263")
+	if !bytes.Equal(got, want) {
+		t.Fatalf("AAD.Bytes = %x, want %x", got, want)
+	}
+}
+
+func TestAADNoCollisions(t *testing.T) {
+	tests := []struct {
+		name   string
+		first  *AAD
+		second *AAD
+	}{
+		{
+			name:   "key and value boundary",
+			first:  NewAAD().SetString("a", "bc"),
+			second: NewAAD().SetString("ab", "c"),
+		},
+		{
+			name:   "entry boundary",
+			first:  NewAAD().SetString("a", "b").SetString("c", "d"),
+			second: NewAAD().SetString("a", "b\x01c"),
+		},
+		{
+			name:   "value type",
+			first:  NewAAD().SetString("a", "bc"),
+			second: NewAAD().SetBytes("a", []byte("bc")),
+		},
+		{
+			name:   "empty value",
+			first:  NewAAD(),
+			second: NewAAD().SetString("", ""),
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if bytes.Equal(test.first.Bytes(), test.second.Bytes()) {
+					t.Fatalf("different contexts share the encoding %x", test.first.Bytes())
+				}
+			},
+		)
+	}
+}
+
+func TestAADReplacesValue(t *testing.T) {
+	got := NewAAD().SetString("row", "1").SetString("row", "2").Bytes()
+	want := NewAAD().SetString("row", "2").Bytes()
+	if !bytes.Equal(got, want) {
+		t.Fatalf("AAD.Bytes = %x, want %x", got, want)
+	}
+}
+
+func TestAADWrongContext(t *testing.T) {
+	key := randomBytes(t, 32)
+	plainText := []byte("secret")
+	context := NewAAD().SetString("table", "users").SetInt64("row", 42)
+
+	encryptedText, err := EncryptGCMWithAAD(plainText, key, context.Bytes())
+	if err != nil {
+		t.Fatalf("EncryptGCMWithAAD: %v", err)
+	}
+	decryptedText, err := DecryptGCMWithAAD(encryptedText, key, context.Bytes())
+	if err != nil {
+		t.Fatalf("DecryptGCMWithAAD: %v", err)
+	}
+	if *decryptedText != string(plainText) {
+		t.Fatalf("DecryptGCMWithAAD = %q, want %q", *decryptedText, plainText)
+	}
+
+	for _, wrongContext := range []*AAD{
+		NewAAD().SetString("table", "users").SetInt64("row", 43),
+		NewAAD().SetString("table", "users"),
+		NewAAD().SetString("table", "users").SetString("row", "42"),
+	} {
+		if _, err = DecryptGCMWithAAD(
+			encryptedText,
+			key,
+			wrongContext.Bytes(),
+		); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf("DecryptGCMWithAAD with the wrong context: got %v, want %v", err, ErrAuthenticationFailed)
+		}
+	}
+	if _, err = DecryptGCM(encryptedText, key); !errors.Is(
+		err,
+		ErrAuthenticationFailed,
+	) {
+		t.Fatalf("DecryptGCM without the context: got %v, want %v", err, ErrAuthenticationFailed)
+	}
+}
@@ -0,0 +1,264 @@
+package aes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"io"
+)
+
+const (
+	// CBCHMACTagSize is the size in bytes of the HMAC-SHA256 tag appended by the authenticated CBC mode
+	CBCHMACTagSize = sha256.Size
+
+	// cbcIVSize is the size in bytes of the IV used with the CBC block cipher mode
+	cbcIVSize = aes.BlockSize
+)
+
+// PKCS7Pad pads the data to a multiple of the block size as specified by PKCS #7 (RFC 5652). A full block of padding
+// is added when the data is already a multiple of the block size
+//
+// Parameters:
+//
+//   - data: The data to pad
+//   - blockSize: The block size (must be between 1 and 255)
+//
+// Returns:
+//
+//   - A padded copy of the data
+//   - ErrInvalidPadding if the block size is not valid
+func PKCS7Pad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize < 1 || blockSize > 255 {
+		return nil, newError("PKCS7Pad", ErrInvalidPadding)
+	}
+
+	paddingLength := blockSize - len(data)%blockSize
+	padded := make([]byte, len(data)+paddingLength)
+	copy(padded, data)
+	for i := len(data); i < len(padded); i++ {
+		padded[i] = byte(paddingLength)
+	}
+	return padded, nil
+}
+
+// PKCS7Unpad removes the PKCS #7 padding of the data. The padding is checked in constant time, so the time taken does
+// not reveal where the padding is invalid
+//
+// Parameters:
+//
+//   - data: The padded data
+//   - blockSize: The block size (must be between 1 and 255)
+//
+// Returns:
+//
+//   - The data without the padding, which shares the storage of the padded data
+//   - ErrInvalidPadding if the block size or the padding is not valid
+func PKCS7Unpad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize < 1 || blockSize > 255 || len(data) == 0 || len(data)%blockSize != 0 {
+		return nil, newError("PKCS7Unpad", ErrInvalidPadding)
+	}
+
+	// The padding length must be between 1 and the block size
+	paddingLength := int(data[len(data)-1])
+	valid := subtle.ConstantTimeLessOrEq(1, paddingLength) & subtle.ConstantTimeLessOrEq(
+		paddingLength,
+		blockSize,
+	)
+
+	// Every byte of the last block that belongs to the padding must be equal to the padding length
+	for i := 1; i <= blockSize; i++ {
+		isPadding := subtle.ConstantTimeLessOrEq(i, paddingLength)
+		matches := subtle.ConstantTimeByteEq(
+			data[len(data)-i],
+			byte(paddingLength),
+		)
+		valid &= subtle.ConstantTimeSelect(isPadding, matches, 1)
+	}
+	if valid != 1 {
+		return nil, newError("PKCS7Unpad", ErrInvalidPadding)
+	}
+	return data[:len(data)-paddingLength], nil
+}
+
+// encryptCBC encrypts the plain text with a random IV using the AES algorithm with the CBC block cipher mode and PKCS
+// #7 padding
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - The IV followed by the cipher text
+//   - An error if any occurred during the encryption process
+func encryptCBC(plainText, key []byte) ([]byte, error) {
+	// Create a new AES cipher block with the given key
+	block, err := newBlock("EncryptCBCHMAC", key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pad the plain text
+	padded, err := PKCS7Pad(plainText, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a new random IV
+	cipherText := make([]byte, cbcIVSize+len(padded))
+	iv := cipherText[:cbcIVSize]
+	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+
+	// Encrypt the padded plain text using the CBC block cipher
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(cipherText[cbcIVSize:], padded)
+	return cipherText, nil
+}
+
+// decryptCBC decrypts a cipher text produced by encryptCBC. It must only be called after the cipher text was
+// authenticated, so the padding check cannot be used as an oracle
+//
+// Parameters:
+//
+//   - cipherText: The IV followed by the cipher text
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - ErrInvalidCiphertextLength if the cipher text is not a multiple of the block size, ErrInvalidPadding if the
+//     padding is not valid, or any other error that occurred during the decryption process
+func decryptCBC(cipherText, key []byte) ([]byte, error) {
+	// Create a new AES cipher block with the given key
+	block, err := newBlock("DecryptCBCHMAC", key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the IV from the cipher text
+	if len(cipherText) < cbcIVSize+aes.BlockSize {
+		return nil, newError("DecryptCBCHMAC", ErrCiphertextTooShort)
+	}
+	if len(cipherText)%aes.BlockSize != 0 {
+		return nil, newError("DecryptCBCHMAC", ErrInvalidCiphertextLength)
+	}
+	iv, cipherText := cipherText[:cbcIVSize], cipherText[cbcIVSize:]
+
+	// Decrypt the cipher text using the CBC block cipher and remove the padding
+	padded := make([]byte, len(cipherText))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(padded, cipherText)
+	return PKCS7Unpad(padded, aes.BlockSize)
+}
+
+// computeCBCHMACTag computes the HMAC-SHA256 tag over the IV followed by the cipher text
+//
+// Parameters:
+//
+//   - macKey: The MAC key
+//   - cipherText: The IV followed by the cipher text
+//
+// Returns:
+//
+//   - The tag
+func computeCBCHMACTag(macKey, cipherText []byte) []byte {
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(cipherText)
+	return mac.Sum(nil)
+}
+
+// EncryptCBCHMAC encrypts a string using the AES algorithm with the CBC block cipher mode and PKCS #7 padding,
+// authenticated with an HMAC-SHA256 tag over the IV and the cipher text (encrypt-then-MAC). This is the construction
+// used by many PHP and Java codebases, and should only be used for interoperability with them
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - encryptionKey: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//   - macKey: The key to use for the HMAC-SHA256 tag (must not be empty)
+//
+// Returns:
+//
+//   - A pointer to the IV followed by the cipher text and the tag, in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptCBCHMAC(plainText, encryptionKey, macKey []byte) (*string, error) {
+	// Check the MAC key
+	if len(macKey) == 0 {
+		return nil, newError("EncryptCBCHMAC", ErrInvalidKeySize)
+	}
+
+	// Encrypt the plain text using the CBC block cipher
+	cipherText, err := encryptCBC(plainText, encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Append the tag and return the encrypted cipher text as a hexadecimal string
+	cipherText = append(cipherText, computeCBCHMACTag(macKey, cipherText)...)
+	enc := hex.EncodeToString(cipherText)
+
+	return &enc, nil
+}
+
+// DecryptCBCHMAC decrypts a string produced by EncryptCBCHMAC, verifying its tag in constant time before decrypting
+// and checking the padding in constant time
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - encryptionKey: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//   - macKey: The key to use for the HMAC-SHA256 tag (must not be empty)
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - ErrAuthenticationFailed if the encrypted text was tampered with, or any other error that occurred during the
+//     decryption process
+func DecryptCBCHMAC(encryptedText *string, encryptionKey, macKey []byte) (
+	*string,
+	error,
+) {
+	// Check if the encrypted text is nil
+	if encryptedText == nil {
+		return nil, newError("DecryptCBCHMAC", ErrNilEncryptedText)
+	}
+
+	// Check the MAC key
+	if len(macKey) == 0 {
+		return nil, newError("DecryptCBCHMAC", ErrInvalidKeySize)
+	}
+
+	// Decode the encrypted text from a hexadecimal string
+	cipherText, err := decodeHex("DecryptCBCHMAC", *encryptedText)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the tag from the cipher text
+	if len(cipherText) < cbcIVSize+aes.BlockSize+CBCHMACTagSize {
+		return nil, newError("DecryptCBCHMAC", ErrCiphertextTooShort)
+	}
+	tagOffset := len(cipherText) - CBCHMACTagSize
+	cipherText, tag := cipherText[:tagOffset], cipherText[tagOffset:]
+
+	// Verify the tag before decrypting
+	if !hmac.Equal(tag, computeCBCHMACTag(macKey, cipherText)) {
+		return nil, newError("DecryptCBCHMAC", ErrAuthenticationFailed)
+	}
+
+	// Decrypt the cipher text using the CBC block cipher
+	plainText, err := decryptCBC(cipherText, encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the decrypted plain text
+	dec := string(plainText)
+
+	return &dec, nil
+}
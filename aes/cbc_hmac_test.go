@@ -0,0 +1,158 @@
+package aes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestPKCS7Pad(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		padded string
+	}{
+		{name: "empty data", data: "", padded: "10101010101010101010101010101010"},
+		{name: "partial block", data: "616263", padded: "6162630d0d0d0d0d0d0d0d0d0d0d0d0d"},
+		{
+			name:   "full block",
+			data:   "000102030405060708090a0b0c0d0e0f",
+			padded: "000102030405060708090a0b0c0d0e0f10101010101010101010101010101010",
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				padded, err := PKCS7Pad(mustDecodeHex(t, test.data), 16)
+				if err != nil {
+					t.Fatalf("PKCS7Pad: %v", err)
+				}
+				if hex.EncodeToString(padded) != test.padded {
+					t.Fatalf("PKCS7Pad = %x, want %s", padded, test.padded)
+				}
+
+				data, err := PKCS7Unpad(padded, 16)
+				if err != nil {
+					t.Fatalf("PKCS7Unpad: %v", err)
+				}
+				if hex.EncodeToString(data) != test.data {
+					t.Fatalf("PKCS7Unpad = %x, want %s", data, test.data)
+				}
+			},
+		)
+	}
+
+	for _, blockSize := range []int{0, 256} {
+		if _, err := PKCS7Pad(nil, blockSize); !errors.Is(err, ErrInvalidPadding) {
+			t.Fatalf("PKCS7Pad with a block size of %d: got %v, want %v", blockSize, err, ErrInvalidPadding)
+		}
+	}
+}
+
+func TestPKCS7UnpadInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		padded string
+	}{
+		{name: "empty data", padded: ""},
+		{name: "not a multiple of the block size", padded: "616263040404"},
+		{name: "padding byte 0", padded: "61626364656667686970717273747500"},
+		{name: "padding byte greater than the block size", padded: "61626364656667686970717273747511"},
+		{name: "padding byte 255", padded: "616263646566676869707172737475ff"},
+		{name: "inconsistent padding", padded: "61626364656667686970717273030403"},
+		{name: "full block with an inconsistent byte", padded: "10101010101010101010101010100f10"},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if _, err := PKCS7Unpad(
+					mustDecodeHex(t, test.padded),
+					16,
+				); !errors.Is(err, ErrInvalidPadding) {
+					t.Fatalf("PKCS7Unpad: got %v, want %v", err, ErrInvalidPadding)
+				}
+			},
+		)
+	}
+}
+
+func TestCBCHMACRoundTrip(t *testing.T) {
+	macKey := randomBytes(t, 32)
+
+	for _, keySize := range []int{16, 24, 32} {
+		key := randomBytes(t, keySize)
+
+		// Plain texts that are shorter than, equal to and longer than a block
+		for _, plainText := range [][]byte{nil, bytes.Repeat([]byte("a"), 16), bytes.Repeat([]byte("b"), 33)} {
+			encryptedText, err := EncryptCBCHMAC(plainText, key, macKey)
+			if err != nil {
+				t.Fatalf("EncryptCBCHMAC: %v", err)
+			}
+			decryptedText, err := DecryptCBCHMAC(encryptedText, key, macKey)
+			if err != nil {
+				t.Fatalf("DecryptCBCHMAC: %v", err)
+			}
+			if *decryptedText != string(plainText) {
+				t.Fatalf("DecryptCBCHMAC = %q, want %q", *decryptedText, plainText)
+			}
+		}
+	}
+}
+
+func TestDecryptCBCHMACInvalid(t *testing.T) {
+	key := randomBytes(t, 32)
+	macKey := randomBytes(t, 32)
+
+	encryptedText, err := EncryptCBCHMAC([]byte("data"), key, macKey)
+	if err != nil {
+		t.Fatalf("EncryptCBCHMAC: %v", err)
+	}
+	cipherText := mustDecodeHex(t, *encryptedText)
+
+	// Flip a bit of the IV, the cipher text and the tag
+	for _, index := range []int{0, cbcIVSize, len(cipherText) - 1} {
+		tampered := bytes.Clone(cipherText)
+		tampered[index] ^= 1
+		tamperedText := hex.EncodeToString(tampered)
+		if _, err = DecryptCBCHMAC(&tamperedText, key, macKey); !errors.Is(
+			err,
+			ErrAuthenticationFailed,
+		) {
+			t.Fatalf("DecryptCBCHMAC with byte %d tampered: got %v, want %v", index, err, ErrAuthenticationFailed)
+		}
+	}
+	if _, err = DecryptCBCHMAC(encryptedText, key, randomBytes(t, 32)); !errors.Is(
+		err,
+		ErrAuthenticationFailed,
+	) {
+		t.Fatalf("DecryptCBCHMAC with the wrong MAC key: got %v, want %v", err, ErrAuthenticationFailed)
+	}
+
+	// An authenticated cipher text that is not a multiple of the block size
+	unaligned := randomBytes(t, cbcIVSize+20)
+	unaligned = append(unaligned, computeCBCHMACTag(macKey, unaligned)...)
+	unalignedText := hex.EncodeToString(unaligned)
+	if _, err = DecryptCBCHMAC(&unalignedText, key, macKey); !errors.Is(
+		err,
+		ErrInvalidCiphertextLength,
+	) {
+		t.Fatalf("DecryptCBCHMAC of an unaligned cipher text: got %v, want %v", err, ErrInvalidCiphertextLength)
+	}
+
+	// A cipher text without a full block
+	short := hex.EncodeToString(cipherText[:cbcIVSize+CBCHMACTagSize])
+	if _, err = DecryptCBCHMAC(&short, key, macKey); !errors.Is(
+		err,
+		ErrCiphertextTooShort,
+	) {
+		t.Fatalf("DecryptCBCHMAC of a short cipher text: got %v, want %v", err, ErrCiphertextTooShort)
+	}
+
+	if _, err = DecryptCBCHMAC(encryptedText, key, nil); !errors.Is(
+		err,
+		ErrInvalidKeySize,
+	) {
+		t.Fatalf("DecryptCBCHMAC without a MAC key: got %v, want %v", err, ErrInvalidKeySize)
+	}
+}
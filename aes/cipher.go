@@ -0,0 +1,551 @@
+package aes
+
+import (
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// Names under which the AES modes are registered as gocrypto ciphers
+const (
+	CipherNameGCM     = "aes-gcm"
+	CipherNameCTRHMAC = "aes-ctr-hmac-sha256"
+	CipherNameSIV     = "aes-siv"
+	CipherNameGCMSIV  = "aes-gcm-siv"
+
+	// CipherNameCTRLegacy is the name of the unauthenticated CTR block cipher mode, which is only registered to
+	// decrypt legacy values. Its name makes the missing authentication explicit wherever it is selected
+	CipherNameCTRLegacy = "aes-ctr-legacy-unauthenticated"
+
+	// CipherNameKeyRing is the name of the cipher backed by a KeyRing, which is not registered since it cannot be
+	// created from a single key
+	CipherNameKeyRing = "aes-keyring"
+)
+
+type (
+	// gcmCipher is the gocrypto.Cipher of the GCM block cipher mode
+	gcmCipher struct {
+		encryptor *GCMEncryptor
+	}
+
+	// ctrCipher is the gocrypto.Cipher of the unauthenticated CTR block cipher mode
+	ctrCipher struct {
+		key []byte
+	}
+
+	// ctrHMACCipher is the gocrypto.Cipher of the authenticated CTR block cipher mode
+	ctrHMACCipher struct {
+		key []byte
+	}
+
+	// sivCipher is the gocrypto.Cipher of AES-SIV
+	sivCipher struct {
+		key []byte
+	}
+
+	// gcmSIVCipher is the gocrypto.Cipher of AES-GCM-SIV
+	gcmSIVCipher struct {
+		key []byte
+	}
+
+	// keyRingCipher is the gocrypto.Cipher backed by a KeyRing
+	keyRingCipher struct {
+		keyRing *KeyRing
+	}
+)
+
+func init() {
+	gocrypto.RegisterCipher(CipherNameGCM, newGCMCipher)
+	gocrypto.RegisterCipher(CipherNameCTRLegacy, newCTRCipher)
+	gocrypto.RegisterCipher(CipherNameCTRHMAC, newCTRHMACCipher)
+	gocrypto.RegisterCipher(CipherNameSIV, newSIVCipher)
+	gocrypto.RegisterCipher(CipherNameGCMSIV, newGCMSIVCipher)
+}
+
+// NewCipher creates the gocrypto.Cipher of the given authenticated algorithm. The unauthenticated CTR block cipher
+// mode is only available through gocrypto.NewCipher with CipherNameCTRLegacy
+//
+// Parameters:
+//
+//   - algorithm: The algorithm
+//   - key: The key to use for encryption and decryption
+//
+// Returns:
+//
+//   - The cipher
+//   - An error if the algorithm is not supported, is not authenticated or the key is invalid
+func NewCipher(algorithm Algorithm, key []byte) (gocrypto.Cipher, error) {
+	switch algorithm {
+	case AlgorithmGCM:
+		return newGCMCipher(key)
+	case AlgorithmCTR:
+		return nil, newError("NewCipher", ErrUnauthenticatedAlgorithm)
+	case AlgorithmCTRHMAC:
+		return newCTRHMACCipher(key)
+	case AlgorithmSIV:
+		return newSIVCipher(key)
+	case AlgorithmGCMSIV:
+		return newGCMSIVCipher(key)
+	default:
+		return nil, newError("NewCipher", ErrUnsupportedAlgorithm)
+	}
+}
+
+// copyKey checks the key size and copies the key
+//
+// Parameters:
+//
+//   - key: The key
+//   - sizes: The valid key sizes
+//
+// Returns:
+//
+//   - A copy of the key
+//   - ErrInvalidKeySize if the key size is not valid
+func copyKey(key []byte, sizes ...int) ([]byte, error) {
+	for _, size := range sizes {
+		if len(key) == size {
+			return append([]byte(nil), key...), nil
+		}
+	}
+	return nil, newError("NewCipher", ErrInvalidKeySize)
+}
+
+// newGCMCipher creates the gocrypto.Cipher of the GCM block cipher mode
+//
+// Parameters:
+//
+//   - key: The key (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - The cipher
+//   - An error if the key is invalid
+func newGCMCipher(key []byte) (gocrypto.Cipher, error) {
+	encryptor, err := NewGCMEncryptor(key)
+	if err != nil {
+		return nil, err
+	}
+	return &gcmCipher{encryptor: encryptor}, nil
+}
+
+// Encrypt encrypts the plain text
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - additionalData: The additional authenticated data (may be nil)
+//
+// Returns:
+//
+//   - The nonce followed by the cipher text
+//   - An error if any occurred during the encryption process
+func (g *gcmCipher) Encrypt(plainText, additionalData []byte) ([]byte, error) {
+	return g.encryptor.EncryptBytes(nil, plainText, additionalData)
+}
+
+// Decrypt decrypts a cipher text produced by Encrypt
+//
+// Parameters:
+//
+//   - cipherText: The nonce followed by the cipher text
+//   - additionalData: The additional authenticated data used for encryption (may be nil)
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - An error if any occurred during the decryption process
+func (g *gcmCipher) Decrypt(cipherText, additionalData []byte) ([]byte, error) {
+	return g.encryptor.DecryptBytes(nil, cipherText, additionalData)
+}
+
+// Overhead returns the size in bytes of the nonce and the tag
+//
+// Returns:
+//
+//   - The overhead
+func (g *gcmCipher) Overhead() int {
+	return g.encryptor.gcm.NonceSize() + g.encryptor.gcm.Overhead()
+}
+
+// Algorithm returns the name of the cipher
+//
+// Returns:
+//
+//   - The name of the cipher
+func (g *gcmCipher) Algorithm() string {
+	return CipherNameGCM
+}
+
+// newCTRCipher creates the gocrypto.Cipher of the unauthenticated CTR block cipher mode
+//
+// Parameters:
+//
+//   - key: The key (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - The cipher
+//   - An error if the key is invalid
+func newCTRCipher(key []byte) (gocrypto.Cipher, error) {
+	copiedKey, err := copyKey(key, 16, 24, 32)
+	if err != nil {
+		return nil, err
+	}
+	return &ctrCipher{key: copiedKey}, nil
+}
+
+// Encrypt encrypts the plain text
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - additionalData: Must be empty, since the mode is not authenticated
+//
+// Returns:
+//
+//   - The IV followed by the cipher text
+//   - An error if any occurred during the encryption process
+func (c *ctrCipher) Encrypt(plainText, additionalData []byte) ([]byte, error) {
+	if len(additionalData) > 0 {
+		return nil, newError("ctrCipher.Encrypt", ErrAADNotSupported)
+	}
+	return EncryptCTRBytes(nil, plainText, c.key)
+}
+
+// Decrypt decrypts a cipher text produced by Encrypt
+//
+// Parameters:
+//
+//   - cipherText: The IV followed by the cipher text
+//   - additionalData: Must be empty, since the mode is not authenticated
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - An error if any occurred during the decryption process
+func (c *ctrCipher) Decrypt(cipherText, additionalData []byte) ([]byte, error) {
+	if len(additionalData) > 0 {
+		return nil, newError("ctrCipher.Decrypt", ErrAADNotSupported)
+	}
+	return DecryptCTRBytes(nil, cipherText, c.key)
+}
+
+// Overhead returns the size in bytes of the IV
+//
+// Returns:
+//
+//   - The overhead
+func (c *ctrCipher) Overhead() int {
+	return ctrIVSize
+}
+
+// Algorithm returns the name of the cipher
+//
+// Returns:
+//
+//   - The name of the cipher
+func (c *ctrCipher) Algorithm() string {
+	return CipherNameCTRLegacy
+}
+
+// newCTRHMACCipher creates the gocrypto.Cipher of the authenticated CTR block cipher mode
+//
+// Parameters:
+//
+//   - key: The key (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - The cipher
+//   - An error if the key is invalid
+func newCTRHMACCipher(key []byte) (gocrypto.Cipher, error) {
+	copiedKey, err := copyKey(key, 16, 24, 32)
+	if err != nil {
+		return nil, err
+	}
+	return &ctrHMACCipher{key: copiedKey}, nil
+}
+
+// Encrypt encrypts the plain text
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - additionalData: The additional authenticated data (may be nil)
+//
+// Returns:
+//
+//   - The IV followed by the cipher text and the tag
+//   - An error if any occurred during the encryption process
+func (c *ctrHMACCipher) Encrypt(plainText, additionalData []byte) (
+	[]byte,
+	error,
+) {
+	return encryptCTRHMAC(plainText, c.key, additionalData)
+}
+
+// Decrypt decrypts a cipher text produced by Encrypt
+//
+// Parameters:
+//
+//   - cipherText: The IV followed by the cipher text and the tag
+//   - additionalData: The additional authenticated data used for encryption (may be nil)
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - An error if any occurred during the decryption process
+func (c *ctrHMACCipher) Decrypt(cipherText, additionalData []byte) (
+	[]byte,
+	error,
+) {
+	return decryptCTRHMAC(cipherText, c.key, additionalData)
+}
+
+// Overhead returns the size in bytes of the IV and the tag
+//
+// Returns:
+//
+//   - The overhead
+func (c *ctrHMACCipher) Overhead() int {
+	return ctrIVSize + CTRHMACTagSize
+}
+
+// Algorithm returns the name of the cipher
+//
+// Returns:
+//
+//   - The name of the cipher
+func (c *ctrHMACCipher) Algorithm() string {
+	return CipherNameCTRHMAC
+}
+
+// newSIVCipher creates the gocrypto.Cipher of AES-SIV
+//
+// Parameters:
+//
+//   - key: The key (must be 32, 48 or 64 bytes long)
+//
+// Returns:
+//
+//   - The cipher
+//   - An error if the key is invalid
+func newSIVCipher(key []byte) (gocrypto.Cipher, error) {
+	copiedKey, err := copyKey(key, 32, 48, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &sivCipher{key: copiedKey}, nil
+}
+
+// sivAdditionalData returns the additional data components for AES-SIV
+//
+// Parameters:
+//
+//   - additionalData: The additional authenticated data
+//
+// Returns:
+//
+//   - The additional data as a single component, or no components if it is empty
+func sivAdditionalData(additionalData []byte) [][]byte {
+	if len(additionalData) == 0 {
+		return nil
+	}
+	return [][]byte{additionalData}
+}
+
+// Encrypt deterministically encrypts the plain text
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - additionalData: The additional authenticated data (may be nil)
+//
+// Returns:
+//
+//   - The synthetic IV followed by the cipher text
+//   - An error if any occurred during the encryption process
+func (s *sivCipher) Encrypt(plainText, additionalData []byte) ([]byte, error) {
+	return sealSIV(plainText, s.key, sivAdditionalData(additionalData))
+}
+
+// Decrypt decrypts a cipher text produced by Encrypt
+//
+// Parameters:
+//
+//   - cipherText: The synthetic IV followed by the cipher text
+//   - additionalData: The additional authenticated data used for encryption (may be nil)
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - An error if any occurred during the decryption process
+func (s *sivCipher) Decrypt(cipherText, additionalData []byte) ([]byte, error) {
+	return openSIV(cipherText, s.key, sivAdditionalData(additionalData))
+}
+
+// Overhead returns the size in bytes of the synthetic IV
+//
+// Returns:
+//
+//   - The overhead
+func (s *sivCipher) Overhead() int {
+	return SIVSize
+}
+
+// Algorithm returns the name of the cipher
+//
+// Returns:
+//
+//   - The name of the cipher
+func (s *sivCipher) Algorithm() string {
+	return CipherNameSIV
+}
+
+// newGCMSIVCipher creates the gocrypto.Cipher of AES-GCM-SIV
+//
+// Parameters:
+//
+//   - key: The key (must be 16 or 32 bytes long)
+//
+// Returns:
+//
+//   - The cipher
+//   - An error if the key is invalid
+func newGCMSIVCipher(key []byte) (gocrypto.Cipher, error) {
+	copiedKey, err := copyKey(key, 16, 32)
+	if err != nil {
+		return nil, err
+	}
+	return &gcmSIVCipher{key: copiedKey}, nil
+}
+
+// Encrypt encrypts the plain text with a random nonce
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - additionalData: The additional authenticated data (may be nil)
+//
+// Returns:
+//
+//   - The nonce followed by the cipher text and the tag
+//   - An error if any occurred during the encryption process
+func (g *gcmSIVCipher) Encrypt(plainText, additionalData []byte) (
+	[]byte,
+	error,
+) {
+	return sealGCMSIV(plainText, g.key, additionalData)
+}
+
+// Decrypt decrypts a cipher text produced by Encrypt
+//
+// Parameters:
+//
+//   - cipherText: The nonce followed by the cipher text and the tag
+//   - additionalData: The additional authenticated data used for encryption (may be nil)
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - An error if any occurred during the decryption process
+func (g *gcmSIVCipher) Decrypt(cipherText, additionalData []byte) (
+	[]byte,
+	error,
+) {
+	return openGCMSIV(cipherText, g.key, additionalData)
+}
+
+// Overhead returns the size in bytes of the nonce and the tag
+//
+// Returns:
+//
+//   - The overhead
+func (g *gcmSIVCipher) Overhead() int {
+	return GCMSIVNonceSize + GCMSIVTagSize
+}
+
+// Algorithm returns the name of the cipher
+//
+// Returns:
+//
+//   - The name of the cipher
+func (g *gcmSIVCipher) Algorithm() string {
+	return CipherNameGCMSIV
+}
+
+// NewKeyRingCipher creates a gocrypto.Cipher backed by the key ring. Encrypt produces binary envelopes with the primary
+// key, and Decrypt accepts envelopes of any key of the ring that is not retired
+//
+// Parameters:
+//
+//   - keyRing: The key ring
+//
+// Returns:
+//
+//   - The cipher
+//   - An error if the key ring is nil
+func NewKeyRingCipher(keyRing *KeyRing) (gocrypto.Cipher, error) {
+	if keyRing == nil {
+		return nil, newError("NewKeyRingCipher", ErrNilKeyRing)
+	}
+	return &keyRingCipher{keyRing: keyRing}, nil
+}
+
+// Encrypt encrypts the plain text with the primary key of the key ring
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - additionalData: The additional authenticated data (may be nil)
+//
+// Returns:
+//
+//   - The binary form of the envelope
+//   - An error if any occurred during the encryption process
+func (k *keyRingCipher) Encrypt(plainText, additionalData []byte) (
+	[]byte,
+	error,
+) {
+	return k.keyRing.EncryptBytes(plainText, additionalData)
+}
+
+// Decrypt decrypts a binary envelope with the key referenced by its key ID
+//
+// Parameters:
+//
+//   - cipherText: The binary form of the envelope
+//   - additionalData: The additional authenticated data used for encryption (may be nil)
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - An error if any occurred during the decryption process
+func (k *keyRingCipher) Decrypt(cipherText, additionalData []byte) (
+	[]byte,
+	error,
+) {
+	plainText, _, err := k.keyRing.DecryptBytes(cipherText, additionalData)
+	return plainText, err
+}
+
+// Overhead returns the size in bytes of the envelope header, the nonce and the tag of values encrypted with the
+// current primary key
+//
+// Returns:
+//
+//   - The overhead
+func (k *keyRingCipher) Overhead() int {
+	primaryID, _ := k.keyRing.PrimaryKeyID()
+	nonceSize, _ := k.keyRing.algorithm.nonceSize()
+	tagSize, _ := k.keyRing.algorithm.tagSize()
+	return len(EnvelopeMagic) + 4 + len(primaryID) + nonceSize + tagSize
+}
+
+// Algorithm returns the name of the cipher
+//
+// Returns:
+//
+//   - The name of the cipher
+func (k *keyRingCipher) Algorithm() string {
+	return CipherNameKeyRing
+}
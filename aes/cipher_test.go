@@ -0,0 +1,91 @@
+package aes
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+func TestRegisteredCiphers(t *testing.T) {
+	tests := []struct {
+		name    string
+		keySize int
+	}{
+		{name: CipherNameGCM, keySize: 32},
+		{name: CipherNameCTRHMAC, keySize: 32},
+		{name: CipherNameSIV, keySize: 64},
+		{name: CipherNameGCMSIV, keySize: 32},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				cipher, err := gocrypto.NewCipher(test.name, randomBytes(t, test.keySize))
+				if err != nil {
+					t.Fatalf("gocrypto.NewCipher: %v", err)
+				}
+				if cipher.Algorithm() != test.name {
+					t.Fatalf("Cipher.Algorithm = %q, want %q", cipher.Algorithm(), test.name)
+				}
+
+				cipherText, err := cipher.Encrypt([]byte("data"), []byte("context"))
+				if err != nil {
+					t.Fatalf("Cipher.Encrypt: %v", err)
+				}
+				if len(cipherText) != len("data")+cipher.Overhead() {
+					t.Fatalf("Cipher.Encrypt returned %d bytes, want %d", len(cipherText), len("data")+cipher.Overhead())
+				}
+				plainText, err := cipher.Decrypt(cipherText, []byte("context"))
+				if err != nil {
+					t.Fatalf("Cipher.Decrypt: %v", err)
+				}
+				if !bytes.Equal(plainText, []byte("data")) {
+					t.Fatalf("Cipher.Decrypt = %q, want %q", plainText, "data")
+				}
+				if _, err = cipher.Decrypt(cipherText, []byte("other")); !errors.Is(
+					err,
+					ErrAuthenticationFailed,
+				) {
+					t.Fatalf("Cipher.Decrypt with the wrong context: got %v, want %v", err, ErrAuthenticationFailed)
+				}
+			},
+		)
+	}
+}
+
+func TestUnauthenticatedCTRCipher(t *testing.T) {
+	key := randomBytes(t, 32)
+
+	// The unauthenticated mode is never registered under a name that hides its missing authentication
+	if slices.Contains(gocrypto.Ciphers(), "aes-ctr") {
+		t.Fatalf("gocrypto.Ciphers contains %q", "aes-ctr")
+	}
+	if _, err := NewCipher(AlgorithmCTR, key); !errors.Is(
+		err,
+		ErrUnauthenticatedAlgorithm,
+	) {
+		t.Fatalf("NewCipher with AES-CTR: got %v, want %v", err, ErrUnauthenticatedAlgorithm)
+	}
+
+	// Legacy values remain readable through the explicitly named cipher
+	legacyText, err := EncryptCTRBytes(nil, []byte("legacy"), key)
+	if err != nil {
+		t.Fatalf("EncryptCTRBytes: %v", err)
+	}
+	cipher, err := gocrypto.NewCipher(CipherNameCTRLegacy, key)
+	if err != nil {
+		t.Fatalf("gocrypto.NewCipher: %v", err)
+	}
+	if cipher.Algorithm() != CipherNameCTRLegacy {
+		t.Fatalf("Cipher.Algorithm = %q, want %q", cipher.Algorithm(), CipherNameCTRLegacy)
+	}
+	plainText, err := cipher.Decrypt(legacyText, nil)
+	if err != nil {
+		t.Fatalf("Cipher.Decrypt: %v", err)
+	}
+	if !bytes.Equal(plainText, []byte("legacy")) {
+		t.Fatalf("Cipher.Decrypt = %q, want %q", plainText, "legacy")
+	}
+}
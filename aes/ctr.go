@@ -6,28 +6,37 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"io"
+	"slices"
 )
 
-// EncryptCTR encrypts a string using the AES algorithm with the CTR block cipher mode
+// ctrIVSize is the size in bytes of the IV used with the CTR block cipher mode
+const ctrIVSize = aes.BlockSize
+
+// EncryptCTRBytes encrypts a byte slice with a random IV using the AES algorithm with the CTR block cipher mode,
+// appending the IV followed by the cipher text to dst
 //
 // Parameters:
 //
-// - plainText: The plain text to encrypt
-// - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//   - dst: The destination buffer, which is reused if it has enough capacity (may be nil)
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
 //
 // Returns:
 //
-// - A pointer to the encrypted string in hexadecimal format
-// - An error if any occurred during the encryption process
-func EncryptCTR(plainText, key []byte) (*string, error) {
+//   - The IV followed by the cipher text, appended to dst
+//   - An error if any occurred during the encryption process
+func EncryptCTRBytes(dst, plainText, key []byte) ([]byte, error) {
 	// Create a new AES cipher block with the generated key
-	block, err := aes.NewCipher(key)
+	block, err := newBlock("EncryptCTRBytes", key)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create a new IV for the CTR block cipher
-	iv := make([]byte, aes.BlockSize)
+	// Create a new IV for the CTR block cipher at the end of the destination buffer
+	offset := len(dst)
+	dst = slices.Grow(dst, aes.BlockSize+len(plainText))
+	dst = dst[:offset+aes.BlockSize+len(plainText)]
+	iv := dst[offset : offset+aes.BlockSize]
 	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
 		return nil, err
 	}
@@ -35,12 +44,117 @@ func EncryptCTR(plainText, key []byte) (*string, error) {
 	// Create a new CTR block cipher with the AES cipher block
 	ctr := cipher.NewCTR(block, iv)
 
+	// Encrypt the plain text using the CTR block cipher, after the prepended IV
+	ctr.XORKeyStream(dst[offset+aes.BlockSize:], plainText)
+
+	return dst, nil
+}
+
+// DecryptCTRBytes decrypts a byte slice produced by EncryptCTRBytes, appending the plain text to dst
+//
+// Parameters:
+//
+//   - dst: The destination buffer, which is reused if it has enough capacity (may be nil)
+//   - cipherText: The IV followed by the cipher text
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - The decrypted plain text, appended to dst
+//   - An error if any occurred during the decryption process
+func DecryptCTRBytes(dst, cipherText, key []byte) ([]byte, error) {
+	// Create a new AES cipher block with the generated key
+	block, err := newBlock("DecryptCTRBytes", key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract the IV from the cipher text
+	if len(cipherText) < aes.BlockSize {
+		return nil, newError("DecryptCTRBytes", ErrCiphertextTooShort)
+	}
+	iv, cipherText := cipherText[:aes.BlockSize], cipherText[aes.BlockSize:]
+
+	// Create a new CTR block cipher with the AES cipher block
+	ctr := cipher.NewCTR(block, iv)
+
+	// Decrypt the encrypted text using the CTR block cipher
+	offset := len(dst)
+	dst = slices.Grow(dst, len(cipherText))
+	dst = dst[:offset+len(cipherText)]
+	ctr.XORKeyStream(dst[offset:], cipherText)
+
+	return dst, nil
+}
+
+// EncryptCTRWithEncoding encrypts a byte slice using the AES algorithm with the CTR block cipher mode and encodes the
+// IV followed by the cipher text with the given encoding
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//   - encoding: The encoding of the output
+//
+// Returns:
+//
+//   - The encoded cipher text
+//   - An error if any occurred during the encryption process
+func EncryptCTRWithEncoding(plainText, key []byte, encoding Encoding) (
+	[]byte,
+	error,
+) {
 	// Encrypt the plain text using the CTR block cipher
-	cipherText := make([]byte, len(plainText))
-	ctr.XORKeyStream(cipherText, plainText)
+	cipherText, err := EncryptCTRBytes(nil, plainText, key)
+	if err != nil {
+		return nil, err
+	}
+	return encoding.Encode(cipherText)
+}
 
-	// Prepend the IV to the cipher text
-	cipherText = append(iv, cipherText...)
+// DecryptCTRWithEncoding decodes a cipher text produced by EncryptCTRWithEncoding and decrypts it
+//
+// Parameters:
+//
+//   - encryptedText: The encoded cipher text
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//   - encoding: The encoding of the input
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - An error if any occurred during the decoding or decryption process
+func DecryptCTRWithEncoding(encryptedText, key []byte, encoding Encoding) (
+	[]byte,
+	error,
+) {
+	// Decode the encrypted text
+	cipherText, err := encoding.Decode(encryptedText)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt the encrypted text using the CTR block cipher
+	return DecryptCTRBytes(nil, cipherText, key)
+}
+
+// EncryptCTR encrypts a string using the AES algorithm with the CTR block cipher mode
+//
+// Parameters:
+//
+// - plainText: The plain text to encrypt
+// - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+// - A pointer to the encrypted string in hexadecimal format
+// - An error if any occurred during the encryption process
+func EncryptCTR(plainText, key []byte) (*string, error) {
+	// Encrypt the plain text using the CTR block cipher
+	cipherText, err := EncryptCTRBytes(nil, plainText, key)
+	if err != nil {
+		return nil, err
+	}
 
 	// Return the encrypted cipher text as a hexadecimal string
 	enc := hex.EncodeToString(cipherText)
@@ -62,31 +176,21 @@ func EncryptCTR(plainText, key []byte) (*string, error) {
 func DecryptCTR(encryptedText *string, key []byte) (*string, error) {
 	// Check if the encrypted text is nil
 	if encryptedText == nil {
-		return nil, ErrNilEncryptedText
+		return nil, newError("DecryptCTR", ErrNilEncryptedText)
 	}
 
-	// Create a new AES cipher block with the generated key
-	block, err := aes.NewCipher(key)
+	// Decode the encrypted text from a hexadecimal string
+	cipherText, err := decodeHex("DecryptCTR", *encryptedText)
 	if err != nil {
 		return nil, err
 	}
 
-	// Decode the encrypted text from a hexadecimal string
-	cipherText, err := hex.DecodeString(*encryptedText)
+	// Decrypt the encrypted text using the CTR block cipher
+	plainText, err := DecryptCTRBytes(nil, cipherText, key)
 	if err != nil {
 		return nil, err
 	}
 
-	// Extract the IV from the cipher text
-	iv, cipherText := cipherText[:aes.BlockSize], cipherText[aes.BlockSize:]
-
-	// Create a new CTR block cipher with the AES cipher block
-	ctr := cipher.NewCTR(block, iv)
-
-	// Decrypt the encrypted text using the CTR block cipher
-	plainText := make([]byte, len(cipherText))
-	ctr.XORKeyStream(plainText, cipherText)
-
 	// Return the decrypted plain text
 	dec := string(plainText)
 
@@ -0,0 +1,284 @@
+package aes
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	// CTRHMACTagSize is the size in bytes of the HMAC-SHA256 tag appended by the authenticated CTR mode
+	CTRHMACTagSize = sha256.Size
+
+	// CTRHMACPrefix is the prefix of the strings produced by EncryptCTRHMAC. It contains characters outside the
+	// hexadecimal alphabet, so it can never be confused with the legacy format produced by EncryptCTR
+	CTRHMACPrefix = "ctrhmac1:"
+
+	// ctrHMACMACKeySize is the size in bytes of the derived MAC key
+	ctrHMACMACKeySize = 32
+)
+
+var (
+	// ctrHMACEncryptionKeyInfo is the HKDF info used to derive the encryption key of the authenticated CTR mode
+	ctrHMACEncryptionKeyInfo = "go-crypto/aes/ctr-hmac/encryption"
+
+	// ctrHMACMACKeyInfo is the HKDF info used to derive the MAC key of the authenticated CTR mode
+	ctrHMACMACKeyInfo = "go-crypto/aes/ctr-hmac/mac"
+)
+
+// deriveCTRHMACKeys derives separate encryption and MAC keys from the input key using HKDF-SHA256
+//
+// Parameters:
+//
+//   - key: The input key (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - The encryption key, with the same length as the input key
+//   - The MAC key
+//   - An error if the key size is invalid
+func deriveCTRHMACKeys(key []byte) ([]byte, []byte, error) {
+	// Check the key size
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, nil, newError("deriveCTRHMACKeys", ErrInvalidKeySize)
+	}
+
+	// Derive the encryption key
+	encryptionKey, err := hkdf.Key(
+		sha256.New,
+		key,
+		nil,
+		ctrHMACEncryptionKeyInfo,
+		len(key),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Derive the MAC key
+	macKey, err := hkdf.Key(
+		sha256.New,
+		key,
+		nil,
+		ctrHMACMACKeyInfo,
+		ctrHMACMACKeySize,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encryptionKey, macKey, nil
+}
+
+// computeCTRHMACTag computes the HMAC-SHA256 tag over the additional data and the IV followed by the cipher text
+//
+// Parameters:
+//
+//   - macKey: The MAC key
+//   - cipherText: The IV followed by the cipher text
+//   - additionalData: The additional authenticated data
+//
+// Returns:
+//
+//   - The tag
+func computeCTRHMACTag(macKey, cipherText, additionalData []byte) []byte {
+	// Prefix the additional data with its length, so it cannot be shifted into the cipher text
+	length := make([]byte, 8)
+	binary.BigEndian.PutUint64(length, uint64(len(additionalData)))
+
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(length)
+	mac.Write(additionalData)
+	mac.Write(cipherText)
+	return mac.Sum(nil)
+}
+
+// encryptCTRHMAC encrypts the plain text using the AES algorithm with the CTR block cipher mode and appends an
+// HMAC-SHA256 tag
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key from which the encryption and MAC keys are derived (must be 16, 24 or 32 bytes long)
+//   - additionalData: The additional authenticated data
+//
+// Returns:
+//
+//   - The IV followed by the cipher text and the tag
+//   - An error if any occurred during the encryption process
+func encryptCTRHMAC(plainText, key, additionalData []byte) ([]byte, error) {
+	// Derive the encryption and MAC keys
+	encryptionKey, macKey, err := deriveCTRHMACKeys(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt the plain text using the CTR block cipher
+	cipherText, err := EncryptCTRBytes(nil, plainText, encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Append the tag
+	return append(
+		cipherText,
+		computeCTRHMACTag(macKey, cipherText, additionalData)...,
+	), nil
+}
+
+// decryptCTRHMAC verifies the tag of a cipher text produced by encryptCTRHMAC in constant time and decrypts it
+//
+// Parameters:
+//
+//   - cipherText: The IV followed by the cipher text and the tag
+//   - key: The key from which the encryption and MAC keys are derived (must be 16, 24 or 32 bytes long)
+//   - additionalData: The additional authenticated data used for encryption
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - An error if any occurred during the decryption process
+func decryptCTRHMAC(cipherText, key, additionalData []byte) ([]byte, error) {
+	// Derive the encryption and MAC keys
+	encryptionKey, macKey, err := deriveCTRHMACKeys(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the tag from the cipher text
+	if len(cipherText) < ctrIVSize+CTRHMACTagSize {
+		return nil, newError("DecryptCTRHMAC", ErrCiphertextTooShort)
+	}
+	tagOffset := len(cipherText) - CTRHMACTagSize
+	cipherText, tag := cipherText[:tagOffset], cipherText[tagOffset:]
+
+	// Verify the tag before decrypting
+	if !hmac.Equal(
+		tag,
+		computeCTRHMACTag(macKey, cipherText, additionalData),
+	) {
+		return nil, newError("DecryptCTRHMAC", ErrAuthenticationFailed)
+	}
+
+	// Decrypt the cipher text using the CTR block cipher
+	return DecryptCTRBytes(nil, cipherText, encryptionKey)
+}
+
+// EncryptCTRHMAC encrypts a string using the AES algorithm with the CTR block cipher mode, authenticated with an
+// HMAC-SHA256 tag over the IV and the cipher text (encrypt-then-MAC). Separate encryption and MAC keys are derived
+// from the given key
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to CTRHMACPrefix followed by the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptCTRHMAC(plainText, key []byte) (*string, error) {
+	// Encrypt the plain text and append the tag
+	cipherText, err := encryptCTRHMAC(plainText, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the prefixed encrypted cipher text as a hexadecimal string
+	enc := CTRHMACPrefix + hex.EncodeToString(cipherText)
+
+	return &enc, nil
+}
+
+// DecryptCTRHMAC decrypts a string produced by EncryptCTRHMAC, verifying its tag in constant time before decrypting
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to CTRHMACPrefix followed by the encrypted string in hexadecimal format
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - ErrAuthenticationFailed if the encrypted text was tampered with, ErrMissingCTRHMACPrefix if it does not start with
+//     CTRHMACPrefix, or any other error that occurred during the decryption process
+func DecryptCTRHMAC(encryptedText *string, key []byte) (*string, error) {
+	// Check if the encrypted text is nil
+	if encryptedText == nil {
+		return nil, newError("DecryptCTRHMAC", ErrNilEncryptedText)
+	}
+
+	// Remove the prefix
+	encodedText, ok := strings.CutPrefix(*encryptedText, CTRHMACPrefix)
+	if !ok {
+		return nil, newError("DecryptCTRHMAC", ErrMissingCTRHMACPrefix)
+	}
+
+	// Decode the encrypted text from a hexadecimal string
+	cipherText, err := decodeHex("DecryptCTRHMAC", encodedText)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the tag and decrypt the cipher text
+	plainText, err := decryptCTRHMAC(cipherText, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the decrypted plain text
+	dec := string(plainText)
+
+	return &dec, nil
+}
+
+// DecryptCTRHMACWithLegacy decrypts a string produced by EncryptCTRHMAC and, as an explicit opt-in migration path,
+// falls back to the unauthenticated format produced by EncryptCTR when the string does not start with CTRHMACPrefix.
+// Prefixed strings never fall back, so a tampered authenticated value is always rejected.
+//
+// The fallback cannot detect tampering, so legacy values should be re-encrypted with EncryptCTRHMAC as soon as they
+// are read, and this function should be replaced by DecryptCTRHMAC once the migration is complete
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - True if the encrypted text was decrypted as the legacy unauthenticated format, false otherwise
+//   - An error if any occurred during the decryption process
+func DecryptCTRHMACWithLegacy(encryptedText *string, key []byte) (
+	*string,
+	bool,
+	error,
+) {
+	// Check if the encrypted text is nil
+	if encryptedText == nil {
+		return nil, false, newError(
+			"DecryptCTRHMACWithLegacy",
+			ErrNilEncryptedText,
+		)
+	}
+
+	// Decrypt the authenticated format, without falling back if its tag cannot be verified
+	if strings.HasPrefix(*encryptedText, CTRHMACPrefix) {
+		plainText, err := DecryptCTRHMAC(encryptedText, key)
+		if err != nil {
+			return nil, false, err
+		}
+		return plainText, false, nil
+	}
+
+	// Fall back to the legacy unauthenticated format
+	plainText, err := DecryptCTR(encryptedText, key)
+	if err != nil {
+		return nil, false, err
+	}
+	return plainText, true, nil
+}
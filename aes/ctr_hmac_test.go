@@ -0,0 +1,149 @@
+package aes
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// tamperCTRHMAC flips a bit of the given byte of a string produced by EncryptCTRHMAC, keeping its prefix
+func tamperCTRHMAC(t *testing.T, encryptedText string, index int) string {
+	t.Helper()
+
+	cipherText := mustDecodeHex(t, strings.TrimPrefix(encryptedText, CTRHMACPrefix))
+	cipherText[index] ^= 1
+	return CTRHMACPrefix + hex.EncodeToString(cipherText)
+}
+
+func TestCTRHMACRoundTrip(t *testing.T) {
+	plainText := []byte("authenticated counter mode")
+
+	for _, keySize := range []int{16, 24, 32} {
+		key := randomBytes(t, keySize)
+
+		encryptedText, err := EncryptCTRHMAC(plainText, key)
+		if err != nil {
+			t.Fatalf("EncryptCTRHMAC: %v", err)
+		}
+		if !strings.HasPrefix(*encryptedText, CTRHMACPrefix) {
+			t.Fatalf("EncryptCTRHMAC = %s, want the prefix %s", *encryptedText, CTRHMACPrefix)
+		}
+
+		decryptedText, err := DecryptCTRHMAC(encryptedText, key)
+		if err != nil {
+			t.Fatalf("DecryptCTRHMAC: %v", err)
+		}
+		if *decryptedText != string(plainText) {
+			t.Fatalf("DecryptCTRHMAC = %q, want %q", *decryptedText, plainText)
+		}
+	}
+}
+
+func TestDecryptCTRHMACRequiresPrefix(t *testing.T) {
+	key := randomBytes(t, 32)
+
+	encryptedText, err := EncryptCTRHMAC([]byte("data"), key)
+	if err != nil {
+		t.Fatalf("EncryptCTRHMAC: %v", err)
+	}
+	unprefixed := strings.TrimPrefix(*encryptedText, CTRHMACPrefix)
+
+	if _, err = DecryptCTRHMAC(&unprefixed, key); !errors.Is(
+		err,
+		ErrMissingCTRHMACPrefix,
+	) {
+		t.Fatalf("DecryptCTRHMAC without the prefix: got %v, want %v", err, ErrMissingCTRHMACPrefix)
+	}
+
+	// Legacy values never have the prefix
+	legacyText, err := EncryptCTR([]byte("data"), key)
+	if err != nil {
+		t.Fatalf("EncryptCTR: %v", err)
+	}
+	if _, err = DecryptCTRHMAC(legacyText, key); !errors.Is(
+		err,
+		ErrMissingCTRHMACPrefix,
+	) {
+		t.Fatalf("DecryptCTRHMAC of a legacy value: got %v, want %v", err, ErrMissingCTRHMACPrefix)
+	}
+}
+
+func TestDecryptCTRHMACTampered(t *testing.T) {
+	key := randomBytes(t, 32)
+
+	encryptedText, err := EncryptCTRHMAC([]byte("data"), key)
+	if err != nil {
+		t.Fatalf("EncryptCTRHMAC: %v", err)
+	}
+	cipherTextSize := len(mustDecodeHex(t, strings.TrimPrefix(*encryptedText, CTRHMACPrefix)))
+
+	// Flip a bit of the IV, the cipher text and the tag
+	for _, index := range []int{0, ctrIVSize, cipherTextSize - 1} {
+		tampered := tamperCTRHMAC(t, *encryptedText, index)
+		if _, err = DecryptCTRHMAC(&tampered, key); !errors.Is(
+			err,
+			ErrAuthenticationFailed,
+		) {
+			t.Fatalf("DecryptCTRHMAC with byte %d tampered: got %v, want %v", index, err, ErrAuthenticationFailed)
+		}
+	}
+
+	// A truncated value
+	truncated := (*encryptedText)[:len(CTRHMACPrefix)+2*(ctrIVSize+CTRHMACTagSize-1)]
+	if _, err = DecryptCTRHMAC(&truncated, key); !errors.Is(
+		err,
+		ErrCiphertextTooShort,
+	) {
+		t.Fatalf("DecryptCTRHMAC of a truncated value: got %v, want %v", err, ErrCiphertextTooShort)
+	}
+}
+
+func TestDecryptCTRHMACWithLegacy(t *testing.T) {
+	key := randomBytes(t, 32)
+	plainText := []byte("migrated value")
+
+	// Authenticated values are not reported as legacy
+	encryptedText, err := EncryptCTRHMAC(plainText, key)
+	if err != nil {
+		t.Fatalf("EncryptCTRHMAC: %v", err)
+	}
+	decryptedText, legacy, err := DecryptCTRHMACWithLegacy(encryptedText, key)
+	if err != nil || *decryptedText != string(plainText) || legacy {
+		t.Fatalf("DecryptCTRHMACWithLegacy = %v, %v, %v", decryptedText, legacy, err)
+	}
+
+	// Legacy values fall back to the unauthenticated format
+	legacyText, err := EncryptCTR(plainText, key)
+	if err != nil {
+		t.Fatalf("EncryptCTR: %v", err)
+	}
+	decryptedText, legacy, err = DecryptCTRHMACWithLegacy(legacyText, key)
+	if err != nil || *decryptedText != string(plainText) || !legacy {
+		t.Fatalf("DecryptCTRHMACWithLegacy of a legacy value = %v, %v, %v", decryptedText, legacy, err)
+	}
+}
+
+func TestDecryptCTRHMACWithLegacyNoFallback(t *testing.T) {
+	key := randomBytes(t, 32)
+
+	encryptedText, err := EncryptCTRHMAC([]byte("data"), key)
+	if err != nil {
+		t.Fatalf("EncryptCTRHMAC: %v", err)
+	}
+
+	// A prefixed value whose tag fails is rejected, although its unprefixed form is a valid legacy value
+	tampered := tamperCTRHMAC(t, *encryptedText, ctrIVSize)
+	decryptedText, legacy, err := DecryptCTRHMACWithLegacy(&tampered, key)
+	if !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("DecryptCTRHMACWithLegacy with a tampered value: got %v, want %v", err, ErrAuthenticationFailed)
+	}
+	if decryptedText != nil || legacy {
+		t.Fatalf("DecryptCTRHMACWithLegacy with a tampered value fell back to the legacy format")
+	}
+
+	unprefixed := strings.TrimPrefix(tampered, CTRHMACPrefix)
+	if _, err = DecryptCTR(&unprefixed, key); err != nil {
+		t.Fatalf("DecryptCTR of the unprefixed value: %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package aes
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// Encodings of cipher texts
+const (
+	EncodingRaw Encoding = iota + 1
+	EncodingHex
+	EncodingBase64
+	EncodingBase64URL
+)
+
+type (
+	// Encoding is the encoding of a cipher text
+	//
+	//   - EncodingRaw: the raw bytes, for compact binary storage (e.g., BYTEA columns)
+	//   - EncodingHex: hexadecimal, as produced by EncryptGCM and EncryptCTR
+	//   - EncodingBase64: standard base64 with padding
+	//   - EncodingBase64URL: URL-safe base64 without padding, for URLs and JWT claims
+	Encoding uint8
+)
+
+// String returns the name of the encoding
+//
+// Returns:
+//
+//   - The name of the encoding
+func (e Encoding) String() string {
+	switch e {
+	case EncodingRaw:
+		return "raw"
+	case EncodingHex:
+		return "hex"
+	case EncodingBase64:
+		return "base64"
+	case EncodingBase64URL:
+		return "base64url"
+	default:
+		return fmt.Sprintf("Encoding(%d)", uint8(e))
+	}
+}
+
+// Encode encodes the data with the encoding
+//
+// Parameters:
+//
+//   - data: The data to encode
+//
+// Returns:
+//
+//   - The encoded data
+//   - ErrUnsupportedEncoding if the encoding is unknown
+func (e Encoding) Encode(data []byte) ([]byte, error) {
+	switch e {
+	case EncodingRaw:
+		return data, nil
+	case EncodingHex:
+		return hex.AppendEncode(nil, data), nil
+	case EncodingBase64:
+		return base64.StdEncoding.AppendEncode(nil, data), nil
+	case EncodingBase64URL:
+		return base64.RawURLEncoding.AppendEncode(nil, data), nil
+	default:
+		return nil, newError("Encoding.Encode", ErrUnsupportedEncoding)
+	}
+}
+
+// Decode decodes the data with the encoding
+//
+// Parameters:
+//
+//   - data: The encoded data
+//
+// Returns:
+//
+//   - The decoded data
+//   - ErrInvalidEncoding if the data is not valid for the encoding, or ErrUnsupportedEncoding if the encoding is
+//     unknown
+func (e Encoding) Decode(data []byte) ([]byte, error) {
+	var decoded []byte
+	var err error
+	switch e {
+	case EncodingRaw:
+		return data, nil
+	case EncodingHex:
+		decoded, err = hex.AppendDecode(nil, data)
+	case EncodingBase64:
+		decoded, err = base64.StdEncoding.AppendDecode(nil, data)
+	case EncodingBase64URL:
+		decoded, err = base64.RawURLEncoding.AppendDecode(nil, data)
+	default:
+		return nil, newError("Encoding.Decode", ErrUnsupportedEncoding)
+	}
+	if err != nil {
+		return nil, newError("Encoding.Decode", ErrInvalidEncoding)
+	}
+	return decoded, nil
+}
+
+// EncodeToString encodes the data with the encoding and returns it as a string
+//
+// Parameters:
+//
+//   - data: The data to encode
+//
+// Returns:
+//
+//   - The encoded string
+//   - ErrUnsupportedEncoding if the encoding is unknown
+func (e Encoding) EncodeToString(data []byte) (string, error) {
+	encoded, err := e.Encode(data)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// DecodeString decodes a string with the encoding
+//
+// Parameters:
+//
+//   - data: The encoded string
+//
+// Returns:
+//
+//   - The decoded data
+//   - ErrInvalidEncoding if the string is not valid for the encoding, or ErrUnsupportedEncoding if the encoding is
+//     unknown
+func (e Encoding) DecodeString(data string) ([]byte, error) {
+	return e.Decode([]byte(data))
+}
@@ -0,0 +1,273 @@
+package aes
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"slices"
+	"sync"
+)
+
+// defaultEncryptorBufferSize is the initial capacity in bytes of the pooled buffers of an encryptor
+const defaultEncryptorBufferSize = 512
+
+type (
+	// GCMEncryptor encrypts and decrypts values using the AES algorithm with the GCM block cipher mode with a key that
+	// is prepared once, instead of creating the AES and GCM block ciphers on every call. It produces the same format
+	// as EncryptGCM and is safe for concurrent use
+	GCMEncryptor struct {
+		gcm       cipher.AEAD
+		buffers   sync.Pool
+		keyID     string
+		tracker   *UsageTracker
+		nonceMode NonceMode
+	}
+)
+
+// NewGCMEncryptor creates a new GCMEncryptor
+//
+// Parameters:
+//
+//   - key: The key to use for encryption and decryption (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the GCMEncryptor
+//   - An error if the key is invalid
+func NewGCMEncryptor(key []byte) (*GCMEncryptor, error) {
+	// Create the GCM block cipher
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GCMEncryptor{
+		gcm: gcm,
+		buffers: sync.Pool{
+			New: func() any {
+				buffer := make([]byte, 0, defaultEncryptorBufferSize)
+				return &buffer
+			},
+		},
+	}, nil
+}
+
+// NewTrackedGCMEncryptor creates a new GCMEncryptor that records every encryption in the usage tracker under the key
+// ID and refuses to encrypt once the key reaches its hard limit
+//
+// Parameters:
+//
+//   - key: The key to use for encryption and decryption (must be 16, 24 or 32 bytes long)
+//   - keyID: The ID under which the usage of the key is tracked
+//   - tracker: The usage tracker
+//   - nonceMode: The nonce mode. NonceModeCounter must only be used by a single writer with a durable usage store,
+//     since the nonces repeat if the message count of the key is lost or shared
+//
+// Returns:
+//
+//   - A pointer to the GCMEncryptor
+//   - An error if the key, the tracker or the nonce mode is invalid
+func NewTrackedGCMEncryptor(
+	key []byte,
+	keyID string,
+	tracker *UsageTracker,
+	nonceMode NonceMode,
+) (*GCMEncryptor, error) {
+	if tracker == nil {
+		return nil, newError("NewTrackedGCMEncryptor", ErrNilUsageTracker)
+	}
+	if nonceMode != NonceModeRandom && nonceMode != NonceModeCounter {
+		return nil, newError("NewTrackedGCMEncryptor", ErrInvalidNonceMode)
+	}
+
+	encryptor, err := NewGCMEncryptor(key)
+	if err != nil {
+		return nil, err
+	}
+	encryptor.keyID = keyID
+	encryptor.tracker = tracker
+	encryptor.nonceMode = nonceMode
+	return encryptor, nil
+}
+
+// getBuffer gets an empty buffer from the pool
+//
+// Returns:
+//
+//   - A pointer to the buffer
+func (g *GCMEncryptor) getBuffer() *[]byte {
+	buffer, _ := g.buffers.Get().(*[]byte)
+	*buffer = (*buffer)[:0]
+	return buffer
+}
+
+// putBuffer clears a buffer and returns it to the pool
+//
+// Parameters:
+//
+//   - buffer: A pointer to the buffer
+//   - data: The slice of the buffer that was used, which may have grown beyond its original capacity
+func (g *GCMEncryptor) putBuffer(buffer *[]byte, data []byte) {
+	clear(data)
+	*buffer = data[:0]
+	g.buffers.Put(buffer)
+}
+
+// EncryptBytes encrypts a byte slice with a random nonce, appending the nonce followed by the cipher text to dst
+//
+// Parameters:
+//
+//   - dst: The destination buffer, which is reused if it has enough capacity (may be nil)
+//   - plainText: The plain text to encrypt
+//   - additionalData: The additional authenticated data (may be nil)
+//
+// Returns:
+//
+//   - The nonce followed by the cipher text, appended to dst
+//   - An error if any occurred during the encryption process
+func (g *GCMEncryptor) EncryptBytes(dst, plainText, additionalData []byte) (
+	[]byte,
+	error,
+) {
+	// Record the encryption if the encryptor is tracked
+	var usage Usage
+	if g.tracker != nil {
+		var err error
+		if usage, err = g.tracker.Track(g.keyID, len(plainText)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create a new nonce at the end of the destination buffer
+	offset := len(dst)
+	nonceSize := g.gcm.NonceSize()
+	dst = slices.Grow(dst, nonceSize+len(plainText)+g.gcm.Overhead())
+	dst = dst[:offset+nonceSize]
+	nonce := dst[offset:]
+	if g.nonceMode == NonceModeCounter {
+		clear(nonce[:nonceSize-8])
+		binary.BigEndian.PutUint64(nonce[nonceSize-8:], usage.Messages)
+	} else if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	// Encrypt the plain text using the GCM block cipher
+	return g.gcm.Seal(dst, nonce, plainText, additionalData), nil
+}
+
+// DecryptBytes decrypts a byte slice produced by EncryptBytes, appending the plain text to dst
+//
+// Parameters:
+//
+//   - dst: The destination buffer, which is reused if it has enough capacity (may be nil)
+//   - cipherText: The nonce followed by the cipher text
+//   - additionalData: The additional authenticated data used for encryption (may be nil)
+//
+// Returns:
+//
+//   - The decrypted plain text, appended to dst
+//   - ErrAuthenticationFailed if the cipher text was tampered with or the additional data does not match, or any
+//     other error that occurred during the decryption process
+func (g *GCMEncryptor) DecryptBytes(dst, cipherText, additionalData []byte) (
+	[]byte,
+	error,
+) {
+	// Get the nonce from the cipher text
+	nonceSize := g.gcm.NonceSize()
+	if len(cipherText) < nonceSize+g.gcm.Overhead() {
+		return nil, newError("GCMEncryptor.DecryptBytes", ErrCiphertextTooShort)
+	}
+	nonce, cipherText := cipherText[:nonceSize], cipherText[nonceSize:]
+
+	// Decrypt the cipher text using the GCM block cipher
+	plainText, err := g.gcm.Open(dst, nonce, cipherText, additionalData)
+	if err != nil {
+		return nil, newError("GCMEncryptor.DecryptBytes", ErrAuthenticationFailed)
+	}
+	return plainText, nil
+}
+
+// Encrypt encrypts a string, producing the same format as EncryptGCMWithAAD
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - additionalData: The additional authenticated data (may be nil)
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func (g *GCMEncryptor) Encrypt(plainText, additionalData []byte) (
+	*string,
+	error,
+) {
+	// Encrypt the plain text into a pooled buffer
+	buffer := g.getBuffer()
+	cipherText, err := g.EncryptBytes(*buffer, plainText, additionalData)
+	if err != nil {
+		g.putBuffer(buffer, *buffer)
+		return nil, err
+	}
+
+	// Return the encrypted cipher text as a hexadecimal string
+	enc := hex.EncodeToString(cipherText)
+	g.putBuffer(buffer, cipherText)
+
+	return &enc, nil
+}
+
+// Decrypt decrypts a string produced by Encrypt or EncryptGCMWithAAD
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - additionalData: The additional authenticated data used for encryption (may be nil)
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - An error if any occurred during the decryption process
+func (g *GCMEncryptor) Decrypt(encryptedText *string, additionalData []byte) (
+	*string,
+	error,
+) {
+	// Check if the encrypted text is nil
+	if encryptedText == nil {
+		return nil, newError("GCMEncryptor.Decrypt", ErrNilEncryptedText)
+	}
+
+	// Decode the encrypted text in place in a pooled buffer
+	buffer := g.getBuffer()
+	data := append(*buffer, *encryptedText...)
+	decodedLength, err := hex.Decode(data, data)
+	if err != nil {
+		g.putBuffer(buffer, data)
+		return nil, newError("GCMEncryptor.Decrypt", ErrInvalidEncoding)
+	}
+	cipherText := data[:decodedLength]
+
+	// Decrypt the cipher text in place, after the nonce
+	nonceSize := g.gcm.NonceSize()
+	if len(cipherText) < nonceSize+g.gcm.Overhead() {
+		g.putBuffer(buffer, data)
+		return nil, newError("GCMEncryptor.Decrypt", ErrCiphertextTooShort)
+	}
+	plainText, err := g.DecryptBytes(
+		cipherText[nonceSize:nonceSize],
+		cipherText,
+		additionalData,
+	)
+	if err != nil {
+		g.putBuffer(buffer, data)
+		return nil, err
+	}
+
+	// Return the decrypted plain text
+	dec := string(plainText)
+	g.putBuffer(buffer, data)
+
+	return &dec, nil
+}
@@ -0,0 +1,82 @@
+package aes
+
+import (
+	"fmt"
+	"testing"
+)
+
+// encryptorBenchmarkSizes are the plain text sizes in bytes used by the GCMEncryptor benchmarks
+var encryptorBenchmarkSizes = []int{64, 1024, 16 * 1024}
+
+func BenchmarkGCMEncryptorEncrypt(b *testing.B) {
+	key := make([]byte, 32)
+	encryptor, err := NewGCMEncryptor(key)
+	if err != nil {
+		b.Fatalf("NewGCMEncryptor: %v", err)
+	}
+
+	for _, size := range encryptorBenchmarkSizes {
+		plainText := make([]byte, size)
+
+		b.Run(
+			fmt.Sprintf("EncryptGCM/%d", size), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(size))
+				for b.Loop() {
+					if _, err := EncryptGCM(plainText, key); err != nil {
+						b.Fatal(err)
+					}
+				}
+			},
+		)
+		b.Run(
+			fmt.Sprintf("GCMEncryptor/%d", size), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(size))
+				for b.Loop() {
+					if _, err := encryptor.Encrypt(plainText, nil); err != nil {
+						b.Fatal(err)
+					}
+				}
+			},
+		)
+	}
+}
+
+func BenchmarkGCMEncryptorDecrypt(b *testing.B) {
+	key := make([]byte, 32)
+	encryptor, err := NewGCMEncryptor(key)
+	if err != nil {
+		b.Fatalf("NewGCMEncryptor: %v", err)
+	}
+
+	for _, size := range encryptorBenchmarkSizes {
+		encryptedText, err := encryptor.Encrypt(make([]byte, size), nil)
+		if err != nil {
+			b.Fatalf("GCMEncryptor.Encrypt: %v", err)
+		}
+
+		b.Run(
+			fmt.Sprintf("DecryptGCM/%d", size), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(size))
+				for b.Loop() {
+					if _, err := DecryptGCM(encryptedText, key); err != nil {
+						b.Fatal(err)
+					}
+				}
+			},
+		)
+		b.Run(
+			fmt.Sprintf("GCMEncryptor/%d", size), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(size))
+				for b.Loop() {
+					if _, err := encryptor.Decrypt(encryptedText, nil); err != nil {
+						b.Fatal(err)
+					}
+				}
+			},
+		)
+	}
+}
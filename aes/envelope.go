@@ -0,0 +1,643 @@
+package aes
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// The envelope format is a self-describing container for encrypted values, which allows stored values to be
+// decrypted without knowing in advance which mode, key or format version produced them.
+//
+// Binary: magic (2 bytes) || version (1 byte) || algorithm (1 byte) || key ID length (1 byte) || key ID ||
+// nonce length (1 byte) || nonce || cipher text
+//
+// The text form is the hexadecimal encoding of the binary form. For authenticated algorithms, everything before the
+// nonce is bound to the cipher text as additional authenticated data, so the header cannot be modified.
+//
+// AES-CTR envelopes are not authenticated, so neither their header nor their cipher text is protected against
+// tampering. They are only produced and accepted through the explicit legacy functions EncryptLegacyCTR and
+// DecryptWithLegacyCTR, which exist to migrate stored values to an authenticated algorithm.
+
+const (
+	// EnvelopeVersion is the current version of the envelope format
+	EnvelopeVersion = 1
+
+	// MaxEnvelopeKeyIDLength is the maximum length in bytes of an envelope key ID
+	MaxEnvelopeKeyIDLength = 255
+)
+
+var (
+	// EnvelopeMagic is the prefix that identifies an envelope
+	EnvelopeMagic = [2]byte{0xae, 0x01}
+)
+
+// Algorithm identifiers, stored in the envelope header
+const (
+	AlgorithmGCM Algorithm = iota + 1
+	AlgorithmCTR
+	AlgorithmCTRHMAC
+	AlgorithmSIV
+	AlgorithmGCMSIV
+)
+
+type (
+	// Algorithm is the identifier of the algorithm used to produce an envelope
+	Algorithm uint8
+
+	// Envelope is a versioned encrypted value with its algorithm, key ID and nonce
+	Envelope struct {
+		Version    uint8
+		Algorithm  Algorithm
+		KeyID      string
+		Nonce      []byte
+		CipherText []byte
+	}
+)
+
+// String returns the name of the algorithm
+//
+// Returns:
+//
+//   - The name of the algorithm
+func (a Algorithm) String() string {
+	switch a {
+	case AlgorithmGCM:
+		return "AES-GCM"
+	case AlgorithmCTR:
+		return "AES-CTR"
+	case AlgorithmCTRHMAC:
+		return "AES-CTR-HMAC-SHA256"
+	case AlgorithmSIV:
+		return "AES-SIV"
+	case AlgorithmGCMSIV:
+		return "AES-GCM-SIV"
+	default:
+		return fmt.Sprintf("Algorithm(%d)", uint8(a))
+	}
+}
+
+// isAuthenticated checks if the algorithm authenticates the envelope
+//
+// Returns:
+//
+//   - True if the algorithm authenticates the header and the cipher text, false otherwise
+func (a Algorithm) isAuthenticated() bool {
+	return a != AlgorithmCTR
+}
+
+// nonceSize returns the size in bytes of the nonce used by the algorithm
+//
+// Returns:
+//
+//   - The nonce size
+//   - ErrUnsupportedAlgorithm if the algorithm is unknown
+func (a Algorithm) nonceSize() (int, error) {
+	switch a {
+	case AlgorithmGCM:
+		return gcmNonceSize, nil
+	case AlgorithmCTR, AlgorithmCTRHMAC:
+		return ctrIVSize, nil
+	case AlgorithmSIV:
+		return SIVSize, nil
+	case AlgorithmGCMSIV:
+		return GCMSIVNonceSize, nil
+	default:
+		return 0, newError("Algorithm.nonceSize", ErrUnsupportedAlgorithm)
+	}
+}
+
+// keySizes returns the valid sizes in bytes of the keys used by the algorithm
+//
+// Returns:
+//
+//   - The valid key sizes
+//   - ErrUnsupportedAlgorithm if the algorithm is unknown
+func (a Algorithm) keySizes() ([]int, error) {
+	switch a {
+	case AlgorithmGCM, AlgorithmCTR, AlgorithmCTRHMAC:
+		return []int{16, 24, 32}, nil
+	case AlgorithmSIV:
+		return []int{32, 48, 64}, nil
+	case AlgorithmGCMSIV:
+		return []int{16, 32}, nil
+	default:
+		return nil, newError("Algorithm.keySizes", ErrUnsupportedAlgorithm)
+	}
+}
+
+// tagSize returns the size in bytes of the authentication tag appended to the cipher text by the algorithm
+//
+// Returns:
+//
+//   - The tag size
+//   - ErrUnsupportedAlgorithm if the algorithm is unknown
+func (a Algorithm) tagSize() (int, error) {
+	switch a {
+	case AlgorithmGCM, AlgorithmGCMSIV:
+		return 16, nil
+	case AlgorithmCTR, AlgorithmSIV:
+		return 0, nil
+	case AlgorithmCTRHMAC:
+		return CTRHMACTagSize, nil
+	default:
+		return 0, newError("Algorithm.tagSize", ErrUnsupportedAlgorithm)
+	}
+}
+
+// header returns the envelope header up to the key ID, which is bound as additional authenticated data
+//
+// Returns:
+//
+//   - The envelope header
+func (e *Envelope) header() []byte {
+	// Default to the current version, so an envelope built without it can be decoded
+	version := e.Version
+	if version == 0 {
+		version = EnvelopeVersion
+	}
+
+	header := make([]byte, 0, 5+len(e.KeyID))
+	header = append(header, EnvelopeMagic[:]...)
+	header = append(header, version, byte(e.Algorithm), byte(len(e.KeyID)))
+	return append(header, e.KeyID...)
+}
+
+// additionalData returns the additional authenticated data for the envelope
+//
+// Parameters:
+//
+//   - additionalData: The caller additional authenticated data
+//
+// Returns:
+//
+//   - The envelope header followed by the caller additional authenticated data
+func (e *Envelope) additionalData(additionalData []byte) []byte {
+	return append(e.header(), additionalData...)
+}
+
+// sivAdditionalData returns the additional data components for the envelope, used by AES-SIV
+//
+// Parameters:
+//
+//   - additionalData: The caller additional authenticated data
+//
+// Returns:
+//
+//   - The envelope header and, if not empty, the caller additional authenticated data
+func (e *Envelope) sivAdditionalData(additionalData []byte) [][]byte {
+	if len(additionalData) == 0 {
+		return [][]byte{e.header()}
+	}
+	return [][]byte{e.header(), additionalData}
+}
+
+// MarshalBinary encodes the envelope into its binary form. If the version is not set, EnvelopeVersion is used
+//
+// Returns:
+//
+//   - The binary form of the envelope
+//   - An error if the key ID or the nonce are too long
+func (e *Envelope) MarshalBinary() ([]byte, error) {
+	if len(e.KeyID) > MaxEnvelopeKeyIDLength {
+		return nil, newError("Envelope.MarshalBinary", ErrEnvelopeKeyIDTooLong)
+	}
+	if len(e.Nonce) > 255 {
+		return nil, newError("Envelope.MarshalBinary", ErrInvalidEnvelope)
+	}
+
+	header := e.header()
+	data := make([]byte, 0, len(header)+1+len(e.Nonce)+len(e.CipherText))
+	data = append(data, header...)
+	data = append(data, byte(len(e.Nonce)))
+	data = append(data, e.Nonce...)
+	return append(data, e.CipherText...), nil
+}
+
+// UnmarshalBinary decodes the envelope from its binary form
+//
+// Parameters:
+//
+//   - data: The binary form of the envelope
+//
+// Returns:
+//
+//   - An error if the data is not a valid envelope
+func (e *Envelope) UnmarshalBinary(data []byte) error {
+	// Check the magic, the version and the key ID length
+	if len(data) < 5 || data[0] != EnvelopeMagic[0] || data[1] != EnvelopeMagic[1] {
+		return newError("Envelope.UnmarshalBinary", ErrInvalidEnvelope)
+	}
+	if data[2] != EnvelopeVersion {
+		return newError("Envelope.UnmarshalBinary", ErrUnsupportedEnvelopeVersion)
+	}
+	algorithm := Algorithm(data[3])
+	keyIDLength := int(data[4])
+	data = data[5:]
+
+	// Get the key ID and the nonce length
+	if len(data) < keyIDLength+1 {
+		return newError("Envelope.UnmarshalBinary", ErrInvalidEnvelope)
+	}
+	keyID := string(data[:keyIDLength])
+	nonceLength := int(data[keyIDLength])
+	data = data[keyIDLength+1:]
+
+	// Get the nonce and the cipher text
+	if len(data) < nonceLength {
+		return newError("Envelope.UnmarshalBinary", ErrInvalidEnvelope)
+	}
+
+	e.Version = EnvelopeVersion
+	e.Algorithm = algorithm
+	e.KeyID = keyID
+	e.Nonce = data[:nonceLength]
+	e.CipherText = data[nonceLength:]
+	return nil
+}
+
+// String returns the text form of the envelope
+//
+// Returns:
+//
+//   - The envelope in hexadecimal format, or an empty string if it cannot be encoded
+func (e *Envelope) String() string {
+	data, err := e.MarshalBinary()
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(data)
+}
+
+// ParseEnvelope parses an envelope from its text form
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the envelope in hexadecimal format
+//
+// Returns:
+//
+//   - A pointer to the parsed envelope
+//   - An error if the text is not a valid envelope
+func ParseEnvelope(encryptedText *string) (*Envelope, error) {
+	// Check if the encrypted text is nil
+	if encryptedText == nil {
+		return nil, newError("ParseEnvelope", ErrNilEncryptedText)
+	}
+
+	// Decode the encrypted text from a hexadecimal string
+	data, err := decodeHex("ParseEnvelope", *encryptedText)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode the envelope
+	var envelope Envelope
+	if err = envelope.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return &envelope, nil
+}
+
+// seal encrypts the plain text into the envelope, whose algorithm and key ID must already be set
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption
+//   - additionalData: The additional authenticated data
+//   - allowLegacy: Whether the unauthenticated AES-CTR algorithm is allowed
+//
+// Returns:
+//
+//   - An error if any occurred during the encryption process
+func (e *Envelope) seal(
+	plainText, key, additionalData []byte,
+	allowLegacy bool,
+) error {
+	// Check the key ID length
+	if len(e.KeyID) > MaxEnvelopeKeyIDLength {
+		return newError("Encrypt", ErrEnvelopeKeyIDTooLong)
+	}
+
+	// Check if the algorithm is authenticated
+	if !allowLegacy && !e.Algorithm.isAuthenticated() {
+		return newError("Encrypt", ErrUnauthenticatedAlgorithm)
+	}
+
+	// Get the nonce size of the algorithm
+	nonceSize, err := e.Algorithm.nonceSize()
+	if err != nil {
+		return err
+	}
+
+	// Encrypt the plain text with the algorithm
+	var cipherText []byte
+	switch e.Algorithm {
+	case AlgorithmGCM:
+		cipherText, err = EncryptGCMBytes(
+			nil,
+			plainText,
+			key,
+			e.additionalData(additionalData),
+		)
+	case AlgorithmCTR:
+		if len(additionalData) > 0 {
+			return newError("Encrypt", ErrAADNotSupported)
+		}
+		cipherText, err = EncryptCTRBytes(nil, plainText, key)
+	case AlgorithmCTRHMAC:
+		cipherText, err = encryptCTRHMAC(
+			plainText,
+			key,
+			e.additionalData(additionalData),
+		)
+	case AlgorithmSIV:
+		cipherText, err = sealSIV(
+			plainText,
+			key,
+			e.sivAdditionalData(additionalData),
+		)
+	case AlgorithmGCMSIV:
+		cipherText, err = sealGCMSIV(
+			plainText,
+			key,
+			e.additionalData(additionalData),
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Split the nonce from the cipher text
+	e.Nonce, e.CipherText = cipherText[:nonceSize], cipherText[nonceSize:]
+	return nil
+}
+
+// open decrypts the envelope
+//
+// Parameters:
+//
+//   - key: The key to use for decryption
+//   - additionalData: The additional authenticated data used for encryption
+//   - allowLegacy: Whether the unauthenticated AES-CTR algorithm is allowed
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - An error if any occurred during the decryption process
+func (e *Envelope) open(key, additionalData []byte, allowLegacy bool) (
+	[]byte,
+	error,
+) {
+	// Check if the algorithm is authenticated
+	if !allowLegacy && !e.Algorithm.isAuthenticated() {
+		return nil, newError("Decrypt", ErrUnauthenticatedAlgorithm)
+	}
+
+	// Check the nonce size of the algorithm
+	nonceSize, err := e.Algorithm.nonceSize()
+	if err != nil {
+		return nil, err
+	}
+	if len(e.Nonce) != nonceSize {
+		return nil, newError("Decrypt", ErrInvalidEnvelope)
+	}
+
+	// Rebuild the nonce followed by the cipher text
+	cipherText := make([]byte, 0, len(e.Nonce)+len(e.CipherText))
+	cipherText = append(cipherText, e.Nonce...)
+	cipherText = append(cipherText, e.CipherText...)
+
+	// Decrypt the cipher text with the algorithm
+	switch e.Algorithm {
+	case AlgorithmGCM:
+		return DecryptGCMBytes(
+			nil,
+			cipherText,
+			key,
+			e.additionalData(additionalData),
+		)
+	case AlgorithmCTR:
+		if len(additionalData) > 0 {
+			return nil, newError("Decrypt", ErrAADNotSupported)
+		}
+		return DecryptCTRBytes(nil, cipherText, key)
+	case AlgorithmCTRHMAC:
+		return decryptCTRHMAC(
+			cipherText,
+			key,
+			e.additionalData(additionalData),
+		)
+	case AlgorithmSIV:
+		return openSIV(cipherText, key, e.sivAdditionalData(additionalData))
+	case AlgorithmGCMSIV:
+		return openGCMSIV(cipherText, key, e.additionalData(additionalData))
+	default:
+		return nil, newError("Decrypt", ErrUnsupportedAlgorithm)
+	}
+}
+
+// Encrypt encrypts a string with the given algorithm and returns it as an envelope
+//
+// Parameters:
+//
+//   - algorithm: The authenticated algorithm to use for encryption
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//   - keyID: The ID of the key, stored in the envelope header (may be empty)
+//
+// Returns:
+//
+//   - A pointer to the envelope in hexadecimal format
+//   - An error if any occurred during the encryption process
+func Encrypt(
+	algorithm Algorithm,
+	plainText, key []byte,
+	keyID string,
+) (*string, error) {
+	return EncryptWithAAD(algorithm, plainText, key, keyID, nil)
+}
+
+// EncryptWithAAD encrypts a string with the given algorithm, binding it to the given additional authenticated data,
+// and returns it as an envelope
+//
+// Parameters:
+//
+//   - algorithm: The authenticated algorithm to use for encryption
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//   - keyID: The ID of the key, stored in the envelope header (may be empty)
+//   - additionalData: The additional authenticated data
+//
+// Returns:
+//
+//   - A pointer to the envelope in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptWithAAD(
+	algorithm Algorithm,
+	plainText, key []byte,
+	keyID string,
+	additionalData []byte,
+) (*string, error) {
+	return encryptEnvelope(
+		algorithm,
+		plainText,
+		key,
+		keyID,
+		additionalData,
+		false,
+	)
+}
+
+// EncryptLegacyCTR encrypts a string with the unauthenticated AES-CTR algorithm and returns it as an envelope. It only
+// exists for systems that still need to produce AES-CTR values, and should not be used for new data
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//   - keyID: The ID of the key, stored in the envelope header (may be empty)
+//
+// Returns:
+//
+//   - A pointer to the envelope in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptLegacyCTR(plainText, key []byte, keyID string) (*string, error) {
+	return encryptEnvelope(AlgorithmCTR, plainText, key, keyID, nil, true)
+}
+
+// encryptEnvelope encrypts a string with the given algorithm and returns it as an envelope
+//
+// Parameters:
+//
+//   - algorithm: The algorithm to use for encryption
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption
+//   - keyID: The ID of the key, stored in the envelope header (may be empty)
+//   - additionalData: The additional authenticated data
+//   - allowLegacy: Whether the unauthenticated AES-CTR algorithm is allowed
+//
+// Returns:
+//
+//   - A pointer to the envelope in hexadecimal format
+//   - An error if any occurred during the encryption process
+func encryptEnvelope(
+	algorithm Algorithm,
+	plainText, key []byte,
+	keyID string,
+	additionalData []byte,
+	allowLegacy bool,
+) (*string, error) {
+	// Encrypt the plain text into the envelope
+	envelope := &Envelope{
+		Version:   EnvelopeVersion,
+		Algorithm: algorithm,
+		KeyID:     keyID,
+	}
+	if err := envelope.seal(
+		plainText,
+		key,
+		additionalData,
+		allowLegacy,
+	); err != nil {
+		return nil, err
+	}
+
+	// Return the envelope in hexadecimal format
+	data, err := envelope.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	enc := hex.EncodeToString(data)
+
+	return &enc, nil
+}
+
+// Decrypt decrypts an envelope, dispatching on the algorithm stored in its header. AES-CTR envelopes are rejected with
+// ErrUnauthenticatedAlgorithm, see DecryptWithLegacyCTR
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the envelope in hexadecimal format
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - An error if any occurred during the decryption process
+func Decrypt(encryptedText *string, key []byte) (*string, error) {
+	return DecryptWithAAD(encryptedText, key, nil)
+}
+
+// DecryptWithAAD decrypts an envelope, dispatching on the algorithm stored in its header and verifying the given
+// additional authenticated data. AES-CTR envelopes are rejected with ErrUnauthenticatedAlgorithm
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the envelope in hexadecimal format
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//   - additionalData: The additional authenticated data used for encryption
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - An error if any occurred during the decryption process
+func DecryptWithAAD(encryptedText *string, key, additionalData []byte) (
+	*string,
+	error,
+) {
+	return decryptEnvelope(encryptedText, key, additionalData, false)
+}
+
+// DecryptWithLegacyCTR decrypts an envelope like Decrypt, but also accepts the unauthenticated AES-CTR envelopes. A
+// tampered AES-CTR envelope cannot be detected, so it should only be used to migrate stored values to an
+// authenticated algorithm
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the envelope in hexadecimal format
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - An error if any occurred during the decryption process
+func DecryptWithLegacyCTR(encryptedText *string, key []byte) (*string, error) {
+	return decryptEnvelope(encryptedText, key, nil, true)
+}
+
+// decryptEnvelope decrypts an envelope, dispatching on the algorithm stored in its header
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the envelope in hexadecimal format
+//   - key: The key to use for decryption
+//   - additionalData: The additional authenticated data used for encryption
+//   - allowLegacy: Whether the unauthenticated AES-CTR algorithm is allowed
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - An error if any occurred during the decryption process
+func decryptEnvelope(
+	encryptedText *string,
+	key, additionalData []byte,
+	allowLegacy bool,
+) (*string, error) {
+	// Parse the envelope
+	envelope, err := ParseEnvelope(encryptedText)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt the envelope
+	plainText, err := envelope.open(key, additionalData, allowLegacy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the decrypted plain text
+	dec := string(plainText)
+
+	return &dec, nil
+}
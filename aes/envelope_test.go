@@ -0,0 +1,218 @@
+package aes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// envelopeTestAlgorithms are the authenticated algorithms with a valid key size for each one
+var envelopeTestAlgorithms = []struct {
+	algorithm Algorithm
+	keySize   int
+}{
+	{algorithm: AlgorithmGCM, keySize: 32},
+	{algorithm: AlgorithmCTRHMAC, keySize: 32},
+	{algorithm: AlgorithmSIV, keySize: 64},
+	{algorithm: AlgorithmGCMSIV, keySize: 32},
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	plainText := []byte("envelope plain text")
+	additionalData := []byte("row 42")
+
+	for _, test := range envelopeTestAlgorithms {
+		t.Run(
+			test.algorithm.String(), func(t *testing.T) {
+				key := randomBytes(t, test.keySize)
+
+				encryptedText, err := EncryptWithAAD(
+					test.algorithm,
+					plainText,
+					key,
+					"key-1",
+					additionalData,
+				)
+				if err != nil {
+					t.Fatalf("EncryptWithAAD: %v", err)
+				}
+
+				// The envelope describes how it was produced
+				envelope, err := ParseEnvelope(encryptedText)
+				if err != nil {
+					t.Fatalf("ParseEnvelope: %v", err)
+				}
+				if envelope.Version != EnvelopeVersion || envelope.Algorithm != test.algorithm || envelope.KeyID != "key-1" {
+					t.Fatalf(
+						"ParseEnvelope = version %d, algorithm %v, key ID %q",
+						envelope.Version,
+						envelope.Algorithm,
+						envelope.KeyID,
+					)
+				}
+				if envelope.String() != *encryptedText {
+					t.Fatalf("Envelope.String = %s, want %s", envelope.String(), *encryptedText)
+				}
+
+				decryptedText, err := DecryptWithAAD(
+					encryptedText,
+					key,
+					additionalData,
+				)
+				if err != nil {
+					t.Fatalf("DecryptWithAAD: %v", err)
+				}
+				if *decryptedText != string(plainText) {
+					t.Fatalf("DecryptWithAAD = %q, want %q", *decryptedText, plainText)
+				}
+
+				// The additional data is verified
+				if _, err = DecryptWithAAD(
+					encryptedText,
+					key,
+					[]byte("row 43"),
+				); !errors.Is(err, ErrAuthenticationFailed) {
+					t.Fatalf("DecryptWithAAD with the wrong additional data: got %v, want %v", err, ErrAuthenticationFailed)
+				}
+			},
+		)
+	}
+}
+
+func TestEnvelopeTamperedHeader(t *testing.T) {
+	for _, test := range envelopeTestAlgorithms {
+		t.Run(
+			test.algorithm.String(), func(t *testing.T) {
+				key := randomBytes(t, test.keySize)
+
+				encryptedText, err := Encrypt(test.algorithm, []byte("data"), key, "key-1")
+				if err != nil {
+					t.Fatalf("Encrypt: %v", err)
+				}
+
+				// Change the key ID, which is bound to the cipher text as additional data
+				envelope, err := ParseEnvelope(encryptedText)
+				if err != nil {
+					t.Fatalf("ParseEnvelope: %v", err)
+				}
+				envelope.KeyID = "key-2"
+				tampered := envelope.String()
+
+				if _, err = Decrypt(&tampered, key); !errors.Is(
+					err,
+					ErrAuthenticationFailed,
+				) {
+					t.Fatalf("Decrypt with a tampered key ID: got %v, want %v", err, ErrAuthenticationFailed)
+				}
+			},
+		)
+	}
+}
+
+func TestEnvelopeMarshalBinary(t *testing.T) {
+	envelope := &Envelope{
+		Algorithm:  AlgorithmGCM,
+		KeyID:      "k",
+		Nonce:      []byte{1, 2},
+		CipherText: []byte{3},
+	}
+
+	// An envelope built without a version is encoded with the current version
+	data, err := envelope.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Envelope.MarshalBinary: %v", err)
+	}
+	want := mustDecodeHex(t, "ae01"+"01"+"01"+"01"+hex.EncodeToString([]byte("k"))+"02"+"0102"+"03")
+	if !bytes.Equal(data, want) {
+		t.Fatalf("Envelope.MarshalBinary = %x, want %x", data, want)
+	}
+
+	var decoded Envelope
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Envelope.UnmarshalBinary: %v", err)
+	}
+	if decoded.Version != EnvelopeVersion || decoded.Algorithm != AlgorithmGCM || decoded.KeyID != "k" ||
+		!bytes.Equal(decoded.Nonce, envelope.Nonce) || !bytes.Equal(decoded.CipherText, envelope.CipherText) {
+		t.Fatalf("Envelope.UnmarshalBinary = %+v, want %+v", decoded, envelope)
+	}
+}
+
+func TestEnvelopeUnmarshalBinaryInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{name: "empty", data: "", err: ErrInvalidEnvelope},
+		{name: "wrong magic", data: "ae02010100", err: ErrInvalidEnvelope},
+		{name: "unsupported version", data: "ae01020100", err: ErrUnsupportedEnvelopeVersion},
+		{name: "truncated key ID", data: "ae0101010561", err: ErrInvalidEnvelope},
+		{name: "truncated nonce", data: "ae010101000c0102", err: ErrInvalidEnvelope},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				var envelope Envelope
+				if err := envelope.UnmarshalBinary(
+					mustDecodeHex(t, test.data),
+				); !errors.Is(err, test.err) {
+					t.Fatalf("Envelope.UnmarshalBinary: got %v, want %v", err, test.err)
+				}
+			},
+		)
+	}
+}
+
+func TestEnvelopeLegacyCTR(t *testing.T) {
+	key := randomBytes(t, 32)
+	plainText := []byte("legacy plain text")
+
+	// CTR envelopes are refused without the legacy opt-in
+	if _, err := Encrypt(
+		AlgorithmCTR,
+		plainText,
+		key,
+		"",
+	); !errors.Is(err, ErrUnauthenticatedAlgorithm) {
+		t.Fatalf("Encrypt with AES-CTR: got %v, want %v", err, ErrUnauthenticatedAlgorithm)
+	}
+	if _, err := NewKeyRing(AlgorithmCTR); !errors.Is(
+		err,
+		ErrUnauthenticatedAlgorithm,
+	) {
+		t.Fatalf("NewKeyRing with AES-CTR: got %v, want %v", err, ErrUnauthenticatedAlgorithm)
+	}
+
+	encryptedText, err := EncryptLegacyCTR(plainText, key, "legacy")
+	if err != nil {
+		t.Fatalf("EncryptLegacyCTR: %v", err)
+	}
+	if _, err = Decrypt(encryptedText, key); !errors.Is(
+		err,
+		ErrUnauthenticatedAlgorithm,
+	) {
+		t.Fatalf("Decrypt of an AES-CTR envelope: got %v, want %v", err, ErrUnauthenticatedAlgorithm)
+	}
+
+	// The legacy functions accept them
+	decryptedText, err := DecryptWithLegacyCTR(encryptedText, key)
+	if err != nil {
+		t.Fatalf("DecryptWithLegacyCTR: %v", err)
+	}
+	if *decryptedText != string(plainText) {
+		t.Fatalf("DecryptWithLegacyCTR = %q, want %q", *decryptedText, plainText)
+	}
+
+	// The legacy functions still decrypt authenticated envelopes
+	encryptedText, err = Encrypt(AlgorithmGCM, plainText, key, "")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if decryptedText, err = DecryptWithLegacyCTR(
+		encryptedText,
+		key,
+	); err != nil || *decryptedText != string(plainText) {
+		t.Fatalf("DecryptWithLegacyCTR of an AES-GCM envelope: got %v", err)
+	}
+}
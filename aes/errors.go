@@ -1,9 +1,115 @@
 package aes
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
 	"errors"
+	"fmt"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
 )
 
+// packageName is the package name reported by the errors of the package
+const packageName = "aes"
+
 var (
-	ErrNilEncryptedText = errors.New("encrypted text is nil")
+	ErrNilEncryptedText           = errors.New("encrypted text is nil")
+	ErrInvalidStreamChunkSize     = errors.New("invalid stream chunk size")
+	ErrInvalidStreamHeader        = errors.New("invalid stream header")
+	ErrUnsupportedStreamVersion   = errors.New("unsupported stream version")
+	ErrStreamTruncated            = errors.New("stream is truncated")
+	ErrStreamAuthenticationFailed = fmt.Errorf("stream chunk %w", gocrypto.ErrAuthenticationFailed)
+	ErrStreamTooLong              = errors.New("stream exceeds the maximum number of chunks")
+	ErrStreamClosed               = errors.New("stream is closed")
+	ErrCiphertextTooShort         = gocrypto.ErrCiphertextTooShort
+	ErrAuthenticationFailed       = gocrypto.ErrAuthenticationFailed
+	ErrInvalidEnvelope            = errors.New("invalid envelope")
+	ErrUnsupportedEnvelopeVersion = errors.New("unsupported envelope version")
+	ErrEnvelopeKeyIDTooLong       = errors.New("envelope key ID is too long")
+	ErrUnsupportedAlgorithm       = errors.New("unsupported algorithm")
+	ErrEmptyKeyID                 = errors.New("key ID is empty")
+	ErrInvalidKeySize             = gocrypto.ErrInvalidKeySize
+	ErrInvalidKeyStatus           = errors.New("invalid key status")
+	ErrDuplicateKeyID             = errors.New("key ID is already in use")
+	ErrKeyNotFound                = errors.New("key not found")
+	ErrKeyNotActive               = errors.New("key is not active")
+	ErrKeyRetired                 = errors.New("key is retired")
+	ErrNoPrimaryKey               = errors.New("key ring has no primary key")
+	ErrPrimaryKeyNotActive        = errors.New("primary key must remain active")
+	ErrTooManyAdditionalData      = errors.New("too many additional data components")
+	ErrInvalidNonceSize           = errors.New("invalid nonce size")
+	ErrMessageTooLong             = errors.New("message is too long")
+	ErrInvalidKeyWrapLength       = errors.New("invalid key wrap input length")
+	ErrUnsupportedEncoding        = errors.New("unsupported encoding")
+	ErrInvalidEncoding            = gocrypto.ErrInvalidEncoding
+	ErrInvalidPasswordHeader      = errors.New("invalid password header")
+	ErrUnsupportedPasswordVersion = errors.New("unsupported password format version")
+	ErrUnsupportedKDF             = errors.New("unsupported key derivation function")
+	ErrInvalidKDFParams           = errors.New("invalid key derivation function parameters")
+	ErrKDFParamsExceedLimits      = errors.New("key derivation function parameters exceed the limits")
+	ErrNilKeyRing                 = errors.New("key ring is nil")
+	ErrNilUsageStore              = errors.New("usage store is nil")
+	ErrNilUsageTracker            = errors.New("usage tracker is nil")
+	ErrInvalidUsageLimits         = errors.New("soft usage limit is greater than the hard usage limit")
+	ErrInvalidNonceMode           = errors.New("invalid nonce mode")
+	ErrKeyUsageLimitExceeded      = errors.New("key usage limit exceeded")
+	ErrInvalidPadding             = errors.New("invalid padding")
+	ErrInvalidCiphertextLength    = errors.New("ciphertext length is not a multiple of the block size")
+	ErrInvalidLaravelPayload      = errors.New("invalid Laravel payload")
+	ErrAADNotSupported            = errors.New("algorithm does not support additional authenticated data")
+	ErrMissingCTRHMACPrefix       = errors.New("authenticated CTR prefix is missing")
+	ErrUnauthenticatedAlgorithm   = errors.New("algorithm is not authenticated and requires the legacy opt-in")
 )
+
+// newError wraps an error with the operation that failed and the package name
+//
+// Parameters:
+//
+//   - op: The name of the operation
+//   - err: The error to wrap
+//
+// Returns:
+//
+//   - The wrapped error, or nil if err is nil
+func newError(op string, err error) error {
+	return gocrypto.NewError(packageName, op, err)
+}
+
+// newBlock creates an AES cipher block, reporting an invalid key size as ErrInvalidKeySize
+//
+// Parameters:
+//
+//   - op: The name of the operation
+//   - key: The key (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - The AES cipher block
+//   - ErrInvalidKeySize if the key size is not valid
+func newBlock(op string, key []byte) (cipher.Block, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, newError(op, ErrInvalidKeySize)
+	}
+	return block, nil
+}
+
+// decodeHex decodes a hexadecimal string, reporting invalid input as ErrInvalidEncoding
+//
+// Parameters:
+//
+//   - op: The name of the operation
+//   - encodedText: The hexadecimal string
+//
+// Returns:
+//
+//   - The decoded bytes
+//   - ErrInvalidEncoding if the string is not valid hexadecimal
+func decodeHex(op string, encodedText string) ([]byte, error) {
+	data, err := hex.DecodeString(encodedText)
+	if err != nil {
+		return nil, newError(op, ErrInvalidEncoding)
+	}
+	return data, nil
+}
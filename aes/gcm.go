@@ -1,45 +1,204 @@
 package aes
 
 import (
-	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/hex"
 	"io"
+	"slices"
 )
 
-// EncryptGCM encrypts a string using the AES algorithm with the GCM block cipher mode
+// gcmNonceSize is the size in bytes of the nonce used with the GCM block cipher mode
+const gcmNonceSize = 12
+
+// newGCM creates a new GCM block cipher with the given key
 //
 // Parameters:
 //
-//   - plainText: The plain text to encrypt
-//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//   - key: The key to use (must be 16, 24 or 32 bytes long)
 //
 // Returns:
 //
-//   - A pointer to the encrypted string in hexadecimal format
-//   - An error if any occurred during the encryption process
-func EncryptGCM(plainText, key []byte) (*string, error) {
-	// Create a new AES cipher block with the generated key
-	block, err := aes.NewCipher(key)
+//   - The GCM block cipher
+//   - An error if the key is invalid
+func newGCM(key []byte) (cipher.AEAD, error) {
+	// Create a new AES cipher block with the given key
+	block, err := newBlock("newGCM", key)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create a new GCM block cipher with the AES cipher block
-	gcm, err := cipher.NewGCM(block)
+	return cipher.NewGCM(block)
+}
+
+// EncryptGCMBytes encrypts a byte slice with a random nonce using the AES algorithm with the GCM block cipher mode,
+// appending the nonce followed by the cipher text to dst
+//
+// Parameters:
+//
+//   - dst: The destination buffer, which is reused if it has enough capacity (may be nil)
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//   - additionalData: The additional authenticated data (may be nil)
+//
+// Returns:
+//
+//   - The nonce followed by the cipher text, appended to dst
+//   - An error if any occurred during the encryption process
+func EncryptGCMBytes(dst, plainText, key, additionalData []byte) (
+	[]byte,
+	error,
+) {
+	// Create a new GCM block cipher with the given key
+	gcm, err := newGCM(key)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create a new nonce for the GCM block cipher
-	nonce := make([]byte, gcm.NonceSize())
+	// Create a new nonce for the GCM block cipher at the end of the destination buffer
+	offset := len(dst)
+	nonceSize := gcm.NonceSize()
+	dst = slices.Grow(dst, nonceSize+len(plainText)+gcm.Overhead())
+	dst = dst[:offset+nonceSize]
+	nonce := dst[offset:]
 	if _, readErr := io.ReadFull(rand.Reader, nonce); readErr != nil {
 		return nil, readErr
 	}
 
 	// Encrypt the plain text using the GCM block cipher
-	cipherText := gcm.Seal(nonce, nonce, plainText, nil)
+	return gcm.Seal(dst, nonce, plainText, additionalData), nil
+}
+
+// DecryptGCMBytes decrypts a byte slice produced by EncryptGCMBytes, appending the plain text to dst
+//
+// Parameters:
+//
+//   - dst: The destination buffer, which is reused if it has enough capacity (may be nil)
+//   - cipherText: The nonce followed by the cipher text
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//   - additionalData: The additional authenticated data used for encryption (may be nil)
+//
+// Returns:
+//
+//   - The decrypted plain text, appended to dst
+//   - ErrAuthenticationFailed if the cipher text was tampered with or the additional data does not match, or any
+//     other error that occurred during the decryption process
+func DecryptGCMBytes(dst, cipherText, key, additionalData []byte) (
+	[]byte,
+	error,
+) {
+	// Create a new GCM block cipher with the given key
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the nonce size from the GCM block cipher
+	nonceSize := gcm.NonceSize()
+	if len(cipherText) < nonceSize+gcm.Overhead() {
+		return nil, newError("DecryptGCMBytes", ErrCiphertextTooShort)
+	}
+
+	// Get the nonce from the encrypted text
+	nonce, cipherText := cipherText[:nonceSize], cipherText[nonceSize:]
+
+	// Decrypt the encrypted text using the GCM block cipher
+	plainText, err := gcm.Open(dst, nonce, cipherText, additionalData)
+	if err != nil {
+		return nil, newError("DecryptGCMBytes", ErrAuthenticationFailed)
+	}
+	return plainText, nil
+}
+
+// EncryptGCMWithEncoding encrypts a byte slice using the AES algorithm with the GCM block cipher mode and encodes the
+// nonce followed by the cipher text with the given encoding
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//   - additionalData: The additional authenticated data (may be nil)
+//   - encoding: The encoding of the output
+//
+// Returns:
+//
+//   - The encoded cipher text
+//   - An error if any occurred during the encryption process
+func EncryptGCMWithEncoding(
+	plainText, key, additionalData []byte,
+	encoding Encoding,
+) ([]byte, error) {
+	// Encrypt the plain text using the GCM block cipher
+	cipherText, err := EncryptGCMBytes(nil, plainText, key, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return encoding.Encode(cipherText)
+}
+
+// DecryptGCMWithEncoding decodes a cipher text produced by EncryptGCMWithEncoding and decrypts it
+//
+// Parameters:
+//
+//   - encryptedText: The encoded cipher text
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//   - additionalData: The additional authenticated data used for encryption (may be nil)
+//   - encoding: The encoding of the input
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - An error if any occurred during the decoding or decryption process
+func DecryptGCMWithEncoding(
+	encryptedText, key, additionalData []byte,
+	encoding Encoding,
+) ([]byte, error) {
+	// Decode the encrypted text
+	cipherText, err := encoding.Decode(encryptedText)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt the encrypted text using the GCM block cipher
+	return DecryptGCMBytes(nil, cipherText, key, additionalData)
+}
+
+// EncryptGCM encrypts a string using the AES algorithm with the GCM block cipher mode
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptGCM(plainText, key []byte) (*string, error) {
+	return EncryptGCMWithAAD(plainText, key, nil)
+}
+
+// EncryptGCMWithAAD encrypts a string using the AES algorithm with the GCM block cipher mode, binding it to the given
+// additional authenticated data
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//   - additionalData: The additional authenticated data (e.g., the result of AAD.Bytes), which is not encrypted but
+//     must be provided again for decryption
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptGCMWithAAD(plainText, key, additionalData []byte) (*string, error) {
+	// Encrypt the plain text using the GCM block cipher
+	cipherText, err := EncryptGCMBytes(nil, plainText, key, additionalData)
+	if err != nil {
+		return nil, err
+	}
 
 	// Return the encrypted cipher text as a hexadecimal string
 	enc := hex.EncodeToString(cipherText)
@@ -59,37 +218,40 @@ func EncryptGCM(plainText, key []byte) (*string, error) {
 // - A pointer to the decrypted plain text string
 // - An error if any occurred during the decryption process
 func DecryptGCM(encryptedText *string, key []byte) (*string, error) {
+	return DecryptGCMWithAAD(encryptedText, key, nil)
+}
+
+// DecryptGCMWithAAD decrypts a string using the AES algorithm with the GCM block cipher mode, verifying the given
+// additional authenticated data
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//   - additionalData: The additional authenticated data used for encryption
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - ErrAuthenticationFailed if the encrypted text was tampered with or the additional data does not match, or any
+//     other error that occurred during the decryption process
+func DecryptGCMWithAAD(encryptedText *string, key, additionalData []byte) (
+	*string,
+	error,
+) {
 	// Check if the encrypted text is nil
 	if encryptedText == nil {
-		return nil, ErrNilEncryptedText
-	}
-
-	// Create a new AES cipher block with the generated key
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create a new GCM block cipher with the AES cipher block
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
+		return nil, newError("DecryptGCMWithAAD", ErrNilEncryptedText)
 	}
 
 	// Decode the encrypted text from a hexadecimal string
-	cipherText, err := hex.DecodeString(*encryptedText)
+	cipherText, err := decodeHex("DecryptGCMWithAAD", *encryptedText)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get the nonce size from the GCM block cipher
-	nonceSize := gcm.NonceSize()
-
-	// Get the nonce from the encrypted text
-	nonce, cipherText := cipherText[:nonceSize], cipherText[nonceSize:]
-
 	// Decrypt the encrypted text using the GCM block cipher
-	plainText, err := gcm.Open(nil, nonce, cipherText, nil)
+	plainText, err := DecryptGCMBytes(nil, cipherText, key, additionalData)
 	if err != nil {
 		return nil, err
 	}
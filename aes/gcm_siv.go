@@ -0,0 +1,535 @@
+package aes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+)
+
+// AES-GCM-SIV (RFC 8452) is a nonce-misuse-resistant authenticated encryption mode: repeating a nonce only reveals
+// whether the same plain text and additional data were encrypted, instead of breaking confidentiality and
+// authenticity as it does with AES-GCM. Per-nonce authentication and encryption keys are derived from the key and
+// the nonce, the tag is computed with POLYVAL and used as the initial counter of AES-CTR.
+
+const (
+	// GCMSIVNonceSize is the size in bytes of the AES-GCM-SIV nonce
+	GCMSIVNonceSize = 12
+
+	// GCMSIVTagSize is the size in bytes of the AES-GCM-SIV tag
+	GCMSIVTagSize = 16
+
+	// gcmSIVMaxLength is the maximum size in bytes of the plain text and the additional data (2^36)
+	gcmSIVMaxLength = 1 << 36
+)
+
+type (
+	// gcmSIV is a cipher.AEAD implementation of AES-GCM-SIV
+	gcmSIV struct {
+		block cipher.Block
+		key   []byte
+	}
+
+	// polyval computes POLYVAL, as defined in RFC 8452, through its relation with GHASH
+	polyval struct {
+		hHigh, hLow uint64
+		sHigh, sLow uint64
+	}
+)
+
+// ghashMul multiplies two elements of the GHASH field in constant time
+//
+// Parameters:
+//
+//   - xHigh, xLow: The first element, as big endian 64-bit halves
+//   - yHigh, yLow: The second element, as big endian 64-bit halves
+//
+// Returns:
+//
+//   - The product, as big endian 64-bit halves
+func ghashMul(xHigh, xLow, yHigh, yLow uint64) (uint64, uint64) {
+	var zHigh, zLow uint64
+	vHigh, vLow := yHigh, yLow
+	for i := 0; i < 128; i++ {
+		// Add V to Z if the current bit of X is set
+		var bit uint64
+		if i < 64 {
+			bit = (xHigh >> (63 - i)) & 1
+		} else {
+			bit = (xLow >> (127 - i)) & 1
+		}
+		mask := -bit
+		zHigh ^= vHigh & mask
+		zLow ^= vLow & mask
+
+		// Multiply V by x, reducing by the GHASH polynomial
+		vHigh, vLow = ghashMulX(vHigh, vLow)
+	}
+	return zHigh, zLow
+}
+
+// ghashMulX multiplies an element of the GHASH field by x in constant time
+//
+// Parameters:
+//
+//   - high, low: The element, as big endian 64-bit halves
+//
+// Returns:
+//
+//   - The product, as big endian 64-bit halves
+func ghashMulX(high, low uint64) (uint64, uint64) {
+	mask := -(low & 1)
+	low = low>>1 | high<<63
+	high = high>>1 ^ 0xe100000000000000&mask
+	return high, low
+}
+
+// newPolyval creates a new POLYVAL instance
+//
+// Parameters:
+//
+//   - h: The 16 bytes authentication key
+//
+// Returns:
+//
+//   - A pointer to the POLYVAL instance
+func newPolyval(h []byte) *polyval {
+	// POLYVAL(H, X) = ByteReverse(GHASH(mulX_GHASH(ByteReverse(H)), ByteReverse(X)))
+	hHigh := binary.LittleEndian.Uint64(h[8:])
+	hLow := binary.LittleEndian.Uint64(h[:8])
+	hHigh, hLow = ghashMulX(hHigh, hLow)
+	return &polyval{hHigh: hHigh, hLow: hLow}
+}
+
+// updateBlock absorbs a single 16 bytes block
+//
+// Parameters:
+//
+//   - block: The block to absorb
+func (p *polyval) updateBlock(block []byte) {
+	p.sHigh ^= binary.LittleEndian.Uint64(block[8:])
+	p.sLow ^= binary.LittleEndian.Uint64(block[:8])
+	p.sHigh, p.sLow = ghashMul(p.sHigh, p.sLow, p.hHigh, p.hLow)
+}
+
+// update absorbs the data, zero padding the last block
+//
+// Parameters:
+//
+//   - data: The data to absorb
+func (p *polyval) update(data []byte) {
+	for len(data) >= 16 {
+		p.updateBlock(data[:16])
+		data = data[16:]
+	}
+	if len(data) > 0 {
+		var padded [16]byte
+		copy(padded[:], data)
+		p.updateBlock(padded[:])
+	}
+}
+
+// sum returns the POLYVAL result
+//
+// Returns:
+//
+//   - The 16 bytes result
+func (p *polyval) sum() [16]byte {
+	var result [16]byte
+	binary.LittleEndian.PutUint64(result[:8], p.sLow)
+	binary.LittleEndian.PutUint64(result[8:], p.sHigh)
+	return result
+}
+
+// newGCMSIV creates a new AES-GCM-SIV cipher.AEAD
+//
+// Parameters:
+//
+//   - key: The key (must be 16 or 32 bytes long)
+//
+// Returns:
+//
+//   - The AES-GCM-SIV cipher.AEAD
+//   - An error if the key size is invalid
+func newGCMSIV(key []byte) (cipher.AEAD, error) {
+	// Check the key size
+	switch len(key) {
+	case 16, 32:
+	default:
+		return nil, newError("newGCMSIV", ErrInvalidKeySize)
+	}
+
+	// Create a new AES cipher block with the key generating key
+	block, err := newBlock("newGCMSIV", key)
+	if err != nil {
+		return nil, err
+	}
+	return &gcmSIV{block: block, key: key}, nil
+}
+
+// NonceSize returns the size in bytes of the nonce
+//
+// Returns:
+//
+//   - The nonce size
+func (g *gcmSIV) NonceSize() int {
+	return GCMSIVNonceSize
+}
+
+// Overhead returns the difference in bytes between the cipher text and the plain text lengths
+//
+// Returns:
+//
+//   - The overhead
+func (g *gcmSIV) Overhead() int {
+	return GCMSIVTagSize
+}
+
+// deriveKeys derives the per-nonce authentication and encryption keys
+//
+// Parameters:
+//
+//   - nonce: The nonce
+//
+// Returns:
+//
+//   - The authentication key
+//   - The cipher block of the encryption key
+func (g *gcmSIV) deriveKeys(nonce []byte) ([]byte, cipher.Block) {
+	// Each derived block contributes its first 8 bytes
+	blockCount := 4
+	if len(g.key) == 32 {
+		blockCount = 6
+	}
+	derived := make([]byte, 8*blockCount)
+	var input, output [aes.BlockSize]byte
+	copy(input[4:], nonce)
+	for i := 0; i < blockCount; i++ {
+		binary.LittleEndian.PutUint32(input[:4], uint32(i))
+		g.block.Encrypt(output[:], input[:])
+		copy(derived[8*i:], output[:8])
+	}
+
+	// The key size is valid, so the cipher creation cannot fail
+	encryptionBlock, _ := aes.NewCipher(derived[16:])
+	return derived[:16], encryptionBlock
+}
+
+// computeGCMSIVTag computes the tag of the plain text and the additional data
+//
+// Parameters:
+//
+//   - authenticationKey: The per-nonce authentication key
+//   - encryptionBlock: The cipher block of the per-nonce encryption key
+//   - nonce: The nonce
+//   - plainText: The plain text
+//   - additionalData: The additional data
+//
+// Returns:
+//
+//   - The tag
+func computeGCMSIVTag(
+	authenticationKey []byte,
+	encryptionBlock cipher.Block,
+	nonce, plainText, additionalData []byte,
+) [GCMSIVTagSize]byte {
+	// Compute POLYVAL over the additional data, the plain text and their bit lengths
+	p := newPolyval(authenticationKey)
+	p.update(additionalData)
+	p.update(plainText)
+	var lengthBlock [16]byte
+	binary.LittleEndian.PutUint64(lengthBlock[:8], uint64(len(additionalData))*8)
+	binary.LittleEndian.PutUint64(lengthBlock[8:], uint64(len(plainText))*8)
+	p.updateBlock(lengthBlock[:])
+	s := p.sum()
+
+	// XOR the nonce, clear the most significant bit and encrypt
+	subtle.XORBytes(s[:GCMSIVNonceSize], s[:GCMSIVNonceSize], nonce)
+	s[15] &= 0x7f
+	var tag [GCMSIVTagSize]byte
+	encryptionBlock.Encrypt(tag[:], s[:])
+	return tag
+}
+
+// gcmSIVCTR applies AES-CTR with a 32-bit little endian counter, starting from the tag
+//
+// Parameters:
+//
+//   - encryptionBlock: The cipher block of the per-nonce encryption key
+//   - tag: The tag
+//   - dst: The destination buffer
+//   - src: The source buffer
+func gcmSIVCTR(
+	encryptionBlock cipher.Block,
+	tag [GCMSIVTagSize]byte,
+	dst, src []byte,
+) {
+	counterBlock := tag
+	counterBlock[15] |= 0x80
+	var keyStream [aes.BlockSize]byte
+	for len(src) > 0 {
+		encryptionBlock.Encrypt(keyStream[:], counterBlock[:])
+		n := subtle.XORBytes(dst, src, keyStream[:])
+		dst, src = dst[n:], src[n:]
+
+		// Increment the counter, wrapping around
+		counter := binary.LittleEndian.Uint32(counterBlock[:4])
+		binary.LittleEndian.PutUint32(counterBlock[:4], counter+1)
+	}
+}
+
+// Seal encrypts and authenticates the plain text, appending the result to dst
+//
+// Parameters:
+//
+//   - dst: The destination buffer
+//   - nonce: The nonce (must be GCMSIVNonceSize bytes long)
+//   - plainText: The plain text
+//   - additionalData: The additional data
+//
+// Returns:
+//
+//   - The cipher text followed by the tag, appended to dst
+func (g *gcmSIV) Seal(dst, nonce, plainText, additionalData []byte) []byte {
+	if len(nonce) != GCMSIVNonceSize {
+		panic("aes: incorrect nonce length given to AES-GCM-SIV")
+	}
+	if uint64(len(plainText)) > gcmSIVMaxLength || uint64(len(additionalData)) > gcmSIVMaxLength {
+		panic("aes: message too large for AES-GCM-SIV")
+	}
+
+	// Derive the per-nonce keys and compute the tag
+	authenticationKey, encryptionBlock := g.deriveKeys(nonce)
+	tag := computeGCMSIVTag(
+		authenticationKey,
+		encryptionBlock,
+		nonce,
+		plainText,
+		additionalData,
+	)
+
+	// Encrypt the plain text and append the tag
+	offset := len(dst)
+	dst = append(dst, make([]byte, len(plainText)+GCMSIVTagSize)...)
+	gcmSIVCTR(encryptionBlock, tag, dst[offset:], plainText)
+	copy(dst[offset+len(plainText):], tag[:])
+	return dst
+}
+
+// Open decrypts and verifies the cipher text, appending the plain text to dst
+//
+// Parameters:
+//
+//   - dst: The destination buffer
+//   - nonce: The nonce (must be GCMSIVNonceSize bytes long)
+//   - cipherText: The cipher text followed by the tag
+//   - additionalData: The additional data
+//
+// Returns:
+//
+//   - The plain text, appended to dst
+//   - ErrAuthenticationFailed if the cipher text or the additional data were tampered with
+func (g *gcmSIV) Open(dst, nonce, cipherText, additionalData []byte) (
+	[]byte,
+	error,
+) {
+	if len(nonce) != GCMSIVNonceSize {
+		return nil, newError("DecryptGCMSIV", ErrInvalidNonceSize)
+	}
+	if len(cipherText) < GCMSIVTagSize {
+		return nil, newError("DecryptGCMSIV", ErrCiphertextTooShort)
+	}
+
+	// Split the tag from the cipher text
+	var tag [GCMSIVTagSize]byte
+	tagOffset := len(cipherText) - GCMSIVTagSize
+	copy(tag[:], cipherText[tagOffset:])
+	cipherText = cipherText[:tagOffset]
+
+	// Derive the per-nonce keys and decrypt the cipher text
+	authenticationKey, encryptionBlock := g.deriveKeys(nonce)
+	offset := len(dst)
+	dst = append(dst, make([]byte, len(cipherText))...)
+	plainText := dst[offset:]
+	gcmSIVCTR(encryptionBlock, tag, plainText, cipherText)
+
+	// Verify the tag
+	expected := computeGCMSIVTag(
+		authenticationKey,
+		encryptionBlock,
+		nonce,
+		plainText,
+		additionalData,
+	)
+	if subtle.ConstantTimeCompare(tag[:], expected[:]) != 1 {
+		clear(plainText)
+		return nil, newError("DecryptGCMSIV", ErrAuthenticationFailed)
+	}
+	return dst, nil
+}
+
+// sealGCMSIV encrypts the plain text with a random nonce using AES-GCM-SIV
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16 or 32 bytes long)
+//   - additionalData: The additional authenticated data
+//
+// Returns:
+//
+//   - The nonce followed by the cipher text and the tag
+//   - An error if any occurred during the encryption process
+func sealGCMSIV(plainText, key, additionalData []byte) ([]byte, error) {
+	// Create the AES-GCM-SIV cipher
+	aead, err := newGCMSIV(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the message lengths
+	if uint64(len(plainText)) > gcmSIVMaxLength || uint64(len(additionalData)) > gcmSIVMaxLength {
+		return nil, newError("EncryptGCMSIV", ErrMessageTooLong)
+	}
+
+	// Create a new nonce
+	nonce := make([]byte, GCMSIVNonceSize)
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	// Encrypt the plain text
+	return aead.Seal(nonce, nonce, plainText, additionalData), nil
+}
+
+// openGCMSIV decrypts a cipher text produced by sealGCMSIV
+//
+// Parameters:
+//
+//   - cipherText: The nonce followed by the cipher text and the tag
+//   - key: The key to use for decryption (must be 16 or 32 bytes long)
+//   - additionalData: The additional authenticated data used for encryption
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - An error if any occurred during the decryption process
+func openGCMSIV(cipherText, key, additionalData []byte) ([]byte, error) {
+	// Create the AES-GCM-SIV cipher
+	aead, err := newGCMSIV(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the nonce from the cipher text
+	if len(cipherText) < GCMSIVNonceSize+GCMSIVTagSize {
+		return nil, newError("DecryptGCMSIV", ErrCiphertextTooShort)
+	}
+	nonce, cipherText := cipherText[:GCMSIVNonceSize], cipherText[GCMSIVNonceSize:]
+
+	// Decrypt the cipher text
+	return aead.Open(nil, nonce, cipherText, additionalData)
+}
+
+// EncryptGCMSIV encrypts a string using AES-GCM-SIV (RFC 8452)
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16 or 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptGCMSIV(plainText, key []byte) (*string, error) {
+	return EncryptGCMSIVWithAAD(plainText, key, nil)
+}
+
+// EncryptGCMSIVWithAAD encrypts a string using AES-GCM-SIV (RFC 8452), binding it to the given additional
+// authenticated data
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16 or 32 bytes long)
+//   - additionalData: The additional authenticated data
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptGCMSIVWithAAD(plainText, key, additionalData []byte) (
+	*string,
+	error,
+) {
+	// Encrypt the plain text using AES-GCM-SIV
+	cipherText, err := sealGCMSIV(plainText, key, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the encrypted cipher text as a hexadecimal string
+	enc := hex.EncodeToString(cipherText)
+
+	return &enc, nil
+}
+
+// DecryptGCMSIV decrypts a string produced by EncryptGCMSIV
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - key: The key to use for decryption (must be 16 or 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - An error if any occurred during the decryption process
+func DecryptGCMSIV(encryptedText *string, key []byte) (*string, error) {
+	return DecryptGCMSIVWithAAD(encryptedText, key, nil)
+}
+
+// DecryptGCMSIVWithAAD decrypts a string produced by EncryptGCMSIVWithAAD, verifying the given additional
+// authenticated data
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - key: The key to use for decryption (must be 16 or 32 bytes long)
+//   - additionalData: The additional authenticated data used for encryption
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - ErrAuthenticationFailed if the encrypted text was tampered with or the additional data does not match, or any
+//     other error that occurred during the decryption process
+func DecryptGCMSIVWithAAD(
+	encryptedText *string,
+	key, additionalData []byte,
+) (*string, error) {
+	// Check if the encrypted text is nil
+	if encryptedText == nil {
+		return nil, newError("DecryptGCMSIVWithAAD", ErrNilEncryptedText)
+	}
+
+	// Decode the encrypted text from a hexadecimal string
+	cipherText, err := decodeHex("DecryptGCMSIVWithAAD", *encryptedText)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt the cipher text using AES-GCM-SIV
+	plainText, err := openGCMSIV(cipherText, key, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the decrypted plain text
+	dec := string(plainText)
+
+	return &dec, nil
+}
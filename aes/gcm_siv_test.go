@@ -0,0 +1,299 @@
+package aes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// gcmSIVVectors are test vectors of RFC 8452, appendix C.1 (AEAD_AES_128_GCM_SIV) and C.2 (AEAD_AES_256_GCM_SIV).
+// The result is the cipher text followed by the tag
+var gcmSIVVectors = []struct {
+	name           string
+	key            string
+	nonce          string
+	additionalData string
+	plainText      string
+	result         string
+}{
+	{
+		name:   "128-bit key, empty plain text",
+		key:    "01000000000000000000000000000000",
+		nonce:  "030000000000000000000000",
+		result: "dc20e2d83f25705bb49e439eca56de25",
+	},
+	{
+		name:      "128-bit key, 8-byte plain text",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		plainText: "0100000000000000",
+		result:    "b5d839330ac7b786578782fff6013b815b287c22493a364c",
+	},
+	{
+		name:      "128-bit key, 12-byte plain text",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		plainText: "010000000000000000000000",
+		result:    "7323ea61d05932260047d942a4978db357391a0bc4fdec8b0d106639",
+	},
+	{
+		name:      "128-bit key, 16-byte plain text",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		plainText: "01000000000000000000000000000000",
+		result:    "743f7c8077ab25f8624e2e948579cf77303aaf90f6fe21199c6068577437a0c4",
+	},
+	{
+		name:      "128-bit key, 32-byte plain text",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		plainText: "0100000000000000000000000000000002000000000000000000000000000000",
+		result:    "84e07e62ba83a6585417245d7ec413a9fe427d6315c09b57ce45f2e3936a94451a8e45dcd4578c667cd86847bf6155ff",
+	},
+	{
+		name:           "128-bit key with additional data, 8-byte plain text",
+		key:            "01000000000000000000000000000000",
+		nonce:          "030000000000000000000000",
+		additionalData: "01",
+		plainText:      "0200000000000000",
+		result:         "1e6daba35669f4273b0a1a2560969cdf790d99759abd1508",
+	},
+	{
+		name:           "128-bit key with additional data, 12-byte plain text",
+		key:            "01000000000000000000000000000000",
+		nonce:          "030000000000000000000000",
+		additionalData: "01",
+		plainText:      "020000000000000000000000",
+		result:         "296c7889fd99f41917f4462008299c5102745aaa3a0c469fad9e075a",
+	},
+	{
+		name:           "128-bit key with additional data, 16-byte plain text",
+		key:            "01000000000000000000000000000000",
+		nonce:          "030000000000000000000000",
+		additionalData: "01",
+		plainText:      "02000000000000000000000000000000",
+		result:         "e2b0c5da79a901c1745f700525cb335b8f8936ec039e4e4bb97ebd8c4457441f",
+	},
+	{
+		name:           "128-bit key with additional data, text plain text",
+		key:            "ee8e1ed9ff2540ae8f2ba9f50bc2f27c",
+		nonce:          "752abad3e0afb5f434dc4310",
+		additionalData: hex.EncodeToString([]byte("example")),
+		plainText:      hex.EncodeToString([]byte("Hello world")),
+		result:         "5d349ead175ef6b1def6fd4fbcdeb7e4793f4a1d7e4faa70100af1",
+	},
+	{
+		name:   "256-bit key, empty plain text",
+		key:    "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:  "030000000000000000000000",
+		result: "07f5f4169bbf55a8400cd47ea6fd400f",
+	},
+	{
+		name:      "256-bit key, 8-byte plain text",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		plainText: "0100000000000000",
+		result:    "c2ef328e5c71c83b843122130f7364b761e0b97427e3df28",
+	},
+	{
+		name:      "256-bit key, 12-byte plain text",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		plainText: "010000000000000000000000",
+		result:    "9aab2aeb3faa0a34aea8e2b18ca50da9ae6559e48fd10f6e5c9ca17e",
+	},
+	{
+		name:      "256-bit key, 16-byte plain text",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		plainText: "01000000000000000000000000000000",
+		result:    "85a01b63025ba19b7fd3ddfc033b3e76c9eac6fa700942702e90862383c6c366",
+	},
+	{
+		name:           "256-bit key with additional data, 8-byte plain text",
+		key:            "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:          "030000000000000000000000",
+		additionalData: "01",
+		plainText:      "0200000000000000",
+		result:         "1de22967237a813291213f267e3b452f02d01ae33e4ec854",
+	},
+	{
+		name:           "256-bit key with additional data, 12-byte plain text",
+		key:            "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:          "030000000000000000000000",
+		additionalData: "01",
+		plainText:      "020000000000000000000000",
+		result:         "163d6f9cc1b346cd453a2e4cc1a4a19ae800941ccdc57cc8413c277f",
+	},
+	{
+		name:           "256-bit key with additional data, 16-byte plain text",
+		key:            "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:          "030000000000000000000000",
+		additionalData: "01",
+		plainText:      "02000000000000000000000000000000",
+		result:         "c91545823cc24f17dbb0e9e807d5ec17b292d28ff61189e8e49f3875ef91aff7",
+	},
+}
+
+func TestGCMSIVVectors(t *testing.T) {
+	for _, vector := range gcmSIVVectors {
+		t.Run(
+			vector.name, func(t *testing.T) {
+				aead, err := newGCMSIV(mustDecodeHex(t, vector.key))
+				if err != nil {
+					t.Fatalf("newGCMSIV: %v", err)
+				}
+				nonce := mustDecodeHex(t, vector.nonce)
+				additionalData := mustDecodeHex(t, vector.additionalData)
+				plainText := mustDecodeHex(t, vector.plainText)
+				result := mustDecodeHex(t, vector.result)
+
+				got := aead.Seal(nil, nonce, plainText, additionalData)
+				if !bytes.Equal(got, result) {
+					t.Fatalf("Seal = %x, want %x", got, result)
+				}
+
+				got, err = aead.Open(nil, nonce, result, additionalData)
+				if err != nil {
+					t.Fatalf("Open: %v", err)
+				}
+				if !bytes.Equal(got, plainText) {
+					t.Fatalf("Open = %x, want %x", got, plainText)
+				}
+
+				// The exported functions decrypt the nonce followed by the result
+				encryptedText := vector.nonce + vector.result
+				decryptedText, err := DecryptGCMSIVWithAAD(
+					&encryptedText,
+					mustDecodeHex(t, vector.key),
+					additionalData,
+				)
+				if err != nil {
+					t.Fatalf("DecryptGCMSIVWithAAD: %v", err)
+				}
+				if !bytes.Equal([]byte(*decryptedText), plainText) {
+					t.Fatalf("DecryptGCMSIVWithAAD = %x, want %x", *decryptedText, plainText)
+				}
+			},
+		)
+	}
+}
+
+func TestGCMSIVTampered(t *testing.T) {
+	for _, vector := range gcmSIVVectors {
+		t.Run(
+			vector.name, func(t *testing.T) {
+				key := mustDecodeHex(t, vector.key)
+				additionalData := mustDecodeHex(t, vector.additionalData)
+				cipherText := mustDecodeHex(t, vector.nonce+vector.result)
+
+				// Flip a bit of every byte of the nonce, the cipher text and the tag
+				for i := range cipherText {
+					tampered := bytes.Clone(cipherText)
+					tampered[i] ^= 1
+					encryptedText := hex.EncodeToString(tampered)
+					if _, err := DecryptGCMSIVWithAAD(
+						&encryptedText,
+						key,
+						additionalData,
+					); !errors.Is(err, ErrAuthenticationFailed) {
+						t.Fatalf("DecryptGCMSIVWithAAD with byte %d tampered: got %v, want %v", i, err, ErrAuthenticationFailed)
+					}
+				}
+
+				// Change the additional data
+				encryptedText := vector.nonce + vector.result
+				if _, err := DecryptGCMSIVWithAAD(
+					&encryptedText,
+					key,
+					append(additionalData, 0),
+				); !errors.Is(err, ErrAuthenticationFailed) {
+					t.Fatalf("DecryptGCMSIVWithAAD with the wrong additional data: got %v, want %v", err, ErrAuthenticationFailed)
+				}
+			},
+		)
+	}
+}
+
+func TestGCMSIVRoundTrip(t *testing.T) {
+	for _, keySize := range []int{16, 32} {
+		key := randomBytes(t, keySize)
+		plainText := randomBytes(t, 100)
+
+		encryptedText, err := EncryptGCMSIVWithAAD(plainText, key, []byte("context"))
+		if err != nil {
+			t.Fatalf("EncryptGCMSIVWithAAD: %v", err)
+		}
+		decryptedText, err := DecryptGCMSIVWithAAD(encryptedText, key, []byte("context"))
+		if err != nil {
+			t.Fatalf("DecryptGCMSIVWithAAD: %v", err)
+		}
+		if !bytes.Equal([]byte(*decryptedText), plainText) {
+			t.Fatalf("DecryptGCMSIVWithAAD returned a different plain text")
+		}
+	}
+}
+
+func TestGCMSIVMalformedInput(t *testing.T) {
+	key := randomBytes(t, 32)
+
+	// Malformed input must be reported as an error, never reach the panics of Seal
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("malformed input panicked: %v", r)
+		}
+	}()
+
+	for _, keySize := range []int{0, 15, 24, 33} {
+		if _, err := EncryptGCMSIV(nil, make([]byte, keySize)); !errors.Is(
+			err,
+			ErrInvalidKeySize,
+		) {
+			t.Fatalf("EncryptGCMSIV with a %d-byte key: got %v, want %v", keySize, err, ErrInvalidKeySize)
+		}
+	}
+
+	for length := 0; length < GCMSIVNonceSize+GCMSIVTagSize; length++ {
+		encryptedText := hex.EncodeToString(make([]byte, length))
+		if _, err := DecryptGCMSIV(&encryptedText, key); !errors.Is(
+			err,
+			ErrCiphertextTooShort,
+		) {
+			t.Fatalf("DecryptGCMSIV of %d bytes: got %v, want %v", length, err, ErrCiphertextTooShort)
+		}
+	}
+	for _, encryptedText := range []string{"0", "zz", "000"} {
+		if _, err := DecryptGCMSIV(&encryptedText, key); !errors.Is(
+			err,
+			ErrInvalidEncoding,
+		) {
+			t.Fatalf("DecryptGCMSIV of %q: got %v, want %v", encryptedText, err, ErrInvalidEncoding)
+		}
+	}
+	if _, err := DecryptGCMSIV(nil, key); !errors.Is(err, ErrNilEncryptedText) {
+		t.Fatalf("DecryptGCMSIV of nil: got %v, want %v", err, ErrNilEncryptedText)
+	}
+
+	// An envelope with a nonce of the wrong size
+	envelope := &Envelope{
+		Algorithm:  AlgorithmGCMSIV,
+		Nonce:      make([]byte, GCMSIVNonceSize-1),
+		CipherText: make([]byte, GCMSIVTagSize),
+	}
+	encryptedText := envelope.String()
+	if _, err := Decrypt(&encryptedText, key); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Fatalf("Decrypt of an envelope with a short nonce: got %v, want %v", err, ErrInvalidEnvelope)
+	}
+
+	// The gocrypto.Cipher of AES-GCM-SIV
+	gcmSIVCipher, err := NewCipher(AlgorithmGCMSIV, key)
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	for _, cipherText := range [][]byte{nil, make([]byte, GCMSIVNonceSize), randomBytes(t, 40)} {
+		if _, err = gcmSIVCipher.Decrypt(cipherText, nil); err == nil {
+			t.Fatalf("Cipher.Decrypt of %d malformed bytes succeeded", len(cipherText))
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package aes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Key statuses
+const (
+	KeyStatusActive KeyStatus = iota + 1
+	KeyStatusDecryptOnly
+	KeyStatusRetired
+)
+
+type (
+	// KeyStatus is the status of a key in a KeyRing
+	//
+	//   - KeyStatusActive: the key can encrypt and decrypt, and can be the primary key
+	//   - KeyStatusDecryptOnly: the key can only decrypt existing values
+	//   - KeyStatusRetired: the key can no longer be used
+	KeyStatus uint8
+
+	// KeyRing holds multiple versioned keys with one primary key. Encryption always uses the primary key and stamps its
+	// ID into the envelope, while decryption looks up the key by the ID stored in the envelope. It is safe for
+	// concurrent use
+	KeyRing struct {
+		mutex     sync.RWMutex
+		algorithm Algorithm
+		keys      map[string]*keyRingEntry
+		primaryID string
+	}
+
+	// keyRingEntry is a key stored in a KeyRing
+	keyRingEntry struct {
+		key    []byte
+		status KeyStatus
+	}
+)
+
+// String returns the name of the key status
+//
+// Returns:
+//
+//   - The name of the key status
+func (k KeyStatus) String() string {
+	switch k {
+	case KeyStatusActive:
+		return "active"
+	case KeyStatusDecryptOnly:
+		return "decrypt-only"
+	case KeyStatusRetired:
+		return "retired"
+	default:
+		return fmt.Sprintf("KeyStatus(%d)", uint8(k))
+	}
+}
+
+// isValid checks if the key status is known
+//
+// Returns:
+//
+//   - True if the key status is known, false otherwise
+func (k KeyStatus) isValid() bool {
+	return k >= KeyStatusActive && k <= KeyStatusRetired
+}
+
+// NewKeyRing creates a new empty KeyRing
+//
+// Parameters:
+//
+//   - algorithm: The authenticated algorithm used to encrypt new values
+//
+// Returns:
+//
+//   - A pointer to the KeyRing
+//   - An error if the algorithm is not supported or is not authenticated
+func NewKeyRing(algorithm Algorithm) (*KeyRing, error) {
+	if _, err := algorithm.nonceSize(); err != nil {
+		return nil, err
+	}
+	if !algorithm.isAuthenticated() {
+		return nil, newError("NewKeyRing", ErrUnauthenticatedAlgorithm)
+	}
+
+	return &KeyRing{
+		algorithm: algorithm,
+		keys:      make(map[string]*keyRingEntry),
+	}, nil
+}
+
+// AddKey adds a key to the key ring. The first active key added becomes the primary key
+//
+// Parameters:
+//
+//   - id: The ID of the key, stored in the envelope of the values it encrypts
+//   - key: The key, which is copied. It must be 16, 24 or 32 bytes long for AES-GCM and AES-CTR-HMAC, 32, 48 or 64
+//     bytes long for AES-SIV, and 16 or 32 bytes long for AES-GCM-SIV
+//   - status: The status of the key
+//
+// Returns:
+//
+//   - An error if the ID, the key or the status is invalid, or if the ID is already in use
+func (k *KeyRing) AddKey(id string, key []byte, status KeyStatus) error {
+	// Check the ID, the key and the status
+	if id == "" {
+		return newError("KeyRing.AddKey", ErrEmptyKeyID)
+	}
+	if len(id) > MaxEnvelopeKeyIDLength {
+		return newError("KeyRing.AddKey", ErrEnvelopeKeyIDTooLong)
+	}
+	keySizes, err := k.algorithm.keySizes()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(keySizes, len(key)) {
+		return newError("KeyRing.AddKey", ErrInvalidKeySize)
+	}
+	if !status.isValid() {
+		return newError("KeyRing.AddKey", ErrInvalidKeyStatus)
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	// Check if the ID is already in use
+	if _, ok := k.keys[id]; ok {
+		return newError("KeyRing.AddKey", ErrDuplicateKeyID)
+	}
+
+	// Add the key, making it the primary key if there is none
+	k.keys[id] = &keyRingEntry{
+		key:    append([]byte(nil), key...),
+		status: status,
+	}
+	if k.primaryID == "" && status == KeyStatusActive {
+		k.primaryID = id
+	}
+	return nil
+}
+
+// SetPrimary sets the primary key, which is used to encrypt new values
+//
+// Parameters:
+//
+//   - id: The ID of the key, which must be active
+//
+// Returns:
+//
+//   - An error if the key is not found or is not active
+func (k *KeyRing) SetPrimary(id string) error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	entry, ok := k.keys[id]
+	if !ok {
+		return newError("KeyRing.SetPrimary", ErrKeyNotFound)
+	}
+	if entry.status != KeyStatusActive {
+		return newError("KeyRing.SetPrimary", ErrKeyNotActive)
+	}
+	k.primaryID = id
+	return nil
+}
+
+// SetStatus sets the status of a key. The primary key must remain active
+//
+// Parameters:
+//
+//   - id: The ID of the key
+//   - status: The new status of the key
+//
+// Returns:
+//
+//   - An error if the key is not found, the status is invalid or the key is the primary key
+func (k *KeyRing) SetStatus(id string, status KeyStatus) error {
+	if !status.isValid() {
+		return newError("KeyRing.SetStatus", ErrInvalidKeyStatus)
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	entry, ok := k.keys[id]
+	if !ok {
+		return newError("KeyRing.SetStatus", ErrKeyNotFound)
+	}
+	if id == k.primaryID && status != KeyStatusActive {
+		return newError("KeyRing.SetStatus", ErrPrimaryKeyNotActive)
+	}
+	entry.status = status
+	return nil
+}
+
+// Status returns the status of a key
+//
+// Parameters:
+//
+//   - id: The ID of the key
+//
+// Returns:
+//
+//   - The status of the key
+//   - An error if the key is not found
+func (k *KeyRing) Status(id string) (KeyStatus, error) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	entry, ok := k.keys[id]
+	if !ok {
+		return 0, newError("KeyRing.Status", ErrKeyNotFound)
+	}
+	return entry.status, nil
+}
+
+// PrimaryKeyID returns the ID of the primary key
+//
+// Returns:
+//
+//   - The ID of the primary key
+//   - An error if there is no primary key
+func (k *KeyRing) PrimaryKeyID() (string, error) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	if k.primaryID == "" {
+		return "", newError("KeyRing.PrimaryKeyID", ErrNoPrimaryKey)
+	}
+	return k.primaryID, nil
+}
+
+// Encrypt encrypts a string with the primary key and returns it as an envelope stamped with the primary key ID
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//
+// Returns:
+//
+//   - A pointer to the envelope in hexadecimal format
+//   - An error if any occurred during the encryption process
+func (k *KeyRing) Encrypt(plainText []byte) (*string, error) {
+	return k.EncryptWithAAD(plainText, nil)
+}
+
+// EncryptWithAAD encrypts a string with the primary key, binding it to the given additional authenticated data, and
+// returns it as an envelope stamped with the primary key ID
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - additionalData: The additional authenticated data
+//
+// Returns:
+//
+//   - A pointer to the envelope in hexadecimal format
+//   - An error if any occurred during the encryption process
+func (k *KeyRing) EncryptWithAAD(plainText, additionalData []byte) (
+	*string,
+	error,
+) {
+	// Encrypt the plain text into the binary envelope
+	data, err := k.EncryptBytes(plainText, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the envelope in hexadecimal format
+	enc := hex.EncodeToString(data)
+
+	return &enc, nil
+}
+
+// EncryptBytes encrypts a byte slice with the primary key, binding it to the given additional authenticated data, and
+// returns it as a binary envelope stamped with the primary key ID
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - additionalData: The additional authenticated data (may be nil)
+//
+// Returns:
+//
+//   - The binary form of the envelope
+//   - An error if any occurred during the encryption process
+func (k *KeyRing) EncryptBytes(plainText, additionalData []byte) (
+	[]byte,
+	error,
+) {
+	// Get the primary key, copying it while the lock is held
+	k.mutex.RLock()
+	primaryID := k.primaryID
+	entry := k.keys[primaryID]
+	var key []byte
+	if entry != nil {
+		key = entry.key
+	}
+	k.mutex.RUnlock()
+	if entry == nil {
+		return nil, newError("KeyRing.EncryptBytes", ErrNoPrimaryKey)
+	}
+
+	// Encrypt the plain text into the envelope
+	envelope := &Envelope{
+		Version:   EnvelopeVersion,
+		Algorithm: k.algorithm,
+		KeyID:     primaryID,
+	}
+	if err := envelope.seal(plainText, key, additionalData, false); err != nil {
+		return nil, err
+	}
+	return envelope.MarshalBinary()
+}
+
+// Decrypt decrypts an envelope with the key referenced by its key ID
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the envelope in hexadecimal format
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - True if the value was not encrypted with the primary key and should be re-encrypted, false otherwise
+//   - An error if any occurred during the decryption process
+func (k *KeyRing) Decrypt(encryptedText *string) (*string, bool, error) {
+	return k.DecryptWithAAD(encryptedText, nil)
+}
+
+// DecryptWithAAD decrypts an envelope with the key referenced by its key ID, verifying the given additional
+// authenticated data
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the envelope in hexadecimal format
+//   - additionalData: The additional authenticated data used for encryption
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - True if the value was not encrypted with the primary key and should be re-encrypted, false otherwise
+//   - An error if any occurred during the decryption process
+func (k *KeyRing) DecryptWithAAD(
+	encryptedText *string,
+	additionalData []byte,
+) (*string, bool, error) {
+	// Check if the encrypted text is nil
+	if encryptedText == nil {
+		return nil, false, newError("KeyRing.DecryptWithAAD", ErrNilEncryptedText)
+	}
+
+	// Decode the encrypted text from a hexadecimal string
+	data, err := decodeHex("KeyRing.DecryptWithAAD", *encryptedText)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Decrypt the binary envelope
+	plainText, needsReEncryption, err := k.DecryptBytes(data, additionalData)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Return the decrypted plain text
+	dec := string(plainText)
+
+	return &dec, needsReEncryption, nil
+}
+
+// DecryptBytes decrypts a binary envelope with the key referenced by its key ID, verifying the given additional
+// authenticated data
+//
+// Parameters:
+//
+//   - cipherText: The binary form of the envelope
+//   - additionalData: The additional authenticated data used for encryption (may be nil)
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - True if the value was not encrypted with the primary key and should be re-encrypted, false otherwise
+//   - ErrUnsupportedAlgorithm if the envelope was not produced with the algorithm of the key ring, or any other error
+//     that occurred during the decryption process
+func (k *KeyRing) DecryptBytes(cipherText, additionalData []byte) (
+	[]byte,
+	bool,
+	error,
+) {
+	// Decode the envelope
+	var envelope Envelope
+	if err := envelope.UnmarshalBinary(cipherText); err != nil {
+		return nil, false, err
+	}
+
+	// Check the envelope was produced with the algorithm of the key ring, so a key is never used with another mode
+	if envelope.Algorithm != k.algorithm {
+		return nil, false, newError("KeyRing.DecryptBytes", ErrUnsupportedAlgorithm)
+	}
+
+	// Look up the key referenced by the envelope, copying its fields while the lock is held
+	k.mutex.RLock()
+	entry := k.keys[envelope.KeyID]
+	primaryID := k.primaryID
+	var (
+		key    []byte
+		status KeyStatus
+	)
+	if entry != nil {
+		key, status = entry.key, entry.status
+	}
+	k.mutex.RUnlock()
+	if entry == nil {
+		return nil, false, newError("KeyRing.DecryptBytes", ErrKeyNotFound)
+	}
+	if status == KeyStatusRetired {
+		return nil, false, newError("KeyRing.DecryptBytes", ErrKeyRetired)
+	}
+
+	// Decrypt the envelope
+	plainText, err := envelope.open(key, additionalData, false)
+	if err != nil {
+		return nil, false, err
+	}
+	return plainText, envelope.KeyID != primaryID, nil
+}
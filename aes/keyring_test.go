@@ -0,0 +1,134 @@
+package aes
+
+import (
+	"errors"
+	"testing"
+)
+
+// newTestKeyRing creates a key ring of the given algorithm with an active key "key-1"
+func newTestKeyRing(t *testing.T, algorithm Algorithm, key []byte) *KeyRing {
+	t.Helper()
+
+	keyRing, err := NewKeyRing(algorithm)
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	if err = keyRing.AddKey("key-1", key, KeyStatusActive); err != nil {
+		t.Fatalf("KeyRing.AddKey: %v", err)
+	}
+	return keyRing
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	keyRing := newTestKeyRing(t, AlgorithmGCM, randomBytes(t, 32))
+
+	oldText, err := keyRing.Encrypt([]byte("old"))
+	if err != nil {
+		t.Fatalf("KeyRing.Encrypt: %v", err)
+	}
+
+	// Rotate to a new primary key
+	if err = keyRing.AddKey("key-2", randomBytes(t, 32), KeyStatusActive); err != nil {
+		t.Fatalf("KeyRing.AddKey: %v", err)
+	}
+	if err = keyRing.SetPrimary("key-2"); err != nil {
+		t.Fatalf("KeyRing.SetPrimary: %v", err)
+	}
+	if err = keyRing.SetStatus("key-1", KeyStatusDecryptOnly); err != nil {
+		t.Fatalf("KeyRing.SetStatus: %v", err)
+	}
+	newText, err := keyRing.Encrypt([]byte("new"))
+	if err != nil {
+		t.Fatalf("KeyRing.Encrypt: %v", err)
+	}
+
+	// Values of the old key still decrypt and are flagged for re-encryption
+	plainText, needsReEncryption, err := keyRing.Decrypt(oldText)
+	if err != nil || *plainText != "old" || !needsReEncryption {
+		t.Fatalf("KeyRing.Decrypt of the old value = %v, %v, %v", plainText, needsReEncryption, err)
+	}
+	plainText, needsReEncryption, err = keyRing.Decrypt(newText)
+	if err != nil || *plainText != "new" || needsReEncryption {
+		t.Fatalf("KeyRing.Decrypt of the new value = %v, %v, %v", plainText, needsReEncryption, err)
+	}
+
+	// Retired keys can no longer decrypt
+	if err = keyRing.SetStatus("key-1", KeyStatusRetired); err != nil {
+		t.Fatalf("KeyRing.SetStatus: %v", err)
+	}
+	if _, _, err = keyRing.Decrypt(oldText); !errors.Is(err, ErrKeyRetired) {
+		t.Fatalf("KeyRing.Decrypt with a retired key: got %v, want %v", err, ErrKeyRetired)
+	}
+}
+
+func TestKeyRingUnknownKey(t *testing.T) {
+	key := randomBytes(t, 32)
+	keyRing := newTestKeyRing(t, AlgorithmGCM, key)
+
+	encryptedText, err := Encrypt(AlgorithmGCM, []byte("data"), key, "key-3")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if _, _, err = keyRing.Decrypt(encryptedText); !errors.Is(
+		err,
+		ErrKeyNotFound,
+	) {
+		t.Fatalf("KeyRing.Decrypt with an unknown key ID: got %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestKeyRingAlgorithmMismatch(t *testing.T) {
+	// A 32-byte AES-GCM key is also a valid AES-GCM-SIV and AES-CTR-HMAC key
+	key := randomBytes(t, 32)
+	keyRing := newTestKeyRing(t, AlgorithmGCM, key)
+
+	for _, algorithm := range []Algorithm{AlgorithmGCMSIV, AlgorithmCTRHMAC} {
+		encryptedText, err := Encrypt(algorithm, []byte("data"), key, "key-1")
+		if err != nil {
+			t.Fatalf("Encrypt with %v: %v", algorithm, err)
+		}
+		if _, _, err = keyRing.Decrypt(encryptedText); !errors.Is(
+			err,
+			ErrUnsupportedAlgorithm,
+		) {
+			t.Fatalf("KeyRing.Decrypt of an %v envelope: got %v, want %v", algorithm, err, ErrUnsupportedAlgorithm)
+		}
+	}
+}
+
+func TestKeyRingAddKeyInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm Algorithm
+		id        string
+		keySize   int
+		status    KeyStatus
+		err       error
+	}{
+		{name: "empty ID", algorithm: AlgorithmGCM, keySize: 32, status: KeyStatusActive, err: ErrEmptyKeyID},
+		{name: "duplicate ID", algorithm: AlgorithmGCM, id: "key-1", keySize: 32, status: KeyStatusActive, err: ErrDuplicateKeyID},
+		{name: "invalid status", algorithm: AlgorithmGCM, id: "key-2", keySize: 32, err: ErrInvalidKeyStatus},
+		{name: "GCM key size", algorithm: AlgorithmGCM, id: "key-2", keySize: 64, status: KeyStatusActive, err: ErrInvalidKeySize},
+		{name: "SIV key size", algorithm: AlgorithmSIV, id: "key-2", keySize: 16, status: KeyStatusActive, err: ErrInvalidKeySize},
+		{name: "GCM-SIV key size", algorithm: AlgorithmGCMSIV, id: "key-2", keySize: 24, status: KeyStatusActive, err: ErrInvalidKeySize},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				keySizes, err := test.algorithm.keySizes()
+				if err != nil {
+					t.Fatalf("Algorithm.keySizes: %v", err)
+				}
+				keyRing := newTestKeyRing(t, test.algorithm, randomBytes(t, keySizes[len(keySizes)-1]))
+
+				if err = keyRing.AddKey(
+					test.id,
+					randomBytes(t, test.keySize),
+					test.status,
+				); !errors.Is(err, test.err) {
+					t.Fatalf("KeyRing.AddKey: got %v, want %v", err, test.err)
+				}
+			},
+		)
+	}
+}
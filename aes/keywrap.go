@@ -0,0 +1,272 @@
+package aes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+)
+
+// AES key wrap (RFC 3394) and AES key wrap with padding (RFC 5649) protect key material with a key encryption key.
+// The output is interoperable with HSM exports, JWE A128KW/A192KW/A256KW and cloud KMS key imports.
+
+const (
+	// keyWrapSemiblockSize is the size in bytes of a key wrap semiblock
+	keyWrapSemiblockSize = 8
+)
+
+var (
+	// keyWrapIV is the default initial value of RFC 3394
+	keyWrapIV = [keyWrapSemiblockSize]byte{
+		0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6,
+	}
+
+	// keyWrapPaddingIVPrefix is the constant prefix of the alternative initial value of RFC 5649
+	keyWrapPaddingIVPrefix = [4]byte{0xa6, 0x59, 0x59, 0xa6}
+)
+
+// wrap applies the RFC 3394 wrapping process
+//
+// Parameters:
+//
+//   - block: The AES cipher block of the key encryption key
+//   - iv: The initial value
+//   - plainText: The plain text, whose length must be a multiple of 8 bytes and at least 16 bytes
+//
+// Returns:
+//
+//   - The wrapped key
+func wrap(block cipher.Block, iv [keyWrapSemiblockSize]byte, plainText []byte) []byte {
+	n := len(plainText) / keyWrapSemiblockSize
+
+	// The output holds the integrity check register followed by the registers
+	output := make([]byte, keyWrapSemiblockSize+len(plainText))
+	copy(output[keyWrapSemiblockSize:], plainText)
+	a := iv
+
+	var b [aes.BlockSize]byte
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			r := output[i*keyWrapSemiblockSize : (i+1)*keyWrapSemiblockSize]
+
+			// B = AES(K, A | R[i])
+			copy(b[:keyWrapSemiblockSize], a[:])
+			copy(b[keyWrapSemiblockSize:], r)
+			block.Encrypt(b[:], b[:])
+
+			// A = MSB(64, B) ^ t, R[i] = LSB(64, B)
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(
+				a[:],
+				binary.BigEndian.Uint64(b[:keyWrapSemiblockSize])^t,
+			)
+			copy(r, b[keyWrapSemiblockSize:])
+		}
+	}
+	copy(output[:keyWrapSemiblockSize], a[:])
+	return output
+}
+
+// unwrap applies the RFC 3394 unwrapping process
+//
+// Parameters:
+//
+//   - block: The AES cipher block of the key encryption key
+//   - cipherText: The wrapped key, whose length must be a multiple of 8 bytes and at least 24 bytes
+//
+// Returns:
+//
+//   - The recovered integrity check register
+//   - The unwrapped key
+func unwrap(block cipher.Block, cipherText []byte) (
+	[keyWrapSemiblockSize]byte,
+	[]byte,
+) {
+	n := len(cipherText)/keyWrapSemiblockSize - 1
+
+	// Split the integrity check register from the registers
+	var a [keyWrapSemiblockSize]byte
+	copy(a[:], cipherText[:keyWrapSemiblockSize])
+	plainText := make([]byte, len(cipherText)-keyWrapSemiblockSize)
+	copy(plainText, cipherText[keyWrapSemiblockSize:])
+
+	var b [aes.BlockSize]byte
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			r := plainText[(i-1)*keyWrapSemiblockSize : i*keyWrapSemiblockSize]
+
+			// B = AES-1(K, (A ^ t) | R[i])
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(
+				b[:keyWrapSemiblockSize],
+				binary.BigEndian.Uint64(a[:])^t,
+			)
+			copy(b[keyWrapSemiblockSize:], r)
+			block.Decrypt(b[:], b[:])
+
+			// A = MSB(64, B), R[i] = LSB(64, B)
+			copy(a[:], b[:keyWrapSemiblockSize])
+			copy(r, b[keyWrapSemiblockSize:])
+		}
+	}
+	return a, plainText
+}
+
+// KeyWrap wraps a key using the AES key wrap algorithm (RFC 3394)
+//
+// Parameters:
+//
+//   - kek: The key encryption key (must be 16, 24 or 32 bytes long)
+//   - plainTextKey: The key to wrap (its length must be a multiple of 8 bytes and at least 16 bytes)
+//
+// Returns:
+//
+//   - The wrapped key, 8 bytes longer than the key
+//   - An error if any occurred during the wrapping process
+func KeyWrap(kek, plainTextKey []byte) ([]byte, error) {
+	// Check the key length
+	if len(plainTextKey) < 2*keyWrapSemiblockSize || len(plainTextKey)%keyWrapSemiblockSize != 0 {
+		return nil, newError("KeyWrap", ErrInvalidKeyWrapLength)
+	}
+
+	// Create a new AES cipher block with the key encryption key
+	block, err := newBlock("KeyWrap", kek)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(block, keyWrapIV, plainTextKey), nil
+}
+
+// KeyUnwrap unwraps a key wrapped with KeyWrap, verifying its integrity
+//
+// Parameters:
+//
+//   - kek: The key encryption key (must be 16, 24 or 32 bytes long)
+//   - wrappedKey: The wrapped key
+//
+// Returns:
+//
+//   - The unwrapped key
+//   - ErrAuthenticationFailed if the integrity check fails, or any other error that occurred during the unwrapping
+//     process
+func KeyUnwrap(kek, wrappedKey []byte) ([]byte, error) {
+	// Check the wrapped key length
+	if len(wrappedKey) < 3*keyWrapSemiblockSize || len(wrappedKey)%keyWrapSemiblockSize != 0 {
+		return nil, newError("KeyUnwrap", ErrInvalidKeyWrapLength)
+	}
+
+	// Create a new AES cipher block with the key encryption key
+	block, err := newBlock("KeyUnwrap", kek)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unwrap the key and check the integrity check register
+	a, plainTextKey := unwrap(block, wrappedKey)
+	if subtle.ConstantTimeCompare(a[:], keyWrapIV[:]) != 1 {
+		clear(plainTextKey)
+		return nil, newError("KeyUnwrap", ErrAuthenticationFailed)
+	}
+	return plainTextKey, nil
+}
+
+// KeyWrapWithPadding wraps a key of any length using the AES key wrap with padding algorithm (RFC 5649)
+//
+// Parameters:
+//
+//   - kek: The key encryption key (must be 16, 24 or 32 bytes long)
+//   - plainTextKey: The key to wrap (must not be empty)
+//
+// Returns:
+//
+//   - The wrapped key
+//   - An error if any occurred during the wrapping process
+func KeyWrapWithPadding(kek, plainTextKey []byte) ([]byte, error) {
+	// Check the key length, which must fit in the 32-bit message length indicator
+	if len(plainTextKey) == 0 || uint64(len(plainTextKey)) > 0xffffffff {
+		return nil, newError("KeyWrapWithPadding", ErrInvalidKeyWrapLength)
+	}
+
+	// Create a new AES cipher block with the key encryption key
+	block, err := newBlock("KeyWrapWithPadding", kek)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build the alternative initial value with the message length indicator
+	var iv [keyWrapSemiblockSize]byte
+	copy(iv[:4], keyWrapPaddingIVPrefix[:])
+	binary.BigEndian.PutUint32(iv[4:], uint32(len(plainTextKey)))
+
+	// Pad the key with zeros to a multiple of 8 bytes
+	paddedLength := (len(plainTextKey) + keyWrapSemiblockSize - 1) / keyWrapSemiblockSize * keyWrapSemiblockSize
+	padded := make([]byte, paddedLength)
+	copy(padded, plainTextKey)
+
+	// A single semiblock is encrypted as one AES block
+	if paddedLength == keyWrapSemiblockSize {
+		output := make([]byte, aes.BlockSize)
+		copy(output, iv[:])
+		copy(output[keyWrapSemiblockSize:], padded)
+		block.Encrypt(output, output)
+		return output, nil
+	}
+	return wrap(block, iv, padded), nil
+}
+
+// KeyUnwrapWithPadding unwraps a key wrapped with KeyWrapWithPadding, verifying its integrity and padding
+//
+// Parameters:
+//
+//   - kek: The key encryption key (must be 16, 24 or 32 bytes long)
+//   - wrappedKey: The wrapped key
+//
+// Returns:
+//
+//   - The unwrapped key
+//   - ErrAuthenticationFailed if the integrity check fails, or any other error that occurred during the unwrapping
+//     process
+func KeyUnwrapWithPadding(kek, wrappedKey []byte) ([]byte, error) {
+	// Check the wrapped key length
+	if len(wrappedKey) < 2*keyWrapSemiblockSize || len(wrappedKey)%keyWrapSemiblockSize != 0 {
+		return nil, newError("KeyUnwrapWithPadding", ErrInvalidKeyWrapLength)
+	}
+
+	// Create a new AES cipher block with the key encryption key
+	block, err := newBlock("KeyUnwrapWithPadding", kek)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single AES block holds a single semiblock
+	var a [keyWrapSemiblockSize]byte
+	var padded []byte
+	if len(wrappedKey) == aes.BlockSize {
+		output := make([]byte, aes.BlockSize)
+		block.Decrypt(output, wrappedKey)
+		copy(a[:], output[:keyWrapSemiblockSize])
+		padded = output[keyWrapSemiblockSize:]
+	} else {
+		a, padded = unwrap(block, wrappedKey)
+	}
+
+	// Check the prefix and the message length indicator
+	valid := subtle.ConstantTimeCompare(a[:4], keyWrapPaddingIVPrefix[:])
+	length := int(binary.BigEndian.Uint32(a[4:]))
+	if length <= len(padded)-keyWrapSemiblockSize || length > len(padded) {
+		valid = 0
+		length = len(padded)
+	}
+
+	// Check that the padding bytes are zero
+	var padding byte
+	for _, paddingByte := range padded[length:] {
+		padding |= paddingByte
+	}
+	valid &= subtle.ConstantTimeByteEq(padding, 0)
+	if valid != 1 {
+		clear(padded)
+		return nil, newError("KeyUnwrapWithPadding", ErrAuthenticationFailed)
+	}
+	return padded[:length], nil
+}
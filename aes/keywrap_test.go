@@ -0,0 +1,209 @@
+package aes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// mustDecodeHex decodes a hexadecimal test vector, failing the test if it is not valid
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid test vector %q: %v", s, err)
+	}
+	return data
+}
+
+// keyWrapVectors are the test vectors of RFC 3394, section 4
+var keyWrapVectors = []struct {
+	name       string
+	kek        string
+	key        string
+	wrappedKey string
+}{
+	{
+		name:       "128-bit key with 128-bit KEK",
+		kek:        "000102030405060708090A0B0C0D0E0F",
+		key:        "00112233445566778899AABBCCDDEEFF",
+		wrappedKey: "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5",
+	},
+	{
+		name:       "128-bit key with 192-bit KEK",
+		kek:        "000102030405060708090A0B0C0D0E0F1011121314151617",
+		key:        "00112233445566778899AABBCCDDEEFF",
+		wrappedKey: "96778B25AE6CA435F92B5B97C050AED2468AB8A17AD84E5D",
+	},
+	{
+		name:       "128-bit key with 256-bit KEK",
+		kek:        "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+		key:        "00112233445566778899AABBCCDDEEFF",
+		wrappedKey: "64E8C3F9CE0F5BA263E9777905818A2A93C8191E7D6E8AE7",
+	},
+	{
+		name:       "192-bit key with 192-bit KEK",
+		kek:        "000102030405060708090A0B0C0D0E0F1011121314151617",
+		key:        "00112233445566778899AABBCCDDEEFF0001020304050607",
+		wrappedKey: "031D33264E15D33268F24EC260743EDCE1C6C7DDEE725A936BA814915C6762D2",
+	},
+	{
+		name:       "192-bit key with 256-bit KEK",
+		kek:        "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+		key:        "00112233445566778899AABBCCDDEEFF0001020304050607",
+		wrappedKey: "A8F9BC1612C68B3FF6E6F4FBE30E71E4769C8B80A32CB8958CD5D17D6B254DA1",
+	},
+	{
+		name:       "256-bit key with 256-bit KEK",
+		kek:        "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+		key:        "00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F",
+		wrappedKey: "28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21",
+	},
+}
+
+// keyWrapWithPaddingVectors are the test vectors of RFC 5649, section 6
+var keyWrapWithPaddingVectors = []struct {
+	name       string
+	kek        string
+	key        string
+	wrappedKey string
+}{
+	{
+		name:       "20-octet key",
+		kek:        "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8",
+		key:        "c37b7e6492584340bed12207808941155068f738",
+		wrappedKey: "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a",
+	},
+	{
+		name:       "7-octet key",
+		kek:        "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8",
+		key:        "466f7250617369",
+		wrappedKey: "afbeb0f07dfbf5419200f2ccb50bb24f",
+	},
+}
+
+func TestKeyWrap(t *testing.T) {
+	for _, vector := range keyWrapVectors {
+		t.Run(
+			vector.name, func(t *testing.T) {
+				kek := mustDecodeHex(t, vector.kek)
+				key := mustDecodeHex(t, vector.key)
+				wrappedKey := mustDecodeHex(t, vector.wrappedKey)
+
+				got, err := KeyWrap(kek, key)
+				if err != nil {
+					t.Fatalf("KeyWrap: %v", err)
+				}
+				if !bytes.Equal(got, wrappedKey) {
+					t.Fatalf("KeyWrap = %x, want %x", got, wrappedKey)
+				}
+
+				got, err = KeyUnwrap(kek, wrappedKey)
+				if err != nil {
+					t.Fatalf("KeyUnwrap: %v", err)
+				}
+				if !bytes.Equal(got, key) {
+					t.Fatalf("KeyUnwrap = %x, want %x", got, key)
+				}
+			},
+		)
+	}
+}
+
+func TestKeyWrapWithPadding(t *testing.T) {
+	for _, vector := range keyWrapWithPaddingVectors {
+		t.Run(
+			vector.name, func(t *testing.T) {
+				kek := mustDecodeHex(t, vector.kek)
+				key := mustDecodeHex(t, vector.key)
+				wrappedKey := mustDecodeHex(t, vector.wrappedKey)
+
+				got, err := KeyWrapWithPadding(kek, key)
+				if err != nil {
+					t.Fatalf("KeyWrapWithPadding: %v", err)
+				}
+				if !bytes.Equal(got, wrappedKey) {
+					t.Fatalf("KeyWrapWithPadding = %x, want %x", got, wrappedKey)
+				}
+
+				got, err = KeyUnwrapWithPadding(kek, wrappedKey)
+				if err != nil {
+					t.Fatalf("KeyUnwrapWithPadding: %v", err)
+				}
+				if !bytes.Equal(got, key) {
+					t.Fatalf("KeyUnwrapWithPadding = %x, want %x", got, key)
+				}
+			},
+		)
+	}
+}
+
+func TestKeyUnwrapTampered(t *testing.T) {
+	vector := keyWrapVectors[len(keyWrapVectors)-1]
+	kek := mustDecodeHex(t, vector.kek)
+	wrappedKey := mustDecodeHex(t, vector.wrappedKey)
+
+	for i := range wrappedKey {
+		tampered := bytes.Clone(wrappedKey)
+		tampered[i] ^= 1
+		if _, err := KeyUnwrap(kek, tampered); !errors.Is(
+			err,
+			ErrAuthenticationFailed,
+		) {
+			t.Fatalf("KeyUnwrap with byte %d tampered: got %v, want %v", i, err, ErrAuthenticationFailed)
+		}
+	}
+}
+
+func TestKeyUnwrapWithPaddingTampered(t *testing.T) {
+	for _, vector := range keyWrapWithPaddingVectors {
+		kek := mustDecodeHex(t, vector.kek)
+		wrappedKey := mustDecodeHex(t, vector.wrappedKey)
+
+		for i := range wrappedKey {
+			tampered := bytes.Clone(wrappedKey)
+			tampered[i] ^= 1
+			if _, err := KeyUnwrapWithPadding(kek, tampered); !errors.Is(
+				err,
+				ErrAuthenticationFailed,
+			) {
+				t.Fatalf(
+					"%s: KeyUnwrapWithPadding with byte %d tampered: got %v, want %v",
+					vector.name,
+					i,
+					err,
+					ErrAuthenticationFailed,
+				)
+			}
+		}
+	}
+}
+
+func TestKeyWrapInvalidLength(t *testing.T) {
+	kek := make([]byte, 16)
+
+	for _, length := range []int{0, 8, 17, 23} {
+		if _, err := KeyWrap(kek, make([]byte, length)); !errors.Is(
+			err,
+			ErrInvalidKeyWrapLength,
+		) {
+			t.Fatalf("KeyWrap with %d bytes: got %v, want %v", length, err, ErrInvalidKeyWrapLength)
+		}
+	}
+	for _, length := range []int{0, 16, 25} {
+		if _, err := KeyUnwrap(kek, make([]byte, length)); !errors.Is(
+			err,
+			ErrInvalidKeyWrapLength,
+		) {
+			t.Fatalf("KeyUnwrap with %d bytes: got %v, want %v", length, err, ErrInvalidKeyWrapLength)
+		}
+	}
+	if _, err := KeyWrapWithPadding(kek, nil); !errors.Is(
+		err,
+		ErrInvalidKeyWrapLength,
+	) {
+		t.Fatalf("KeyWrapWithPadding with no key: got %v, want %v", err, ErrInvalidKeyWrapLength)
+	}
+}
@@ -0,0 +1,169 @@
+package aes
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// Laravel encrypts values with AES-128-CBC or AES-256-CBC (depending on the length of the application key) and
+// serializes them as the base64 encoding of a JSON object:
+//
+//	{"iv": base64(IV), "value": base64(cipher text), "mac": hex(HMAC-SHA256(key, iv || value)), "tag": ""}
+//
+// where the MAC is computed over the concatenation of the base64 encoded IV and cipher text, with the application key.
+// Values encrypted with Laravel's encrypt helper are PHP serialized, while values encrypted with encryptString are not.
+
+const (
+	// laravelKeyPrefix is the prefix of base64 encoded Laravel application keys
+	laravelKeyPrefix = "base64:"
+)
+
+type (
+	// laravelPayload is the JSON payload of a Laravel encrypted value
+	laravelPayload struct {
+		IV    string `json:"iv"`
+		Value string `json:"value"`
+		MAC   string `json:"mac"`
+		Tag   string `json:"tag"`
+	}
+)
+
+// ParseLaravelKey parses a Laravel application key (the APP_KEY setting), which is either raw or base64 encoded with
+// the "base64:" prefix
+//
+// Parameters:
+//
+//   - appKey: The application key
+//
+// Returns:
+//
+//   - The key
+//   - ErrInvalidKeySize if the key is not 16 or 32 bytes long, or ErrInvalidEncoding if it is not valid base64
+func ParseLaravelKey(appKey string) ([]byte, error) {
+	key := []byte(appKey)
+	if encodedKey, ok := strings.CutPrefix(appKey, laravelKeyPrefix); ok {
+		var err error
+		if key, err = base64.StdEncoding.DecodeString(encodedKey); err != nil {
+			return nil, newError("ParseLaravelKey", ErrInvalidEncoding)
+		}
+	}
+	if len(key) != 16 && len(key) != 32 {
+		return nil, newError("ParseLaravelKey", ErrInvalidKeySize)
+	}
+	return key, nil
+}
+
+// computeLaravelMAC computes the MAC of a Laravel payload
+//
+// Parameters:
+//
+//   - key: The application key
+//   - iv: The base64 encoded IV
+//   - value: The base64 encoded cipher text
+//
+// Returns:
+//
+//   - The MAC
+func computeLaravelMAC(key []byte, iv, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(iv))
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// EncryptLaravel encrypts a value into the payload format of Laravel's encryptString
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The application key (must be 16 or 32 bytes long), e.g. parsed with ParseLaravelKey
+//
+// Returns:
+//
+//   - The payload
+//   - An error if any occurred during the encryption process
+func EncryptLaravel(plainText, key []byte) (string, error) {
+	// Check the key size
+	if len(key) != 16 && len(key) != 32 {
+		return "", newError("EncryptLaravel", ErrInvalidKeySize)
+	}
+
+	// Encrypt the plain text using the CBC block cipher
+	cipherText, err := encryptCBC(plainText, key)
+	if err != nil {
+		return "", err
+	}
+
+	// Build the payload
+	payload := laravelPayload{
+		IV:    base64.StdEncoding.EncodeToString(cipherText[:cbcIVSize]),
+		Value: base64.StdEncoding.EncodeToString(cipherText[cbcIVSize:]),
+	}
+	payload.MAC = hex.EncodeToString(
+		computeLaravelMAC(key, payload.IV, payload.Value),
+	)
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecryptLaravel decrypts a payload produced by Laravel's encrypt or encryptString with the CBC ciphers, verifying its
+// MAC in constant time before decrypting
+//
+// Parameters:
+//
+//   - payload: The payload
+//   - key: The application key (must be 16 or 32 bytes long), e.g. parsed with ParseLaravelKey
+//
+// Returns:
+//
+//   - The decrypted plain text, which is PHP serialized if the value was encrypted with Laravel's encrypt helper
+//   - ErrAuthenticationFailed if the payload was tampered with, ErrInvalidLaravelPayload if it is malformed, or any
+//     other error that occurred during the decryption process
+func DecryptLaravel(payload string, key []byte) ([]byte, error) {
+	// Check the key size
+	if len(key) != 16 && len(key) != 32 {
+		return nil, newError("DecryptLaravel", ErrInvalidKeySize)
+	}
+
+	// Decode the payload
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, newError("DecryptLaravel", ErrInvalidLaravelPayload)
+	}
+	var decoded laravelPayload
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		return nil, newError("DecryptLaravel", ErrInvalidLaravelPayload)
+	}
+	if decoded.Tag != "" {
+		return nil, newError("DecryptLaravel", ErrUnsupportedAlgorithm)
+	}
+
+	// Decode the IV and the cipher text
+	iv, err := base64.StdEncoding.DecodeString(decoded.IV)
+	if err != nil || len(iv) != cbcIVSize {
+		return nil, newError("DecryptLaravel", ErrInvalidLaravelPayload)
+	}
+	value, err := base64.StdEncoding.DecodeString(decoded.Value)
+	if err != nil {
+		return nil, newError("DecryptLaravel", ErrInvalidLaravelPayload)
+	}
+
+	// Verify the MAC before decrypting
+	mac, err := hex.DecodeString(decoded.MAC)
+	if err != nil || !hmac.Equal(
+		mac,
+		computeLaravelMAC(key, decoded.IV, decoded.Value),
+	) {
+		return nil, newError("DecryptLaravel", ErrAuthenticationFailed)
+	}
+
+	// Decrypt the cipher text using the CBC block cipher
+	return decryptCBC(append(iv, value...), key)
+}
@@ -0,0 +1,160 @@
+package aes
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// laravelTestAppKey is the application key of the Laravel payloads, the bytes 0x00 to 0x1f
+const laravelTestAppKey = "base64:AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+
+// laravelPayloads are payloads in the format of Laravel's encrypter, with the IV "0123456789abcdef". The payloads of
+// older Laravel versions escape the slashes of the JSON object
+var laravelPayloads = []struct {
+	name      string
+	keySize   int
+	payload   string
+	plainText string
+}{
+	{
+		name:    "AES-256-CBC encryptString",
+		keySize: 32,
+		payload: "eyJpdiI6Ik1ERXlNelExTmpjNE9XRmlZMlJsWmc9PSIsInZhbHVlIjoienJUOXBGM3AyNTg5WDZ5L0MvVEZrdz09IiwibWFjIjoiYTN" +
+			"iNWQwOTg1Zjk2M2YzYjA4MWZiNjUyMGUwYjA2M2NjYWNiZDM5OTQ1OTBmZTQwZDI5ZDMxMzY5ZWM3NzA4NiIsInRhZyI6IiJ9",
+		plainText: "Hello, Laravel!",
+	},
+	{
+		name:    "AES-256-CBC encrypt with escaped slashes",
+		keySize: 32,
+		payload: "eyJpdiI6Ik1ERXlNelExTmpjNE9XRmlZMlJsWmc9PSIsInZhbHVlIjoieTNTOHJcL210RURJaktXRGdjYkttUkE9PSIsIm1hYyI6IjV" +
+			"lYmJmMTRmNTMxNzMxMmEwYWI0YThjYTJjMjg3MzQ4NDllNGU5MGVjYmMxZWI4MjBmZTA1NmNlYTkwNzc5YWIiLCJ0YWciOiIifQ==",
+		plainText: `s:5:"hello";`,
+	},
+	{
+		name:    "AES-128-CBC encryptString",
+		keySize: 16,
+		payload: "eyJpdiI6Ik1ERXlNelExTmpjNE9XRmlZMlJsWmc9PSIsInZhbHVlIjoiakhVUnVMQ3RMdjRRVGw2TVQ4UHB1eXdLSkhXaVV2eTRWaUh" +
+			"rY1Y2TThraz0iLCJtYWMiOiJlMGI3YzVmYTFlMGZlMGM5ZTE5NzViZDJmMzM5ODYzMWUzYzYwZTEzNzE4OWUyNmM4MTI3NTU3MDBjYT" +
+			"ZjZjRiIiwidGFnIjoiIn0=",
+		plainText: "sixteen byte msg",
+	},
+}
+
+// parseTestLaravelKey parses the application key of the Laravel payloads, truncated to the given size
+func parseTestLaravelKey(t *testing.T, keySize int) []byte {
+	t.Helper()
+
+	key, err := ParseLaravelKey(laravelTestAppKey)
+	if err != nil {
+		t.Fatalf("ParseLaravelKey: %v", err)
+	}
+	return key[:keySize]
+}
+
+func TestDecryptLaravelPayloads(t *testing.T) {
+	for _, test := range laravelPayloads {
+		t.Run(
+			test.name, func(t *testing.T) {
+				plainText, err := DecryptLaravel(test.payload, parseTestLaravelKey(t, test.keySize))
+				if err != nil {
+					t.Fatalf("DecryptLaravel: %v", err)
+				}
+				if string(plainText) != test.plainText {
+					t.Fatalf("DecryptLaravel = %q, want %q", plainText, test.plainText)
+				}
+			},
+		)
+	}
+}
+
+func TestLaravelRoundTrip(t *testing.T) {
+	for _, keySize := range []int{16, 32} {
+		key := randomBytes(t, keySize)
+
+		payload, err := EncryptLaravel([]byte("value"), key)
+		if err != nil {
+			t.Fatalf("EncryptLaravel: %v", err)
+		}
+		plainText, err := DecryptLaravel(payload, key)
+		if err != nil {
+			t.Fatalf("DecryptLaravel: %v", err)
+		}
+		if string(plainText) != "value" {
+			t.Fatalf("DecryptLaravel = %q, want %q", plainText, "value")
+		}
+	}
+}
+
+func TestDecryptLaravelInvalid(t *testing.T) {
+	key := parseTestLaravelKey(t, 32)
+
+	// encodePayload encodes a JSON object as a payload
+	encodePayload := func(json string) string {
+		return base64.StdEncoding.EncodeToString([]byte(json))
+	}
+	// authenticatedPayload builds a payload with a valid MAC
+	authenticatedPayload := func(iv, value []byte) string {
+		encodedIV := base64.StdEncoding.EncodeToString(iv)
+		encodedValue := base64.StdEncoding.EncodeToString(value)
+		mac := computeLaravelMAC(key, encodedIV, encodedValue)
+		return encodePayload(
+			`{"iv":"` + encodedIV + `","value":"` + encodedValue + `","mac":"` + hex.EncodeToString(mac) + `","tag":""}`,
+		)
+	}
+
+	tests := []struct {
+		name    string
+		payload string
+		err     error
+	}{
+		{name: "not base64", payload: "not base64!", err: ErrInvalidLaravelPayload},
+		{name: "not JSON", payload: encodePayload("not JSON"), err: ErrInvalidLaravelPayload},
+		{
+			name:    "short IV",
+			payload: encodePayload(`{"iv":"MDEyMw==","value":"","mac":"","tag":""}`),
+			err:     ErrInvalidLaravelPayload,
+		},
+		{
+			name:    "GCM tag",
+			payload: encodePayload(`{"iv":"","value":"","mac":"","tag":"dGFn"}`),
+			err:     ErrUnsupportedAlgorithm,
+		},
+		{
+			name:    "tampered MAC",
+			payload: strings.Replace(laravelPayloads[0].payload, "YTN", "YTR", 1),
+			err:     ErrAuthenticationFailed,
+		},
+		{
+			name:    "unaligned value",
+			payload: authenticatedPayload([]byte("0123456789abcdef"), make([]byte, 20)),
+			err:     ErrInvalidCiphertextLength,
+		},
+		{
+			name:    "invalid padding",
+			payload: authenticatedPayload([]byte("0123456789abcdef"), make([]byte, 16)),
+			err:     ErrInvalidPadding,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if _, err := DecryptLaravel(test.payload, key); !errors.Is(
+					err,
+					test.err,
+				) {
+					t.Fatalf("DecryptLaravel: got %v, want %v", err, test.err)
+				}
+			},
+		)
+	}
+
+	if _, err := DecryptLaravel(laravelPayloads[0].payload, key[:24]); !errors.Is(
+		err,
+		ErrInvalidKeySize,
+	) {
+		t.Fatalf("DecryptLaravel with a 24-byte key: got %v, want %v", err, ErrInvalidKeySize)
+	}
+}
@@ -0,0 +1,240 @@
+package aes
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// The parallel functions produce and consume the stream format of GCMStreamWriter, so a payload encrypted in
+// parallel can be decrypted with a GCMStreamReader and vice versa. Since every chunk nonce only depends on the nonce
+// prefix, the chunk index and the final flag, the chunks are sealed and opened independently by a pool of workers,
+// each one writing to its own region of the output.
+
+// forEachChunk calls fn for every chunk index using a pool of workers, stopping early once fn fails
+//
+// Parameters:
+//
+//   - chunks: The number of chunks
+//   - workers: The number of workers. If zero or negative, runtime.GOMAXPROCS(0) is used
+//   - fn: The function called for every chunk index
+//
+// Returns:
+//
+//   - The first error returned by fn, if any
+func forEachChunk(chunks uint64, workers int, fn func(index uint64) error) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if uint64(workers) > chunks {
+		workers = int(chunks)
+	}
+
+	var (
+		next      atomic.Uint64
+		failed    atomic.Bool
+		firstErr  error
+		errorOnce sync.Once
+		waitGroup sync.WaitGroup
+	)
+	for range workers {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for !failed.Load() {
+				// Claim the next chunk
+				index := next.Add(1) - 1
+				if index >= chunks {
+					return
+				}
+
+				if err := fn(index); err != nil {
+					errorOnce.Do(func() { firstErr = err })
+					failed.Store(true)
+					return
+				}
+			}
+		}()
+	}
+	waitGroup.Wait()
+	return firstErr
+}
+
+// EncryptGCMParallel encrypts a byte slice using the AES algorithm with the GCM block cipher mode, splitting it into
+// chunks that are encrypted concurrently. The output has the same format as GCMStreamWriter, with the chunk index and
+// the final chunk flag authenticated, so it can also be decrypted with a GCMStreamReader
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//   - chunkSize: The size in bytes of the plain text of each chunk. If zero, DefaultStreamChunkSize is used
+//   - workers: The number of chunks encrypted concurrently. If zero or negative, runtime.GOMAXPROCS(0) is used
+//
+// Returns:
+//
+//   - The stream header followed by the encrypted chunks
+//   - An error if any occurred during the encryption process
+func EncryptGCMParallel(plainText, key []byte, chunkSize, workers int) (
+	[]byte,
+	error,
+) {
+	// Check the chunk size
+	if chunkSize == 0 {
+		chunkSize = DefaultStreamChunkSize
+	}
+	if chunkSize < 0 || chunkSize > MaxStreamChunkSize {
+		return nil, newError("EncryptGCMParallel", ErrInvalidStreamChunkSize)
+	}
+
+	// Get the number of chunks, which is at least one, since an empty stream still has its final chunk
+	chunks := max(uint64(1), (uint64(len(plainText))+uint64(chunkSize)-1)/uint64(chunkSize))
+	if chunks-1 > math.MaxUint32 {
+		return nil, newError("EncryptGCMParallel", ErrStreamTooLong)
+	}
+
+	// Create the header with a random salt and nonce prefix
+	header := make([]byte, StreamHeaderSize)
+	if err := newStreamHeader(header, chunkSize); err != nil {
+		return nil, err
+	}
+
+	// Create the GCM block cipher with the stream key
+	gcm, err := newStreamGCM("EncryptGCMParallel", key, header)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the output, starting with the header
+	sealedSize := chunkSize + gcm.Overhead()
+	output := make(
+		[]byte,
+		StreamHeaderSize+len(plainText)+int(chunks)*gcm.Overhead(),
+	)
+	header = output[:copy(output, header)]
+
+	// Seal every chunk in its own region of the output, binding the header as additional data
+	body := output[StreamHeaderSize:]
+	err = forEachChunk(
+		chunks, workers, func(index uint64) error {
+			start := int(index) * chunkSize
+			end := min(start+chunkSize, len(plainText))
+			nonce := streamNonce(gcm, header, index, index == chunks-1)
+			offset := int(index) * sealedSize
+			gcm.Seal(body[offset:offset], nonce, plainText[start:end], header)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// DecryptGCMParallel decrypts a byte slice produced by EncryptGCMParallel or GCMStreamWriter, decrypting its chunks
+// concurrently. No plain text is returned unless every chunk is authenticated
+//
+// Parameters:
+//
+//   - cipherText: The stream header followed by the encrypted chunks
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//   - workers: The number of chunks decrypted concurrently. If zero or negative, runtime.GOMAXPROCS(0) is used
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - ErrStreamAuthenticationFailed if any chunk was tampered with, reordered or removed, or any other error that
+//     occurred during the decryption process
+func DecryptGCMParallel(cipherText, key []byte, workers int) (
+	[]byte,
+	error,
+) {
+	// Check the header
+	if len(cipherText) < StreamHeaderSize {
+		return nil, newError("DecryptGCMParallel", ErrInvalidStreamHeader)
+	}
+	header := cipherText[:StreamHeaderSize]
+	if header[0] != StreamVersion {
+		return nil, newError("DecryptGCMParallel", ErrUnsupportedStreamVersion)
+	}
+	chunkSize := int(binary.BigEndian.Uint32(header[1:streamSaltOffset]))
+	if chunkSize == 0 || chunkSize > MaxStreamChunkSize {
+		return nil, newError("DecryptGCMParallel", ErrInvalidStreamChunkSize)
+	}
+
+	// Create the GCM block cipher with the stream key
+	gcm, err := newStreamGCM("DecryptGCMParallel", key, header)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the number of chunks. Every chunk but the last one is full, and the stream has at least the final chunk
+	body := cipherText[StreamHeaderSize:]
+	if len(body) == 0 {
+		return nil, newError("DecryptGCMParallel", ErrStreamTruncated)
+	}
+	sealedSize := chunkSize + gcm.Overhead()
+	chunks := (uint64(len(body)) + uint64(sealedSize) - 1) / uint64(sealedSize)
+	if chunks-1 > math.MaxUint32 {
+		return nil, newError("DecryptGCMParallel", ErrStreamTooLong)
+	}
+	lastSealedSize := len(body) - int(chunks-1)*sealedSize
+	if lastSealedSize < gcm.Overhead() {
+		return nil, newError("DecryptGCMParallel", ErrStreamAuthenticationFailed)
+	}
+
+	// Open every chunk into its own region of the output, verifying the header as additional data
+	output := make([]byte, len(body)-int(chunks)*gcm.Overhead())
+	err = forEachChunk(
+		chunks, workers, func(index uint64) error {
+			offset := int(index) * sealedSize
+			end := min(offset+sealedSize, len(body))
+			nonce := streamNonce(gcm, header, index, index == chunks-1)
+			start := int(index) * chunkSize
+			if _, openErr := gcm.Open(
+				output[start:start],
+				nonce,
+				body[offset:end],
+				header,
+			); openErr != nil {
+				return newError(
+					"DecryptGCMParallel",
+					ErrStreamAuthenticationFailed,
+				)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		clear(output)
+		return nil, err
+	}
+	return output, nil
+}
+
+// streamNonce creates the nonce of a chunk of the stream
+//
+// Parameters:
+//
+//   - gcm: The GCM block cipher
+//   - header: The stream header, which contains the nonce prefix
+//   - index: The chunk index, which must fit in the chunk counter
+//   - final: Whether the chunk is the final chunk of the stream
+//
+// Returns:
+//
+//   - The chunk nonce
+func streamNonce(
+	gcm cipher.AEAD,
+	header []byte,
+	index uint64,
+	final bool,
+) []byte {
+	nonce := make([]byte, gcm.NonceSize())
+	copy(nonce, header[streamNoncePrefixOffset:])
+	setStreamNonce(nonce, uint32(index), final)
+	return nonce
+}
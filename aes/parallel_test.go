@@ -0,0 +1,356 @@
+package aes
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+const (
+	// parallelTestChunkSize is the chunk size in bytes used by the parallel GCM tests
+	parallelTestChunkSize = 1024
+
+	// parallelTestSealedSize is the size in bytes of each sealed chunk, including its 16-byte GCM tag
+	parallelTestSealedSize = parallelTestChunkSize + 16
+
+	// parallelTestWorkers is the number of workers used by the parallel GCM tests
+	parallelTestWorkers = 4
+
+	// parallelBenchmarkSize is the plain text size in bytes used by the parallel GCM benchmarks
+	parallelBenchmarkSize = 8 * 1024 * 1024
+)
+
+// parallelTestSizes are the plain text sizes in bytes used by the parallel GCM tests
+var parallelTestSizes = []int{
+	0,
+	1,
+	parallelTestChunkSize - 1,
+	parallelTestChunkSize,
+	parallelTestChunkSize + 1,
+	5*parallelTestChunkSize + 17,
+}
+
+// randomBytes returns a random byte slice of the given size, failing the test if it cannot be generated
+func randomBytes(t testing.TB, size int) []byte {
+	t.Helper()
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, data); err != nil {
+		t.Fatalf("generating random bytes: %v", err)
+	}
+	return data
+}
+
+// encryptParallelChunks encrypts a plain text of the given number of full chunks with EncryptGCMParallel
+func encryptParallelChunks(t *testing.T, key []byte, chunks int) []byte {
+	t.Helper()
+
+	cipherText, err := EncryptGCMParallel(
+		randomBytes(t, chunks*parallelTestChunkSize),
+		key,
+		parallelTestChunkSize,
+		parallelTestWorkers,
+	)
+	if err != nil {
+		t.Fatalf("EncryptGCMParallel: %v", err)
+	}
+	return cipherText
+}
+
+func TestGCMParallelRoundTrip(t *testing.T) {
+	key := randomBytes(t, 32)
+
+	for _, size := range parallelTestSizes {
+		t.Run(
+			fmt.Sprintf("%d", size), func(t *testing.T) {
+				plainText := randomBytes(t, size)
+
+				cipherText, err := EncryptGCMParallel(
+					plainText,
+					key,
+					parallelTestChunkSize,
+					parallelTestWorkers,
+				)
+				if err != nil {
+					t.Fatalf("EncryptGCMParallel: %v", err)
+				}
+				got, err := DecryptGCMParallel(cipherText, key, parallelTestWorkers)
+				if err != nil {
+					t.Fatalf("DecryptGCMParallel: %v", err)
+				}
+				if !bytes.Equal(got, plainText) {
+					t.Fatalf("DecryptGCMParallel returned a different plain text")
+				}
+			},
+		)
+	}
+}
+
+func TestGCMParallelStreamReader(t *testing.T) {
+	key := randomBytes(t, 32)
+
+	for _, size := range parallelTestSizes {
+		t.Run(
+			fmt.Sprintf("%d", size), func(t *testing.T) {
+				plainText := randomBytes(t, size)
+
+				cipherText, err := EncryptGCMParallel(
+					plainText,
+					key,
+					parallelTestChunkSize,
+					parallelTestWorkers,
+				)
+				if err != nil {
+					t.Fatalf("EncryptGCMParallel: %v", err)
+				}
+				reader, err := NewGCMStreamReader(bytes.NewReader(cipherText), key)
+				if err != nil {
+					t.Fatalf("NewGCMStreamReader: %v", err)
+				}
+				got, err := io.ReadAll(reader)
+				if err != nil {
+					t.Fatalf("GCMStreamReader.Read: %v", err)
+				}
+				if !bytes.Equal(got, plainText) {
+					t.Fatalf("GCMStreamReader returned a different plain text")
+				}
+			},
+		)
+	}
+}
+
+func TestGCMParallelStreamWriter(t *testing.T) {
+	key := randomBytes(t, 32)
+
+	for _, size := range parallelTestSizes {
+		t.Run(
+			fmt.Sprintf("%d", size), func(t *testing.T) {
+				plainText := randomBytes(t, size)
+
+				var cipherText bytes.Buffer
+				writer, err := NewGCMStreamWriter(
+					&cipherText,
+					key,
+					parallelTestChunkSize,
+				)
+				if err != nil {
+					t.Fatalf("NewGCMStreamWriter: %v", err)
+				}
+				if _, err = writer.Write(plainText); err != nil {
+					t.Fatalf("GCMStreamWriter.Write: %v", err)
+				}
+				if err = writer.Close(); err != nil {
+					t.Fatalf("GCMStreamWriter.Close: %v", err)
+				}
+				got, err := DecryptGCMParallel(
+					cipherText.Bytes(),
+					key,
+					parallelTestWorkers,
+				)
+				if err != nil {
+					t.Fatalf("DecryptGCMParallel: %v", err)
+				}
+				if !bytes.Equal(got, plainText) {
+					t.Fatalf("DecryptGCMParallel returned a different plain text")
+				}
+			},
+		)
+	}
+}
+
+func TestDecryptGCMParallelTruncated(t *testing.T) {
+	key := randomBytes(t, 32)
+	cipherText := encryptParallelChunks(t, key, 3)
+
+	// A stream with only its header has lost its final chunk
+	if _, err := DecryptGCMParallel(
+		cipherText[:StreamHeaderSize],
+		key,
+		parallelTestWorkers,
+	); !errors.Is(err, ErrStreamTruncated) {
+		t.Fatalf("DecryptGCMParallel with only the header: got %v, want %v", err, ErrStreamTruncated)
+	}
+
+	// A stream cut at a chunk boundary ends with a chunk that is not flagged as final
+	for chunks := 1; chunks < 3; chunks++ {
+		truncated := cipherText[:StreamHeaderSize+chunks*parallelTestSealedSize]
+		if _, err := DecryptGCMParallel(
+			truncated,
+			key,
+			parallelTestWorkers,
+		); !errors.Is(err, ErrStreamAuthenticationFailed) {
+			t.Fatalf(
+				"DecryptGCMParallel truncated to %d chunks: got %v, want %v",
+				chunks,
+				err,
+				ErrStreamAuthenticationFailed,
+			)
+		}
+	}
+
+	// A stream cut inside a chunk
+	if _, err := DecryptGCMParallel(
+		cipherText[:len(cipherText)-1],
+		key,
+		parallelTestWorkers,
+	); !errors.Is(err, ErrStreamAuthenticationFailed) {
+		t.Fatalf("DecryptGCMParallel truncated inside a chunk: got %v, want %v", err, ErrStreamAuthenticationFailed)
+	}
+}
+
+func TestDecryptGCMParallelReordered(t *testing.T) {
+	key := randomBytes(t, 32)
+	cipherText := encryptParallelChunks(t, key, 3)
+
+	// Swap the first two chunks
+	reordered := bytes.Clone(cipherText)
+	first := reordered[StreamHeaderSize : StreamHeaderSize+parallelTestSealedSize]
+	second := reordered[StreamHeaderSize+parallelTestSealedSize : StreamHeaderSize+2*parallelTestSealedSize]
+	swapped := bytes.Clone(first)
+	copy(first, second)
+	copy(second, swapped)
+
+	if _, err := DecryptGCMParallel(
+		reordered,
+		key,
+		parallelTestWorkers,
+	); !errors.Is(err, ErrStreamAuthenticationFailed) {
+		t.Fatalf("DecryptGCMParallel with reordered chunks: got %v, want %v", err, ErrStreamAuthenticationFailed)
+	}
+
+	// Replace a chunk with the chunk at the same index of another stream
+	other := encryptParallelChunks(t, key, 3)
+	spliced := bytes.Clone(cipherText)
+	copy(
+		spliced[StreamHeaderSize:StreamHeaderSize+parallelTestSealedSize],
+		other[StreamHeaderSize:StreamHeaderSize+parallelTestSealedSize],
+	)
+	if _, err := DecryptGCMParallel(
+		spliced,
+		key,
+		parallelTestWorkers,
+	); !errors.Is(err, ErrStreamAuthenticationFailed) {
+		t.Fatalf("DecryptGCMParallel with a chunk of another stream: got %v, want %v", err, ErrStreamAuthenticationFailed)
+	}
+}
+
+func TestDecryptGCMParallelAuthenticationFailed(t *testing.T) {
+	key := randomBytes(t, 32)
+	cipherText := encryptParallelChunks(t, key, 3)
+
+	// Stream failures are authentication failures of any other algorithm, whether the last chunk is too short to
+	// hold a tag or its tag does not match
+	for _, truncated := range [][]byte{
+		cipherText[:StreamHeaderSize+parallelTestSealedSize+1],
+		cipherText[:len(cipherText)-1],
+	} {
+		if _, err := DecryptGCMParallel(
+			truncated,
+			key,
+			parallelTestWorkers,
+		); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf(
+				"DecryptGCMParallel of %d bytes: got %v, want %v",
+				len(truncated),
+				err,
+				ErrAuthenticationFailed,
+			)
+		}
+	}
+}
+
+func TestDecryptGCMParallelTampered(t *testing.T) {
+	key := randomBytes(t, 32)
+	cipherText := encryptParallelChunks(t, key, 3)
+
+	// Flip a byte of the header and of each chunk
+	offsets := []int{StreamHeaderSize - 1}
+	for chunk := 0; chunk < 3; chunk++ {
+		offsets = append(offsets, StreamHeaderSize+chunk*parallelTestSealedSize+chunk)
+	}
+	for _, offset := range offsets {
+		tampered := bytes.Clone(cipherText)
+		tampered[offset] ^= 1
+
+		plainText, err := DecryptGCMParallel(tampered, key, parallelTestWorkers)
+		if !errors.Is(err, ErrStreamAuthenticationFailed) {
+			t.Fatalf(
+				"DecryptGCMParallel with byte %d tampered: got %v, want %v",
+				offset,
+				err,
+				ErrStreamAuthenticationFailed,
+			)
+		}
+		if plainText != nil {
+			t.Fatalf("DecryptGCMParallel with byte %d tampered returned a plain text", offset)
+		}
+	}
+}
+
+func BenchmarkGCMParallelEncrypt(b *testing.B) {
+	key := make([]byte, 32)
+	plainText := make([]byte, parallelBenchmarkSize)
+
+	b.Run(
+		"EncryptGCMBytes", func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(parallelBenchmarkSize)
+			for b.Loop() {
+				if _, err := EncryptGCMBytes(nil, plainText, key, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		},
+	)
+	b.Run(
+		"EncryptGCMParallel", func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(parallelBenchmarkSize)
+			for b.Loop() {
+				if _, err := EncryptGCMParallel(plainText, key, 0, 0); err != nil {
+					b.Fatal(err)
+				}
+			}
+		},
+	)
+}
+
+func BenchmarkGCMParallelDecrypt(b *testing.B) {
+	key := make([]byte, 32)
+	plainText := make([]byte, parallelBenchmarkSize)
+
+	cipherText, err := EncryptGCMBytes(nil, plainText, key, nil)
+	if err != nil {
+		b.Fatalf("EncryptGCMBytes: %v", err)
+	}
+	parallelCipherText, err := EncryptGCMParallel(plainText, key, 0, 0)
+	if err != nil {
+		b.Fatalf("EncryptGCMParallel: %v", err)
+	}
+
+	b.Run(
+		"DecryptGCMBytes", func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(parallelBenchmarkSize)
+			for b.Loop() {
+				if _, err := DecryptGCMBytes(nil, cipherText, key, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		},
+	)
+	b.Run(
+		"DecryptGCMParallel", func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(parallelBenchmarkSize)
+			for b.Loop() {
+				if _, err := DecryptGCMParallel(parallelCipherText, key, 0); err != nil {
+					b.Fatal(err)
+				}
+			}
+		},
+	)
+}
@@ -0,0 +1,567 @@
+package aes
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"slices"
+
+	gocryptopbkdf2 "github.com/ralvarezdev/go-crypto/pbkdf2"
+	gocryptorandombytes "github.com/ralvarezdev/go-crypto/random/bytes"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// The password format stores everything needed to derive the key again, so only the password is required to decrypt,
+// and ciphertexts produced with older KDF parameters remain decryptable after the defaults are upgraded.
+//
+// Binary: magic (2 bytes) || version (1 byte) || KDF (1 byte) || KDF parameters (3 x 4 bytes, big endian) ||
+// salt length (1 byte) || salt || nonce || cipher text
+//
+// The text form is the hexadecimal encoding of the binary form. The cipher text is produced with AES-256-GCM and the
+// whole header is bound to it as additional authenticated data.
+
+const (
+	// PasswordVersion is the current version of the password format
+	PasswordVersion = 1
+
+	// PasswordSaltSize is the size in bytes of the random salt
+	PasswordSaltSize = 16
+
+	// passwordKeySize is the size in bytes of the derived AES-256 key
+	passwordKeySize = 32
+
+	// passwordHeaderSize is the size in bytes of the password header before the salt
+	passwordHeaderSize = 17
+
+	// maxPasswordKDFMemory is the maximum memory in bytes that the KDF parameters of a ciphertext may require, so a
+	// crafted header cannot exhaust the memory of the decrypting process
+	maxPasswordKDFMemory = 4 << 30
+
+	// maxPasswordPBKDF2Iterations is the maximum number of PBKDF2 iterations of a ciphertext
+	maxPasswordPBKDF2Iterations = 10000000
+
+	// maxPasswordArgon2idPasses is the maximum number of Argon2id passes of a ciphertext
+	maxPasswordArgon2idPasses = 64
+)
+
+// KDF identifiers, stored in the password header
+const (
+	KDFPBKDF2SHA256 KDF = iota + 1
+	KDFScrypt
+	KDFArgon2id
+)
+
+var (
+	// PasswordMagic is the prefix that identifies a password-encrypted value
+	PasswordMagic = [2]byte{0xae, 0x50}
+
+	// DefaultPBKDF2Params are the default PBKDF2-SHA256 parameters
+	DefaultPBKDF2Params = PasswordParams{
+		KDF:        KDFPBKDF2SHA256,
+		Iterations: 600000,
+	}
+
+	// DefaultScryptParams are the default scrypt parameters
+	DefaultScryptParams = PasswordParams{
+		KDF:     KDFScrypt,
+		ScryptN: 1 << 15,
+		ScryptR: 8,
+		ScryptP: 1,
+	}
+
+	// DefaultArgon2idParams are the default Argon2id parameters (RFC 9106 second recommended option)
+	DefaultArgon2idParams = PasswordParams{
+		KDF:        KDFArgon2id,
+		Iterations: 3,
+		Memory:     64 * 1024,
+		Threads:    4,
+	}
+
+	// DefaultPasswordParams are the KDF parameters used by EncryptWithPassword
+	DefaultPasswordParams = DefaultPBKDF2Params
+
+	// DefaultPasswordLimits are the KDF limits used by DecryptWithPassword, which accept the default parameters of
+	// every KDF
+	DefaultPasswordLimits = PasswordLimits{
+		PBKDF2Iterations: DefaultPBKDF2Params.Iterations,
+		ScryptN:          DefaultScryptParams.ScryptN,
+		ScryptR:          DefaultScryptParams.ScryptR,
+		ScryptP:          DefaultScryptParams.ScryptP,
+		Argon2idPasses:   DefaultArgon2idParams.Iterations,
+		Argon2idMemory:   DefaultArgon2idParams.Memory,
+		Argon2idThreads:  DefaultArgon2idParams.Threads,
+	}
+)
+
+type (
+	// KDF is the identifier of the key derivation function used to derive a key from a password
+	KDF uint8
+
+	// PasswordParams are the key derivation function and its cost parameters
+	PasswordParams struct {
+		KDF KDF
+
+		// Iterations is the number of PBKDF2 iterations or the number of Argon2id passes
+		Iterations uint32
+
+		// Memory is the Argon2id memory in KiB
+		Memory uint32
+
+		// Threads is the Argon2id degree of parallelism
+		Threads uint32
+
+		// ScryptN is the scrypt CPU/memory cost, a power of two greater than 1
+		ScryptN uint32
+
+		// ScryptR is the scrypt block size
+		ScryptR uint32
+
+		// ScryptP is the scrypt parallelization
+		ScryptP uint32
+	}
+
+	// PasswordLimits are the maximum KDF parameters accepted when decrypting. The parameters are read from the
+	// ciphertext header, so the limits prevent a crafted ciphertext from exhausting the resources of the decrypting
+	// process. A KDF whose limits are zero is not accepted
+	PasswordLimits struct {
+		// PBKDF2Iterations is the maximum number of PBKDF2 iterations
+		PBKDF2Iterations uint32
+
+		// ScryptN is the maximum scrypt CPU/memory cost
+		ScryptN uint32
+
+		// ScryptR is the maximum scrypt block size
+		ScryptR uint32
+
+		// ScryptP is the maximum scrypt parallelization
+		ScryptP uint32
+
+		// Argon2idPasses is the maximum number of Argon2id passes
+		Argon2idPasses uint32
+
+		// Argon2idMemory is the maximum Argon2id memory in KiB
+		Argon2idMemory uint32
+
+		// Argon2idThreads is the maximum Argon2id degree of parallelism
+		Argon2idThreads uint32
+	}
+)
+
+// String returns the name of the KDF
+//
+// Returns:
+//
+//   - The name of the KDF
+func (k KDF) String() string {
+	switch k {
+	case KDFPBKDF2SHA256:
+		return "PBKDF2-SHA256"
+	case KDFScrypt:
+		return "scrypt"
+	case KDFArgon2id:
+		return "Argon2id"
+	default:
+		return "unknown"
+	}
+}
+
+// validate checks that the parameters are supported and within the allowed costs
+//
+// Returns:
+//
+//   - ErrUnsupportedKDF if the KDF is not supported, or ErrInvalidKDFParams if the parameters are not valid
+func (p *PasswordParams) validate() error {
+	switch p.KDF {
+	case KDFPBKDF2SHA256:
+		if p.Iterations == 0 || p.Iterations > maxPasswordPBKDF2Iterations {
+			return newError("PasswordParams.validate", ErrInvalidKDFParams)
+		}
+	case KDFScrypt:
+		if p.ScryptN <= 1 || p.ScryptN&(p.ScryptN-1) != 0 || p.ScryptR == 0 || p.ScryptP == 0 {
+			return newError("PasswordParams.validate", ErrInvalidKDFParams)
+		}
+		if uint64(p.ScryptR)*uint64(p.ScryptP) >= 1<<30 {
+			return newError("PasswordParams.validate", ErrInvalidKDFParams)
+		}
+		if 128*uint64(p.ScryptN)*uint64(p.ScryptR) > maxPasswordKDFMemory {
+			return newError("PasswordParams.validate", ErrInvalidKDFParams)
+		}
+	case KDFArgon2id:
+		if p.Iterations == 0 || p.Iterations > maxPasswordArgon2idPasses {
+			return newError("PasswordParams.validate", ErrInvalidKDFParams)
+		}
+		if p.Threads == 0 || p.Threads > 255 {
+			return newError("PasswordParams.validate", ErrInvalidKDFParams)
+		}
+		if p.Memory < 8*p.Threads || 1024*uint64(p.Memory) > maxPasswordKDFMemory {
+			return newError("PasswordParams.validate", ErrInvalidKDFParams)
+		}
+	default:
+		return newError("PasswordParams.validate", ErrUnsupportedKDF)
+	}
+	return nil
+}
+
+// check checks that the KDF parameters do not exceed the limits
+//
+// Parameters:
+//
+//   - params: The KDF parameters
+//
+// Returns:
+//
+//   - ErrKDFParamsExceedLimits if any parameter exceeds its limit
+func (l *PasswordLimits) check(params *PasswordParams) error {
+	var ok bool
+	switch params.KDF {
+	case KDFPBKDF2SHA256:
+		ok = params.Iterations <= l.PBKDF2Iterations
+	case KDFScrypt:
+		ok = params.ScryptN <= l.ScryptN && params.ScryptR <= l.ScryptR && params.ScryptP <= l.ScryptP
+	case KDFArgon2id:
+		ok = params.Iterations <= l.Argon2idPasses && params.Memory <= l.Argon2idMemory &&
+			params.Threads <= l.Argon2idThreads
+	}
+	if !ok {
+		return newError("PasswordLimits.check", ErrKDFParamsExceedLimits)
+	}
+	return nil
+}
+
+// deriveKey derives the AES-256 key from the password
+//
+// Parameters:
+//
+//   - password: The password
+//   - salt: The salt
+//
+// Returns:
+//
+//   - The derived key
+//   - An error if the parameters are not valid or the key derivation failed
+func (p *PasswordParams) deriveKey(password, salt []byte) (
+	[]byte,
+	error,
+) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	switch p.KDF {
+	case KDFPBKDF2SHA256:
+		return gocryptopbkdf2.DeriveKeyBytes(
+			password,
+			salt,
+			int(p.Iterations),
+			passwordKeySize,
+			sha256.New,
+		), nil
+	case KDFScrypt:
+		return scrypt.Key(
+			password,
+			salt,
+			int(p.ScryptN),
+			int(p.ScryptR),
+			int(p.ScryptP),
+			passwordKeySize,
+		)
+	default:
+		return argon2.IDKey(
+			password,
+			salt,
+			p.Iterations,
+			p.Memory,
+			uint8(p.Threads),
+			passwordKeySize,
+		), nil
+	}
+}
+
+// header returns the password header, which is bound as additional authenticated data
+//
+// Parameters:
+//
+//   - salt: The salt
+//
+// Returns:
+//
+//   - The password header
+func (p *PasswordParams) header(salt []byte) []byte {
+	header := make([]byte, passwordHeaderSize, passwordHeaderSize+len(salt))
+	copy(header, PasswordMagic[:])
+	header[2] = PasswordVersion
+	header[3] = byte(p.KDF)
+	switch p.KDF {
+	case KDFScrypt:
+		binary.BigEndian.PutUint32(header[4:8], p.ScryptN)
+		binary.BigEndian.PutUint32(header[8:12], p.ScryptR)
+		binary.BigEndian.PutUint32(header[12:16], p.ScryptP)
+	default:
+		binary.BigEndian.PutUint32(header[4:8], p.Iterations)
+		binary.BigEndian.PutUint32(header[8:12], p.Memory)
+		binary.BigEndian.PutUint32(header[12:16], p.Threads)
+	}
+	header[16] = byte(len(salt))
+	return append(header, salt...)
+}
+
+// parsePasswordHeader parses the password header of a password-encrypted value
+//
+// Parameters:
+//
+//   - data: The binary form of the password-encrypted value
+//
+// Returns:
+//
+//   - The KDF parameters
+//   - The header, including the salt
+//   - The salt
+//   - An error if the data is not a valid password-encrypted value
+func parsePasswordHeader(data []byte) (*PasswordParams, []byte, []byte, error) {
+	// Check the magic and the version
+	if len(data) < passwordHeaderSize || data[0] != PasswordMagic[0] || data[1] != PasswordMagic[1] {
+		return nil, nil, nil, newError("ParsePasswordParams", ErrInvalidPasswordHeader)
+	}
+	if data[2] != PasswordVersion {
+		return nil, nil, nil, newError("ParsePasswordParams", ErrUnsupportedPasswordVersion)
+	}
+
+	// Get the KDF parameters
+	params := PasswordParams{KDF: KDF(data[3])}
+	first := binary.BigEndian.Uint32(data[4:8])
+	second := binary.BigEndian.Uint32(data[8:12])
+	third := binary.BigEndian.Uint32(data[12:16])
+	switch params.KDF {
+	case KDFScrypt:
+		params.ScryptN, params.ScryptR, params.ScryptP = first, second, third
+	default:
+		params.Iterations, params.Memory, params.Threads = first, second, third
+	}
+	if err := params.validate(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Get the salt
+	saltLength := int(data[16])
+	if saltLength == 0 || len(data) < passwordHeaderSize+saltLength {
+		return nil, nil, nil, newError("ParsePasswordParams", ErrInvalidPasswordHeader)
+	}
+	headerSize := passwordHeaderSize + saltLength
+	return &params, data[:headerSize], data[passwordHeaderSize:headerSize], nil
+}
+
+// EncryptWithPassword encrypts a string with a key derived from the password using DefaultPasswordParams. The salt and
+// the KDF parameters are embedded in the encrypted string, so only the password is needed to decrypt it
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - password: The password
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptWithPassword(plainText []byte, password string) (*string, error) {
+	return EncryptWithPasswordParams(plainText, password, DefaultPasswordParams)
+}
+
+// EncryptWithPasswordParams encrypts a string with a key derived from the password using the given KDF parameters.
+// The salt and the KDF parameters are embedded in the encrypted string, so only the password is needed to decrypt it
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - password: The password
+//   - params: The KDF parameters
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptWithPasswordParams(
+	plainText []byte,
+	password string,
+	params PasswordParams,
+) (*string, error) {
+	return encryptWithPassword(plainText, []byte(password), params)
+}
+
+// encryptWithPassword encrypts a string with a key derived from the password bytes using the given KDF parameters
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - password: The password
+//   - params: The KDF parameters
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func encryptWithPassword(
+	plainText, password []byte,
+	params PasswordParams,
+) (*string, error) {
+	// Generate a random salt
+	salt, err := gocryptorandombytes.Generate(PasswordSaltSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive the key from the password
+	key, err := params.deriveKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+
+	// Encrypt the plain text after the header, binding the header as additional authenticated data
+	header := params.header(salt)
+	data, err := EncryptGCMBytes(slices.Clone(header), plainText, key, header)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the encrypted data as a hexadecimal string
+	enc := hex.EncodeToString(data)
+
+	return &enc, nil
+}
+
+// DecryptWithPassword decrypts a string produced by EncryptWithPassword or EncryptWithPasswordParams, deriving the key
+// with the KDF parameters embedded in it. Values whose parameters exceed DefaultPasswordLimits are rejected, see
+// DecryptWithPasswordLimits
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - password: The password
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - ErrAuthenticationFailed if the password is wrong or the encrypted text was tampered with, or any other error
+//     that occurred during the decryption process
+func DecryptWithPassword(encryptedText *string, password string) (
+	*string,
+	error,
+) {
+	return decryptWithPassword(
+		encryptedText,
+		[]byte(password),
+		DefaultPasswordLimits,
+	)
+}
+
+// DecryptWithPasswordLimits decrypts a string produced by EncryptWithPassword or EncryptWithPasswordParams, deriving
+// the key with the KDF parameters embedded in it only if they do not exceed the given limits
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - password: The password
+//   - limits: The maximum KDF parameters accepted
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - ErrKDFParamsExceedLimits if the KDF parameters exceed the limits, ErrAuthenticationFailed if the password is
+//     wrong or the encrypted text was tampered with, or any other error that occurred during the decryption process
+func DecryptWithPasswordLimits(
+	encryptedText *string,
+	password string,
+	limits PasswordLimits,
+) (*string, error) {
+	return decryptWithPassword(encryptedText, []byte(password), limits)
+}
+
+// decryptWithPassword decrypts a password-encrypted string with the password bytes
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - password: The password
+//   - limits: The maximum KDF parameters accepted
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - An error if any occurred during the decryption process
+func decryptWithPassword(
+	encryptedText *string,
+	password []byte,
+	limits PasswordLimits,
+) (*string, error) {
+	// Check if the encrypted text is nil
+	if encryptedText == nil {
+		return nil, newError("DecryptWithPassword", ErrNilEncryptedText)
+	}
+
+	// Decode the encrypted text from a hexadecimal string
+	data, err := decodeHex("DecryptWithPassword", *encryptedText)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the header
+	params, header, salt, err := parsePasswordHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the KDF parameters against the limits before deriving the key
+	if err = limits.check(params); err != nil {
+		return nil, err
+	}
+
+	// Derive the key from the password
+	key, err := params.deriveKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+
+	// Decrypt the cipher text
+	plainText, err := DecryptGCMBytes(nil, data[len(header):], key, header)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the decrypted plain text
+	dec := string(plainText)
+
+	return &dec, nil
+}
+
+// ParsePasswordParams returns the KDF parameters embedded in a string produced by EncryptWithPassword or
+// EncryptWithPasswordParams, so values encrypted with outdated parameters can be detected and re-encrypted
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//
+// Returns:
+//
+//   - A pointer to the KDF parameters
+//   - An error if the encrypted text is not a valid password-encrypted value
+func ParsePasswordParams(encryptedText *string) (*PasswordParams, error) {
+	// Check if the encrypted text is nil
+	if encryptedText == nil {
+		return nil, newError("ParsePasswordParams", ErrNilEncryptedText)
+	}
+
+	// Decode the encrypted text from a hexadecimal string
+	data, err := decodeHex("ParsePasswordParams", *encryptedText)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the header
+	params, _, _, err := parsePasswordHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	return params, nil
+}
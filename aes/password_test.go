@@ -0,0 +1,239 @@
+package aes
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Cheap KDF parameters of the tests
+var (
+	testPBKDF2Params = PasswordParams{
+		KDF:        KDFPBKDF2SHA256,
+		Iterations: 1000,
+	}
+	testScryptParams = PasswordParams{
+		KDF:     KDFScrypt,
+		ScryptN: 1 << 10,
+		ScryptR: 8,
+		ScryptP: 1,
+	}
+	testArgon2idParams = PasswordParams{
+		KDF:        KDFArgon2id,
+		Iterations: 1,
+		Memory:     64,
+		Threads:    1,
+	}
+)
+
+// encryptTestPassword encrypts the plain text with the password "password" and the given KDF parameters, returning
+// the binary form of the encrypted value
+func encryptTestPassword(t *testing.T, plainText []byte, params PasswordParams) []byte {
+	t.Helper()
+
+	encryptedText, err := EncryptWithPasswordParams(plainText, "password", params)
+	if err != nil {
+		t.Fatalf("EncryptWithPasswordParams: %v", err)
+	}
+	return mustDecodeHex(t, *encryptedText)
+}
+
+func TestPasswordRoundTrip(t *testing.T) {
+	for _, params := range []PasswordParams{
+		testPBKDF2Params,
+		testScryptParams,
+		testArgon2idParams,
+	} {
+		t.Run(
+			params.KDF.String(), func(t *testing.T) {
+				encryptedText := hex.EncodeToString(encryptTestPassword(t, []byte("secret"), params))
+
+				decryptedText, err := DecryptWithPassword(&encryptedText, "password")
+				if err != nil {
+					t.Fatalf("DecryptWithPassword: %v", err)
+				}
+				if *decryptedText != "secret" {
+					t.Fatalf("DecryptWithPassword = %q, want %q", *decryptedText, "secret")
+				}
+
+				if _, err = DecryptWithPassword(
+					&encryptedText,
+					"wrong password",
+				); !errors.Is(err, ErrAuthenticationFailed) {
+					t.Fatalf("DecryptWithPassword with the wrong password: got %v, want %v", err, ErrAuthenticationFailed)
+				}
+			},
+		)
+	}
+}
+
+func TestDecryptWithPasswordExceedsLimits(t *testing.T) {
+	// The costliest parameters that the format accepts would take seconds or gigabytes to derive the key from
+	tests := []struct {
+		name   string
+		params PasswordParams
+	}{
+		{
+			name:   "PBKDF2 iterations",
+			params: PasswordParams{KDF: KDFPBKDF2SHA256, Iterations: maxPasswordPBKDF2Iterations},
+		},
+		{
+			name:   "scrypt memory",
+			params: PasswordParams{KDF: KDFScrypt, ScryptN: 1 << 22, ScryptR: 8, ScryptP: 1},
+		},
+		{
+			name:   "scrypt parallelization",
+			params: PasswordParams{KDF: KDFScrypt, ScryptN: 1 << 10, ScryptR: 8, ScryptP: 1 << 16},
+		},
+		{
+			name:   "Argon2id passes",
+			params: PasswordParams{KDF: KDFArgon2id, Iterations: maxPasswordArgon2idPasses, Memory: 64, Threads: 1},
+		},
+		{
+			name:   "Argon2id memory",
+			params: PasswordParams{KDF: KDFArgon2id, Iterations: 1, Memory: maxPasswordKDFMemory / 1024, Threads: 1},
+		},
+		{
+			name:   "Argon2id threads",
+			params: PasswordParams{KDF: KDFArgon2id, Iterations: 1, Memory: 8 * 255, Threads: 255},
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				// Craft a value with a valid header, since no key can be derived to encrypt it
+				data := append(test.params.header(make([]byte, PasswordSaltSize)), make([]byte, 28)...)
+				encryptedText := hex.EncodeToString(data)
+
+				start := time.Now()
+				if _, err := DecryptWithPassword(
+					&encryptedText,
+					"password",
+				); !errors.Is(err, ErrKDFParamsExceedLimits) {
+					t.Fatalf("DecryptWithPassword: got %v, want %v", err, ErrKDFParamsExceedLimits)
+				}
+				if elapsed := time.Since(start); elapsed > time.Second {
+					t.Fatalf("DecryptWithPassword took %v, the key was derived before checking the limits", elapsed)
+				}
+			},
+		)
+	}
+
+	// A KDF whose limits are zero is not accepted
+	encryptedText := hex.EncodeToString(encryptTestPassword(t, []byte("secret"), testPBKDF2Params))
+	if _, err := DecryptWithPasswordLimits(
+		&encryptedText,
+		"password",
+		PasswordLimits{ScryptN: 1 << 20, ScryptR: 8, ScryptP: 1},
+	); !errors.Is(err, ErrKDFParamsExceedLimits) {
+		t.Fatalf("DecryptWithPasswordLimits without PBKDF2 limits: got %v, want %v", err, ErrKDFParamsExceedLimits)
+	}
+	if _, err := DecryptWithPasswordLimits(
+		&encryptedText,
+		"password",
+		PasswordLimits{PBKDF2Iterations: testPBKDF2Params.Iterations},
+	); err != nil {
+		t.Fatalf("DecryptWithPasswordLimits within the limits: %v", err)
+	}
+}
+
+func TestDecryptWithPasswordTamperedHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(data []byte)
+		err    error
+	}{
+		{
+			name:   "magic",
+			tamper: func(data []byte) { data[0] ^= 1 },
+			err:    ErrInvalidPasswordHeader,
+		},
+		{
+			name:   "version",
+			tamper: func(data []byte) { data[2] = PasswordVersion + 1 },
+			err:    ErrUnsupportedPasswordVersion,
+		},
+		{
+			name:   "unknown KDF",
+			tamper: func(data []byte) { data[3] = 0 },
+			err:    ErrUnsupportedKDF,
+		},
+		{
+			name:   "zero iterations",
+			tamper: func(data []byte) { binary.BigEndian.PutUint32(data[4:8], 0) },
+			err:    ErrInvalidKDFParams,
+		},
+		{
+			name:   "salt length beyond the data",
+			tamper: func(data []byte) { data[16] = 0xff },
+			err:    ErrInvalidPasswordHeader,
+		},
+		{
+			name:   "iterations within the limits",
+			tamper: func(data []byte) { binary.BigEndian.PutUint32(data[4:8], testPBKDF2Params.Iterations+1) },
+			err:    ErrAuthenticationFailed,
+		},
+		{
+			name:   "unused PBKDF2 parameter",
+			tamper: func(data []byte) { binary.BigEndian.PutUint32(data[8:12], 1) },
+			err:    ErrAuthenticationFailed,
+		},
+		{
+			name:   "salt",
+			tamper: func(data []byte) { data[passwordHeaderSize] ^= 1 },
+			err:    ErrAuthenticationFailed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				data := encryptTestPassword(t, []byte("secret"), testPBKDF2Params)
+				test.tamper(data)
+				encryptedText := hex.EncodeToString(data)
+
+				if _, err := DecryptWithPassword(
+					&encryptedText,
+					"password",
+				); !errors.Is(err, test.err) {
+					t.Fatalf("DecryptWithPassword: got %v, want %v", err, test.err)
+				}
+			},
+		)
+	}
+}
+
+func TestEncryptWithPasswordInvalidParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		params PasswordParams
+		err    error
+	}{
+		{name: "unknown KDF", params: PasswordParams{}, err: ErrUnsupportedKDF},
+		{name: "zero PBKDF2 iterations", params: PasswordParams{KDF: KDFPBKDF2SHA256}, err: ErrInvalidKDFParams},
+		{
+			name:   "scrypt cost not a power of two",
+			params: PasswordParams{KDF: KDFScrypt, ScryptN: 1000, ScryptR: 8, ScryptP: 1},
+			err:    ErrInvalidKDFParams,
+		},
+		{
+			name:   "Argon2id memory below 8 KiB per thread",
+			params: PasswordParams{KDF: KDFArgon2id, Iterations: 1, Memory: 7, Threads: 1},
+			err:    ErrInvalidKDFParams,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if _, err := EncryptWithPasswordParams(
+					[]byte("secret"),
+					"password",
+					test.params,
+				); !errors.Is(err, test.err) {
+					t.Fatalf("EncryptWithPasswordParams: got %v, want %v", err, test.err)
+				}
+			},
+		)
+	}
+}
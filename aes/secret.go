@@ -0,0 +1,229 @@
+package aes
+
+import (
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// NewGCMEncryptorWithSecretKey creates a new GCMEncryptor with the key held by a SecretKey. The SecretKey can be
+// destroyed once the encryptor is created
+//
+// Parameters:
+//
+//   - key: The key to use for encryption and decryption (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the GCMEncryptor
+//   - An error if the key is nil, destroyed or invalid
+func NewGCMEncryptorWithSecretKey(key *gocrypto.SecretKey) (
+	*GCMEncryptor,
+	error,
+) {
+	keyBytes, err := key.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return NewGCMEncryptor(keyBytes)
+}
+
+// NewCipherWithSecretKey creates the gocrypto.Cipher of the given algorithm with the key held by a SecretKey. The
+// SecretKey can be destroyed once the cipher is created
+//
+// Parameters:
+//
+//   - algorithm: The algorithm
+//   - key: The key to use for encryption and decryption
+//
+// Returns:
+//
+//   - The cipher
+//   - An error if the algorithm is not supported or the key is nil, destroyed or invalid
+func NewCipherWithSecretKey(algorithm Algorithm, key *gocrypto.SecretKey) (
+	gocrypto.Cipher,
+	error,
+) {
+	keyBytes, err := key.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return NewCipher(algorithm, keyBytes)
+}
+
+// AddSecretKey adds the key held by a SecretKey to the key ring. The key is copied, so the SecretKey can be destroyed
+// once it is added
+//
+// Parameters:
+//
+//   - id: The ID of the key, stored in the envelope of the values it encrypts
+//   - key: The key, which must have a valid size for the algorithm of the key ring
+//   - status: The status of the key
+//
+// Returns:
+//
+//   - An error if the ID, the key or the status is invalid, or if the ID is already in use
+func (k *KeyRing) AddSecretKey(
+	id string,
+	key *gocrypto.SecretKey,
+	status KeyStatus,
+) error {
+	keyBytes, err := key.Bytes()
+	if err != nil {
+		return err
+	}
+	return k.AddKey(id, keyBytes, status)
+}
+
+// EncryptWithSecretKey encrypts a string with the given algorithm and the key held by a SecretKey, and returns it as
+// an envelope
+//
+// Parameters:
+//
+//   - algorithm: The algorithm to use for encryption
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//   - keyID: The ID of the key, stored in the envelope header (may be empty)
+//   - additionalData: The additional authenticated data
+//
+// Returns:
+//
+//   - A pointer to the envelope in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptWithSecretKey(
+	algorithm Algorithm,
+	plainText []byte,
+	key *gocrypto.SecretKey,
+	keyID string,
+	additionalData []byte,
+) (*string, error) {
+	keyBytes, err := key.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return EncryptWithAAD(algorithm, plainText, keyBytes, keyID, additionalData)
+}
+
+// DecryptWithSecretKey decrypts an envelope with the key held by a SecretKey
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the envelope in hexadecimal format
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//   - additionalData: The additional authenticated data
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - An error if any occurred during the decryption process
+func DecryptWithSecretKey(
+	encryptedText *string,
+	key *gocrypto.SecretKey,
+	additionalData []byte,
+) (*string, error) {
+	keyBytes, err := key.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return DecryptWithAAD(encryptedText, keyBytes, additionalData)
+}
+
+// EncryptWithSecretPassword encrypts a string with a key derived from the password held by a SecretKey using the
+// default KDF parameters
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - password: The password
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptWithSecretPassword(
+	plainText []byte,
+	password *gocrypto.SecretKey,
+) (*string, error) {
+	return EncryptWithSecretPasswordParams(
+		plainText,
+		password,
+		DefaultPasswordParams,
+	)
+}
+
+// EncryptWithSecretPasswordParams encrypts a string with a key derived from the password held by a SecretKey using
+// the given KDF parameters. It produces the same format as EncryptWithPasswordParams
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - password: The password
+//   - params: The KDF parameters
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptWithSecretPasswordParams(
+	plainText []byte,
+	password *gocrypto.SecretKey,
+	params PasswordParams,
+) (*string, error) {
+	passwordBytes, err := password.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return encryptWithPassword(plainText, passwordBytes, params)
+}
+
+// DecryptWithSecretPassword decrypts a password-encrypted string with the password held by a SecretKey. Values whose
+// KDF parameters exceed DefaultPasswordLimits are rejected
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - password: The password
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - ErrAuthenticationFailed if the password is wrong or the encrypted text was tampered with, or any other error
+//     that occurred during the decryption process
+func DecryptWithSecretPassword(
+	encryptedText *string,
+	password *gocrypto.SecretKey,
+) (*string, error) {
+	passwordBytes, err := password.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return decryptWithPassword(
+		encryptedText,
+		passwordBytes,
+		DefaultPasswordLimits,
+	)
+}
+
+// DecryptWithSecretPasswordLimits decrypts a password-encrypted string with the password held by a SecretKey, only if
+// its KDF parameters do not exceed the given limits
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - password: The password
+//   - limits: The maximum KDF parameters accepted
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - ErrKDFParamsExceedLimits if the KDF parameters exceed the limits, ErrAuthenticationFailed if the password is
+//     wrong or the encrypted text was tampered with, or any other error that occurred during the decryption process
+func DecryptWithSecretPasswordLimits(
+	encryptedText *string,
+	password *gocrypto.SecretKey,
+	limits PasswordLimits,
+) (*string, error) {
+	passwordBytes, err := password.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return decryptWithPassword(encryptedText, passwordBytes, limits)
+}
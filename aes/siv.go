@@ -0,0 +1,307 @@
+package aes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// AES-SIV (RFC 5297) is a deterministic authenticated encryption mode: the same plain text and additional data
+// always produce the same cipher text under the same key, which allows equality lookups and unique indexes on
+// encrypted values. The synthetic IV is computed with S2V over AES-CMAC and used as the IV of AES-CTR.
+
+const (
+	// SIVSize is the size in bytes of the synthetic IV prepended to the cipher text
+	SIVSize = aes.BlockSize
+
+	// MaxSIVAdditionalData is the maximum number of additional data components
+	MaxSIVAdditionalData = 126
+)
+
+// dbl doubles a block in GF(2^128), as defined in RFC 5297
+//
+// Parameters:
+//
+//   - block: The block to double in place
+func dbl(block *[aes.BlockSize]byte) {
+	carry := block[0] >> 7
+	for i := 0; i < aes.BlockSize-1; i++ {
+		block[i] = block[i]<<1 | block[i+1]>>7
+	}
+	block[aes.BlockSize-1] = block[aes.BlockSize-1]<<1 ^ byte(subtle.ConstantTimeByteEq(carry, 1))*0x87
+}
+
+// cmac computes the AES-CMAC (RFC 4493) of a message
+//
+// Parameters:
+//
+//   - block: The AES cipher block
+//   - message: The message to authenticate
+//
+// Returns:
+//
+//   - The CMAC of the message
+func cmac(block cipher.Block, message []byte) [aes.BlockSize]byte {
+	// Generate the subkeys
+	var k1, k2 [aes.BlockSize]byte
+	block.Encrypt(k1[:], k1[:])
+	dbl(&k1)
+	k2 = k1
+	dbl(&k2)
+
+	// Process all the blocks except the last one
+	var mac [aes.BlockSize]byte
+	for len(message) > aes.BlockSize {
+		subtle.XORBytes(mac[:], mac[:], message[:aes.BlockSize])
+		block.Encrypt(mac[:], mac[:])
+		message = message[aes.BlockSize:]
+	}
+
+	// Process the last block, padding it if it is incomplete
+	var last [aes.BlockSize]byte
+	copy(last[:], message)
+	if len(message) == aes.BlockSize {
+		subtle.XORBytes(last[:], last[:], k1[:])
+	} else {
+		last[len(message)] = 0x80
+		subtle.XORBytes(last[:], last[:], k2[:])
+	}
+	subtle.XORBytes(mac[:], mac[:], last[:])
+	block.Encrypt(mac[:], mac[:])
+	return mac
+}
+
+// s2v computes the synthetic IV of the plain text and the additional data components, as defined in RFC 5297
+//
+// Parameters:
+//
+//   - block: The AES cipher block of the MAC key
+//   - additionalData: The additional data components
+//   - plainText: The plain text
+//
+// Returns:
+//
+//   - The synthetic IV
+func s2v(
+	block cipher.Block,
+	additionalData [][]byte,
+	plainText []byte,
+) [aes.BlockSize]byte {
+	// Start with the CMAC of a zero block
+	var zero [aes.BlockSize]byte
+	d := cmac(block, zero[:])
+
+	// Mix in each additional data component
+	for _, component := range additionalData {
+		dbl(&d)
+		mac := cmac(block, component)
+		subtle.XORBytes(d[:], d[:], mac[:])
+	}
+
+	// Mix in the plain text as the last component
+	if len(plainText) >= aes.BlockSize {
+		t := make([]byte, len(plainText))
+		copy(t, plainText)
+		offset := len(t) - aes.BlockSize
+		subtle.XORBytes(t[offset:], t[offset:], d[:])
+		return cmac(block, t)
+	}
+	dbl(&d)
+	var padded [aes.BlockSize]byte
+	copy(padded[:], plainText)
+	padded[len(plainText)] = 0x80
+	subtle.XORBytes(d[:], d[:], padded[:])
+	return cmac(block, d[:])
+}
+
+// newSIVCiphers creates the MAC and CTR cipher blocks from an AES-SIV key
+//
+// Parameters:
+//
+//   - key: The key (must be 32, 48 or 64 bytes long)
+//
+// Returns:
+//
+//   - The cipher block of the MAC key
+//   - The cipher block of the CTR key
+//   - An error if the key size is invalid
+func newSIVCiphers(key []byte) (cipher.Block, cipher.Block, error) {
+	// Check the key size
+	switch len(key) {
+	case 32, 48, 64:
+	default:
+		return nil, nil, newError("newSIVCiphers", ErrInvalidKeySize)
+	}
+
+	// The first half of the key is used for S2V and the second half for CTR
+	macBlock, err := newBlock("newSIVCiphers", key[:len(key)/2])
+	if err != nil {
+		return nil, nil, err
+	}
+	ctrBlock, err := newBlock("newSIVCiphers", key[len(key)/2:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return macBlock, ctrBlock, nil
+}
+
+// sivCTR applies AES-CTR with the counter derived from the synthetic IV
+//
+// Parameters:
+//
+//   - block: The AES cipher block of the CTR key
+//   - v: The synthetic IV
+//   - dst: The destination buffer
+//   - src: The source buffer
+func sivCTR(block cipher.Block, v [aes.BlockSize]byte, dst, src []byte) {
+	// Clear the 31st and 63rd rightmost bits of the counter
+	q := v
+	q[8] &= 0x7f
+	q[12] &= 0x7f
+	cipher.NewCTR(block, q[:]).XORKeyStream(dst, src)
+}
+
+// sealSIV encrypts the plain text using AES-SIV
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 32, 48 or 64 bytes long)
+//   - additionalData: The additional data components
+//
+// Returns:
+//
+//   - The synthetic IV followed by the cipher text
+//   - An error if any occurred during the encryption process
+func sealSIV(plainText, key []byte, additionalData [][]byte) ([]byte, error) {
+	// Check the number of additional data components
+	if len(additionalData) > MaxSIVAdditionalData {
+		return nil, newError("EncryptSIV", ErrTooManyAdditionalData)
+	}
+
+	// Create the cipher blocks
+	macBlock, ctrBlock, err := newSIVCiphers(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compute the synthetic IV and encrypt the plain text
+	v := s2v(macBlock, additionalData, plainText)
+	cipherText := make([]byte, SIVSize+len(plainText))
+	copy(cipherText, v[:])
+	sivCTR(ctrBlock, v, cipherText[SIVSize:], plainText)
+	return cipherText, nil
+}
+
+// openSIV decrypts a cipher text produced by sealSIV, verifying its synthetic IV in constant time
+//
+// Parameters:
+//
+//   - cipherText: The synthetic IV followed by the cipher text
+//   - key: The key to use for decryption (must be 32, 48 or 64 bytes long)
+//   - additionalData: The additional data components used for encryption
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - An error if any occurred during the decryption process
+func openSIV(cipherText, key []byte, additionalData [][]byte) ([]byte, error) {
+	// Check the number of additional data components
+	if len(additionalData) > MaxSIVAdditionalData {
+		return nil, newError("DecryptSIV", ErrTooManyAdditionalData)
+	}
+
+	// Create the cipher blocks
+	macBlock, ctrBlock, err := newSIVCiphers(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the synthetic IV from the cipher text
+	if len(cipherText) < SIVSize {
+		return nil, newError("DecryptSIV", ErrCiphertextTooShort)
+	}
+	var v [aes.BlockSize]byte
+	copy(v[:], cipherText[:SIVSize])
+	cipherText = cipherText[SIVSize:]
+
+	// Decrypt the cipher text and verify the synthetic IV
+	plainText := make([]byte, len(cipherText))
+	sivCTR(ctrBlock, v, plainText, cipherText)
+	expected := s2v(macBlock, additionalData, plainText)
+	if subtle.ConstantTimeCompare(v[:], expected[:]) != 1 {
+		clear(plainText)
+		return nil, newError("DecryptSIV", ErrAuthenticationFailed)
+	}
+	return plainText, nil
+}
+
+// EncryptSIV deterministically encrypts a string using AES-SIV (RFC 5297)
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 32, 48 or 64 bytes long, for AES-128, AES-192 or AES-256)
+//   - additionalData: The additional data components, which are authenticated but not encrypted
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptSIV(plainText, key []byte, additionalData ...[]byte) (
+	*string,
+	error,
+) {
+	// Encrypt the plain text using AES-SIV
+	cipherText, err := sealSIV(plainText, key, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the encrypted cipher text as a hexadecimal string
+	enc := hex.EncodeToString(cipherText)
+
+	return &enc, nil
+}
+
+// DecryptSIV decrypts a string produced by EncryptSIV
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - key: The key to use for decryption (must be 32, 48 or 64 bytes long)
+//   - additionalData: The additional data components used for encryption
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - ErrAuthenticationFailed if the encrypted text was tampered with or the additional data does not match, or any
+//     other error that occurred during the decryption process
+func DecryptSIV(
+	encryptedText *string,
+	key []byte,
+	additionalData ...[]byte,
+) (*string, error) {
+	// Check if the encrypted text is nil
+	if encryptedText == nil {
+		return nil, newError("DecryptSIV", ErrNilEncryptedText)
+	}
+
+	// Decode the encrypted text from a hexadecimal string
+	cipherText, err := decodeHex("DecryptSIV", *encryptedText)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt the cipher text using AES-SIV
+	plainText, err := openSIV(cipherText, key, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the decrypted plain text
+	dec := string(plainText)
+
+	return &dec, nil
+}
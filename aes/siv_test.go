@@ -0,0 +1,183 @@
+package aes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// sivVectors are the test vectors of RFC 5297, appendix A. The nonce of the nonce-based vector is passed as the last
+// additional data component, as defined in section 3
+var sivVectors = []struct {
+	name           string
+	key            string
+	additionalData []string
+	plainText      string
+	cipherText     string
+}{
+	{
+		name:           "A.1 deterministic authenticated encryption",
+		key:            "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+		additionalData: []string{"101112131415161718191a1b1c1d1e1f2021222324252627"},
+		plainText:      "112233445566778899aabbccddee",
+		cipherText:     "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c",
+	},
+	{
+		name: "A.2 nonce-based authenticated encryption",
+		key:  "7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f",
+		additionalData: []string{
+			"00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100",
+			"102030405060708090a0",
+			"09f911029d74e35bd84156c5635688c0",
+		},
+		plainText: "7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553",
+		cipherText: "7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17dba77ceb094fa663b7a3f748ba8af829" +
+			"ea64ad544a272e9c485b62a3fd5c0d",
+	},
+}
+
+// decodeSIVAdditionalData decodes the additional data components of a test vector
+func decodeSIVAdditionalData(t *testing.T, components []string) [][]byte {
+	t.Helper()
+
+	additionalData := make([][]byte, len(components))
+	for i, component := range components {
+		additionalData[i] = mustDecodeHex(t, component)
+	}
+	return additionalData
+}
+
+func TestSIVVectors(t *testing.T) {
+	for _, vector := range sivVectors {
+		t.Run(
+			vector.name, func(t *testing.T) {
+				key := mustDecodeHex(t, vector.key)
+				additionalData := decodeSIVAdditionalData(t, vector.additionalData)
+				plainText := mustDecodeHex(t, vector.plainText)
+
+				encryptedText, err := EncryptSIV(plainText, key, additionalData...)
+				if err != nil {
+					t.Fatalf("EncryptSIV: %v", err)
+				}
+				if *encryptedText != vector.cipherText {
+					t.Fatalf("EncryptSIV = %s, want %s", *encryptedText, vector.cipherText)
+				}
+
+				decryptedText, err := DecryptSIV(encryptedText, key, additionalData...)
+				if err != nil {
+					t.Fatalf("DecryptSIV: %v", err)
+				}
+				if !bytes.Equal([]byte(*decryptedText), plainText) {
+					t.Fatalf("DecryptSIV = %x, want %x", *decryptedText, plainText)
+				}
+			},
+		)
+	}
+}
+
+func TestSIVTampered(t *testing.T) {
+	for _, vector := range sivVectors {
+		t.Run(
+			vector.name, func(t *testing.T) {
+				key := mustDecodeHex(t, vector.key)
+				additionalData := decodeSIVAdditionalData(t, vector.additionalData)
+				cipherText := mustDecodeHex(t, vector.cipherText)
+
+				// Flip a bit of every byte of the synthetic IV and the cipher text
+				for i := range cipherText {
+					tampered := bytes.Clone(cipherText)
+					tampered[i] ^= 1
+					encryptedText := hex.EncodeToString(tampered)
+					if _, err := DecryptSIV(
+						&encryptedText,
+						key,
+						additionalData...,
+					); !errors.Is(err, ErrAuthenticationFailed) {
+						t.Fatalf("DecryptSIV with byte %d tampered: got %v, want %v", i, err, ErrAuthenticationFailed)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestSIVWrongAdditionalData(t *testing.T) {
+	for _, vector := range sivVectors {
+		t.Run(
+			vector.name, func(t *testing.T) {
+				key := mustDecodeHex(t, vector.key)
+				additionalData := decodeSIVAdditionalData(t, vector.additionalData)
+
+				// Modify a component, drop the last one, reorder them and add an empty one
+				modified := decodeSIVAdditionalData(t, vector.additionalData)
+				modified[0][0] ^= 1
+				wrongAdditionalData := [][][]byte{
+					modified,
+					additionalData[:len(additionalData)-1],
+					append([][]byte{{}}, additionalData...),
+				}
+				if len(additionalData) > 1 {
+					reordered := append([][]byte{additionalData[1], additionalData[0]}, additionalData[2:]...)
+					wrongAdditionalData = append(wrongAdditionalData, reordered)
+				}
+
+				for i, wrong := range wrongAdditionalData {
+					encryptedText := vector.cipherText
+					if _, err := DecryptSIV(
+						&encryptedText,
+						key,
+						wrong...,
+					); !errors.Is(err, ErrAuthenticationFailed) {
+						t.Fatalf("DecryptSIV with wrong additional data %d: got %v, want %v", i, err, ErrAuthenticationFailed)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestSIVDeterministic(t *testing.T) {
+	key := randomBytes(t, 64)
+	plainText := []byte("user@example.com")
+
+	first, err := EncryptSIV(plainText, key, []byte("email"))
+	if err != nil {
+		t.Fatalf("EncryptSIV: %v", err)
+	}
+	second, err := EncryptSIV(plainText, key, []byte("email"))
+	if err != nil {
+		t.Fatalf("EncryptSIV: %v", err)
+	}
+	if *first != *second {
+		t.Fatalf("EncryptSIV is not deterministic: %s != %s", *first, *second)
+	}
+}
+
+func TestSIVInvalid(t *testing.T) {
+	for _, keySize := range []int{16, 32 + 1, 128} {
+		if _, err := EncryptSIV(
+			nil,
+			make([]byte, keySize),
+		); !errors.Is(err, ErrInvalidKeySize) {
+			t.Fatalf("EncryptSIV with a %d-byte key: got %v, want %v", keySize, err, ErrInvalidKeySize)
+		}
+	}
+
+	key := randomBytes(t, 32)
+	tooMany := make([][]byte, MaxSIVAdditionalData+1)
+	if _, err := EncryptSIV(nil, key, tooMany...); !errors.Is(
+		err,
+		ErrTooManyAdditionalData,
+	) {
+		t.Fatalf("EncryptSIV with too many components: got %v, want %v", err, ErrTooManyAdditionalData)
+	}
+
+	short := hex.EncodeToString(make([]byte, SIVSize-1))
+	if _, err := DecryptSIV(&short, key); !errors.Is(
+		err,
+		ErrCiphertextTooShort,
+	) {
+		t.Fatalf("DecryptSIV of a short value: got %v, want %v", err, ErrCiphertextTooShort)
+	}
+}
@@ -0,0 +1,446 @@
+package aes
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// The stream format is a segmented AEAD construction (STREAM). The output starts with a header followed by a
+// sequence of GCM sealed chunks. Each stream is encrypted with its own key, derived with HKDF-SHA256 from the caller
+// key and the random salt stored in the header, so the short nonce prefix of streams encrypted with the same key can
+// collide without reusing a GCM key and nonce pair. Each chunk nonce is derived from the random nonce prefix stored in the header, the
+// chunk counter and a final chunk flag, so truncation, reordering and chunk swapping between streams are detected.
+//
+// Header: version (1 byte) || chunk size (4 bytes, big endian) || salt (32 bytes) || nonce prefix (7 bytes)
+// Chunk nonce: nonce prefix (7 bytes) || counter (4 bytes, big endian) || final flag (1 byte)
+
+const (
+	// StreamVersion is the version of the stream format
+	StreamVersion = 2
+
+	// DefaultStreamChunkSize is the default size in bytes of the plain text of each chunk
+	DefaultStreamChunkSize = 64 * 1024
+
+	// MaxStreamChunkSize is the maximum size in bytes of the plain text of each chunk
+	MaxStreamChunkSize = 16 * 1024 * 1024
+
+	// StreamSaltSize is the size in bytes of the random salt stored in the stream header, from which the stream key is
+	// derived
+	StreamSaltSize = 32
+
+	// StreamNoncePrefixSize is the size in bytes of the random nonce prefix stored in the stream header
+	StreamNoncePrefixSize = 7
+
+	// StreamHeaderSize is the size in bytes of the stream header
+	StreamHeaderSize = 1 + 4 + StreamSaltSize + StreamNoncePrefixSize
+
+	// streamSaltOffset is the offset of the salt in the stream header
+	streamSaltOffset = 1 + 4
+
+	// streamNoncePrefixOffset is the offset of the nonce prefix in the stream header
+	streamNoncePrefixOffset = streamSaltOffset + StreamSaltSize
+)
+
+var (
+	// streamKeyInfo is the HKDF info used to derive the key of each stream
+	streamKeyInfo = "go-crypto/aes/stream/gcm-hkdf-sha256"
+)
+
+type (
+	// GCMStreamWriter is an io.WriteCloser that encrypts the written data using the AES algorithm with the GCM block
+	// cipher mode, splitting it into authenticated chunks
+	GCMStreamWriter struct {
+		writer        io.Writer
+		gcm           cipher.AEAD
+		header        []byte
+		nonce         []byte
+		buffer        []byte
+		sealed        []byte
+		chunkSize     int
+		counter       uint32
+		headerWritten bool
+		closed        bool
+		err           error
+	}
+
+	// GCMStreamReader is an io.Reader that decrypts data produced by a GCMStreamWriter, verifying each chunk before
+	// returning its plain text
+	GCMStreamReader struct {
+		reader    *bufio.Reader
+		gcm       cipher.AEAD
+		header    []byte
+		nonce     []byte
+		chunk     []byte
+		plainText []byte
+		chunkSize int
+		counter   uint32
+		done      bool
+		err       error
+	}
+)
+
+// setStreamNonce sets the chunk nonce for the given counter and final flag
+//
+// Parameters:
+//
+//   - nonce: The nonce buffer, which must already contain the nonce prefix
+//   - counter: The chunk counter
+//   - final: Whether the chunk is the final chunk of the stream
+func setStreamNonce(nonce []byte, counter uint32, final bool) {
+	binary.BigEndian.PutUint32(nonce[StreamNoncePrefixSize:], counter)
+	if final {
+		nonce[len(nonce)-1] = 1
+	} else {
+		nonce[len(nonce)-1] = 0
+	}
+}
+
+// newStreamHeader creates a stream header with a random salt and nonce prefix
+//
+// Parameters:
+//
+//   - header: The header buffer, which must be StreamHeaderSize bytes long
+//   - chunkSize: The size in bytes of the plain text of each chunk
+//
+// Returns:
+//
+//   - An error if the random salt or nonce prefix could not be generated
+func newStreamHeader(header []byte, chunkSize int) error {
+	header[0] = StreamVersion
+	binary.BigEndian.PutUint32(header[1:streamSaltOffset], uint32(chunkSize))
+	_, err := io.ReadFull(rand.Reader, header[streamSaltOffset:])
+	return err
+}
+
+// newStreamGCM creates the GCM block cipher of a stream, keyed with the stream key derived from the given key and the
+// salt stored in the stream header
+//
+// Parameters:
+//
+//   - op: The name of the operation, reported by the errors
+//   - key: The key (must be 16, 24 or 32 bytes long)
+//   - header: The stream header
+//
+// Returns:
+//
+//   - The GCM block cipher
+//   - An error if the key size is invalid or the stream key could not be derived
+func newStreamGCM(op string, key, header []byte) (cipher.AEAD, error) {
+	// Check the key size
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, newError(op, ErrInvalidKeySize)
+	}
+
+	// Derive the stream key, with the same length as the given key
+	streamKey, err := hkdf.Key(
+		sha256.New,
+		key,
+		header[streamSaltOffset:streamNoncePrefixOffset],
+		streamKeyInfo,
+		len(key),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(streamKey)
+	return newGCM(streamKey)
+}
+
+// NewGCMStreamWriter creates a new GCMStreamWriter
+//
+// Parameters:
+//
+//   - writer: The writer where the encrypted stream is written
+//   - key: The key to use for encryption (must be 16, 24 or 32 bytes long)
+//   - chunkSize: The size in bytes of the plain text of each chunk. If zero, DefaultStreamChunkSize is used
+//
+// Returns:
+//
+//   - A pointer to the GCMStreamWriter
+//   - An error if any occurred during the creation
+func NewGCMStreamWriter(
+	writer io.Writer,
+	key []byte,
+	chunkSize int,
+) (*GCMStreamWriter, error) {
+	// Check the chunk size
+	if chunkSize == 0 {
+		chunkSize = DefaultStreamChunkSize
+	}
+	if chunkSize < 0 || chunkSize > MaxStreamChunkSize {
+		return nil, newError("NewGCMStreamWriter", ErrInvalidStreamChunkSize)
+	}
+
+	// Create the header with a random salt and nonce prefix
+	header := make([]byte, StreamHeaderSize)
+	if err := newStreamHeader(header, chunkSize); err != nil {
+		return nil, err
+	}
+
+	// Create the GCM block cipher with the stream key
+	gcm, err := newStreamGCM("NewGCMStreamWriter", key, header)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the nonce buffer with the nonce prefix
+	nonce := make([]byte, gcm.NonceSize())
+	copy(nonce, header[streamNoncePrefixOffset:])
+
+	return &GCMStreamWriter{
+		writer:    writer,
+		gcm:       gcm,
+		header:    header,
+		nonce:     nonce,
+		buffer:    make([]byte, 0, chunkSize),
+		sealed:    make([]byte, 0, chunkSize+gcm.Overhead()),
+		chunkSize: chunkSize,
+	}, nil
+}
+
+// writeHeader writes the stream header if it has not been written yet
+//
+// Returns:
+//
+//   - An error if any occurred while writing the header
+func (g *GCMStreamWriter) writeHeader() error {
+	if g.headerWritten {
+		return nil
+	}
+	if _, err := g.writer.Write(g.header); err != nil {
+		return err
+	}
+	g.headerWritten = true
+	return nil
+}
+
+// flush encrypts the buffered plain text as a chunk and writes it
+//
+// Parameters:
+//
+//   - final: Whether the chunk is the final chunk of the stream
+//
+// Returns:
+//
+//   - An error if any occurred while encrypting or writing the chunk
+func (g *GCMStreamWriter) flush(final bool) error {
+	// Check if the chunk counter would overflow
+	if !final && g.counter == math.MaxUint32 {
+		return newError("GCMStreamWriter.Write", ErrStreamTooLong)
+	}
+
+	// Write the header before the first chunk
+	if err := g.writeHeader(); err != nil {
+		return err
+	}
+
+	// Encrypt the chunk, binding the header as additional data
+	setStreamNonce(g.nonce, g.counter, final)
+	g.sealed = g.gcm.Seal(g.sealed[:0], g.nonce, g.buffer, g.header)
+
+	// Write the chunk
+	if _, err := g.writer.Write(g.sealed); err != nil {
+		return err
+	}
+
+	// Reset the buffer and increment the counter
+	g.buffer = g.buffer[:0]
+	g.counter++
+	return nil
+}
+
+// Write encrypts the given plain text and writes it to the underlying writer. Data is buffered until a full chunk is
+// available, so Close must be called to write the final chunk
+//
+// Parameters:
+//
+//   - p: The plain text to encrypt
+//
+// Returns:
+//
+//   - The number of bytes consumed from p
+//   - An error if any occurred during the encryption or writing process
+func (g *GCMStreamWriter) Write(p []byte) (int, error) {
+	if g.closed {
+		return 0, newError("GCMStreamWriter.Write", ErrStreamClosed)
+	}
+	if g.err != nil {
+		return 0, g.err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// Flush the buffer only once more data arrives, so the final chunk is always written by Close
+		if len(g.buffer) == g.chunkSize {
+			if err := g.flush(false); err != nil {
+				g.err = err
+				return written, err
+			}
+		}
+
+		// Copy as much data as possible into the buffer
+		n := min(g.chunkSize-len(g.buffer), len(p))
+		g.buffer = append(g.buffer, p[:n]...)
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close writes the final chunk of the stream. It does not close the underlying writer
+//
+// Returns:
+//
+//   - An error if any occurred while writing the final chunk
+func (g *GCMStreamWriter) Close() error {
+	if g.closed {
+		return newError("GCMStreamWriter.Close", ErrStreamClosed)
+	}
+	g.closed = true
+	if g.err != nil {
+		return g.err
+	}
+	return g.flush(true)
+}
+
+// NewGCMStreamReader creates a new GCMStreamReader, reading the stream header from the given reader
+//
+// Parameters:
+//
+//   - reader: The reader from which the encrypted stream is read
+//   - key: The key to use for decryption (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the GCMStreamReader
+//   - An error if any occurred during the creation
+func NewGCMStreamReader(reader io.Reader, key []byte) (
+	*GCMStreamReader,
+	error,
+) {
+	// Read the header
+	bufferedReader := bufio.NewReader(reader)
+	header := make([]byte, StreamHeaderSize)
+	if _, err := io.ReadFull(bufferedReader, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, newError("NewGCMStreamReader", ErrInvalidStreamHeader)
+		}
+		return nil, err
+	}
+
+	// Check the version and the chunk size
+	if header[0] != StreamVersion {
+		return nil, newError("NewGCMStreamReader", ErrUnsupportedStreamVersion)
+	}
+	chunkSize := binary.BigEndian.Uint32(header[1:streamSaltOffset])
+	if chunkSize == 0 || chunkSize > MaxStreamChunkSize {
+		return nil, newError("NewGCMStreamReader", ErrInvalidStreamChunkSize)
+	}
+
+	// Create the GCM block cipher with the stream key
+	gcm, err := newStreamGCM("NewGCMStreamReader", key, header)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the nonce buffer with the nonce prefix
+	nonce := make([]byte, gcm.NonceSize())
+	copy(nonce, header[streamNoncePrefixOffset:])
+
+	return &GCMStreamReader{
+		reader:    bufferedReader,
+		gcm:       gcm,
+		header:    header,
+		nonce:     nonce,
+		chunk:     make([]byte, int(chunkSize)+gcm.Overhead()),
+		chunkSize: int(chunkSize),
+	}, nil
+}
+
+// readChunk reads, verifies and decrypts the next chunk of the stream
+//
+// Returns:
+//
+//   - An error if any occurred while reading or decrypting the chunk
+func (g *GCMStreamReader) readChunk() error {
+	// Read a full sealed chunk
+	n, err := io.ReadFull(g.reader, g.chunk)
+	final := false
+	switch {
+	case errors.Is(err, io.EOF):
+		// The stream ended without its final chunk
+		return newError("GCMStreamReader.Read", ErrStreamTruncated)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	case err != nil:
+		return err
+	default:
+		// A full chunk is the final one only if nothing follows it
+		if _, err = g.reader.Peek(1); errors.Is(err, io.EOF) {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	// Check if the chunk counter would overflow
+	if !final && g.counter == math.MaxUint32 {
+		return newError("GCMStreamReader.Read", ErrStreamTooLong)
+	}
+
+	// Decrypt the chunk, verifying the header as additional data
+	setStreamNonce(g.nonce, g.counter, final)
+	plainText, err := g.gcm.Open(g.chunk[:0], g.nonce, g.chunk[:n], g.header)
+	if err != nil {
+		return newError("GCMStreamReader.Read", ErrStreamAuthenticationFailed)
+	}
+
+	g.plainText = plainText
+	g.counter++
+	g.done = final
+	return nil
+}
+
+// Read reads and decrypts data from the underlying reader. Only verified plain text is returned
+//
+// Parameters:
+//
+//   - p: The buffer where the plain text is written
+//
+// Returns:
+//
+//   - The number of bytes written to p
+//   - An error if any occurred during the reading or decryption process, or io.EOF at the end of the stream
+func (g *GCMStreamReader) Read(p []byte) (int, error) {
+	if g.err != nil {
+		return 0, g.err
+	}
+
+	for len(g.plainText) == 0 {
+		// Check if the final chunk was already read
+		if g.done {
+			g.err = io.EOF
+			return 0, io.EOF
+		}
+
+		// Read the next chunk
+		if err := g.readChunk(); err != nil {
+			g.err = err
+			return 0, err
+		}
+	}
+
+	// Copy the decrypted plain text
+	n := copy(p, g.plainText)
+	g.plainText = g.plainText[n:]
+	return n, nil
+}
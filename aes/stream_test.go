@@ -0,0 +1,291 @@
+package aes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+const (
+	// streamTestChunkSize is the chunk size in bytes used by the stream tests
+	streamTestChunkSize = 64
+
+	// streamTestSealedSize is the size in bytes of each sealed chunk, including its 16-byte GCM tag
+	streamTestSealedSize = streamTestChunkSize + 16
+)
+
+// encryptStream encrypts the plain text with a GCMStreamWriter, writing it in pieces of the given size
+func encryptStream(t *testing.T, key, plainText []byte, writeSize int) []byte {
+	t.Helper()
+
+	var cipherText bytes.Buffer
+	writer, err := NewGCMStreamWriter(&cipherText, key, streamTestChunkSize)
+	if err != nil {
+		t.Fatalf("NewGCMStreamWriter: %v", err)
+	}
+	for remaining := plainText; len(remaining) > 0; {
+		n := min(writeSize, len(remaining))
+		written, err := writer.Write(remaining[:n])
+		if err != nil {
+			t.Fatalf("GCMStreamWriter.Write: %v", err)
+		}
+		if written != n {
+			t.Fatalf("GCMStreamWriter.Write = %d, want %d", written, n)
+		}
+		remaining = remaining[n:]
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatalf("GCMStreamWriter.Close: %v", err)
+	}
+	return cipherText.Bytes()
+}
+
+// decryptStream decrypts the cipher text with a GCMStreamReader
+func decryptStream(cipherText, key []byte) ([]byte, error) {
+	reader, err := NewGCMStreamReader(bytes.NewReader(cipherText), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+func TestGCMStreamRoundTrip(t *testing.T) {
+	key := randomBytes(t, 32)
+
+	for _, size := range []int{
+		1,
+		streamTestChunkSize - 1,
+		streamTestChunkSize,
+		streamTestChunkSize + 1,
+		7*streamTestChunkSize + 3,
+	} {
+		// Write sizes that are not aligned to the chunk boundaries
+		for _, writeSize := range []int{1, 7, streamTestChunkSize + 5, size} {
+			t.Run(
+				fmt.Sprintf("%d/%d", size, writeSize), func(t *testing.T) {
+					plainText := randomBytes(t, size)
+					cipherText := encryptStream(t, key, plainText, writeSize)
+
+					got, err := decryptStream(cipherText, key)
+					if err != nil {
+						t.Fatalf("GCMStreamReader.Read: %v", err)
+					}
+					if !bytes.Equal(got, plainText) {
+						t.Fatalf("GCMStreamReader returned a different plain text")
+					}
+				},
+			)
+		}
+	}
+}
+
+func TestGCMStreamEmpty(t *testing.T) {
+	key := randomBytes(t, 32)
+
+	// An empty stream is the header followed by an empty final chunk
+	cipherText := encryptStream(t, key, nil, 1)
+	if len(cipherText) != StreamHeaderSize+16 {
+		t.Fatalf("empty stream length = %d, want %d", len(cipherText), StreamHeaderSize+16)
+	}
+	got, err := decryptStream(cipherText, key)
+	if err != nil {
+		t.Fatalf("GCMStreamReader.Read: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("GCMStreamReader returned %d bytes, want 0", len(got))
+	}
+}
+
+func TestGCMStreamUniqueHeaders(t *testing.T) {
+	key := randomBytes(t, 32)
+	plainText := randomBytes(t, streamTestChunkSize)
+
+	// Streams encrypted with the same key use different salts, so their chunks differ
+	first := encryptStream(t, key, plainText, streamTestChunkSize)
+	second := encryptStream(t, key, plainText, streamTestChunkSize)
+	if bytes.Equal(
+		first[streamSaltOffset:streamNoncePrefixOffset],
+		second[streamSaltOffset:streamNoncePrefixOffset],
+	) {
+		t.Fatalf("two streams share the same salt")
+	}
+	if bytes.Equal(first[StreamHeaderSize:], second[StreamHeaderSize:]) {
+		t.Fatalf("two streams share the same cipher text")
+	}
+}
+
+func TestGCMStreamTruncated(t *testing.T) {
+	key := randomBytes(t, 32)
+	cipherText := encryptStream(
+		t,
+		key,
+		randomBytes(t, 3*streamTestChunkSize),
+		streamTestChunkSize,
+	)
+
+	// A stream with only its header has lost its final chunk
+	if _, err := decryptStream(
+		cipherText[:StreamHeaderSize],
+		key,
+	); !errors.Is(err, ErrStreamTruncated) {
+		t.Fatalf("GCMStreamReader with only the header: got %v, want %v", err, ErrStreamTruncated)
+	}
+
+	// A stream cut at a chunk boundary ends with a chunk that is not flagged as final
+	for chunks := 1; chunks < 3; chunks++ {
+		plainText, err := decryptStream(
+			cipherText[:StreamHeaderSize+chunks*streamTestSealedSize],
+			key,
+		)
+		if !errors.Is(err, ErrStreamAuthenticationFailed) {
+			t.Fatalf(
+				"GCMStreamReader truncated to %d chunks: got %v, want %v",
+				chunks,
+				err,
+				ErrStreamAuthenticationFailed,
+			)
+		}
+
+		// Only the verified chunks before the truncation are returned
+		if len(plainText) != (chunks-1)*streamTestChunkSize {
+			t.Fatalf(
+				"GCMStreamReader truncated to %d chunks returned %d bytes, want %d",
+				chunks,
+				len(plainText),
+				(chunks-1)*streamTestChunkSize,
+			)
+		}
+	}
+
+	// A stream with a truncated header
+	if _, err := decryptStream(
+		cipherText[:StreamHeaderSize-1],
+		key,
+	); !errors.Is(err, ErrInvalidStreamHeader) {
+		t.Fatalf("GCMStreamReader with a truncated header: got %v, want %v", err, ErrInvalidStreamHeader)
+	}
+}
+
+func TestGCMStreamReordered(t *testing.T) {
+	key := randomBytes(t, 32)
+	cipherText := encryptStream(
+		t,
+		key,
+		randomBytes(t, 3*streamTestChunkSize),
+		streamTestChunkSize,
+	)
+
+	// Swap the first two chunks
+	reordered := bytes.Clone(cipherText)
+	first := reordered[StreamHeaderSize : StreamHeaderSize+streamTestSealedSize]
+	second := reordered[StreamHeaderSize+streamTestSealedSize : StreamHeaderSize+2*streamTestSealedSize]
+	swapped := bytes.Clone(first)
+	copy(first, second)
+	copy(second, swapped)
+
+	if _, err := decryptStream(
+		reordered,
+		key,
+	); !errors.Is(err, ErrStreamAuthenticationFailed) {
+		t.Fatalf("GCMStreamReader with reordered chunks: got %v, want %v", err, ErrStreamAuthenticationFailed)
+	}
+}
+
+func TestGCMStreamTamperedHeader(t *testing.T) {
+	key := randomBytes(t, 32)
+	cipherText := encryptStream(
+		t,
+		key,
+		randomBytes(t, 2*streamTestChunkSize),
+		streamTestChunkSize,
+	)
+
+	tests := []struct {
+		name   string
+		offset int
+		err    error
+	}{
+		{name: "version", offset: 0, err: ErrUnsupportedStreamVersion},
+		{name: "chunk size", offset: streamSaltOffset - 1, err: ErrStreamAuthenticationFailed},
+		{name: "salt", offset: streamSaltOffset, err: ErrStreamAuthenticationFailed},
+		{name: "nonce prefix", offset: StreamHeaderSize - 1, err: ErrStreamAuthenticationFailed},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				tampered := bytes.Clone(cipherText)
+				tampered[test.offset] ^= 1
+
+				plainText, err := decryptStream(tampered, key)
+				if !errors.Is(err, test.err) {
+					t.Fatalf("GCMStreamReader: got %v, want %v", err, test.err)
+				}
+				if len(plainText) != 0 {
+					t.Fatalf("GCMStreamReader returned %d bytes of a tampered stream", len(plainText))
+				}
+			},
+		)
+	}
+}
+
+func TestGCMStreamWrongKey(t *testing.T) {
+	cipherText := encryptStream(
+		t,
+		randomBytes(t, 32),
+		randomBytes(t, streamTestChunkSize),
+		streamTestChunkSize,
+	)
+
+	_, err := decryptStream(cipherText, randomBytes(t, 32))
+	if !errors.Is(err, ErrStreamAuthenticationFailed) {
+		t.Fatalf("GCMStreamReader with the wrong key: got %v, want %v", err, ErrStreamAuthenticationFailed)
+	}
+
+	// Stream failures are authentication failures of any other algorithm
+	if !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("GCMStreamReader with the wrong key: got %v, want %v", err, ErrAuthenticationFailed)
+	}
+}
+
+func TestGCMStreamWriterClosed(t *testing.T) {
+	writer, err := NewGCMStreamWriter(io.Discard, randomBytes(t, 32), 0)
+	if err != nil {
+		t.Fatalf("NewGCMStreamWriter: %v", err)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatalf("GCMStreamWriter.Close: %v", err)
+	}
+
+	if _, err = writer.Write([]byte("data")); !errors.Is(err, ErrStreamClosed) {
+		t.Fatalf("GCMStreamWriter.Write after Close: got %v, want %v", err, ErrStreamClosed)
+	}
+	if err = writer.Close(); !errors.Is(err, ErrStreamClosed) {
+		t.Fatalf("GCMStreamWriter.Close after Close: got %v, want %v", err, ErrStreamClosed)
+	}
+}
+
+func TestNewGCMStreamWriterInvalid(t *testing.T) {
+	if _, err := NewGCMStreamWriter(
+		io.Discard,
+		randomBytes(t, 15),
+		0,
+	); !errors.Is(err, ErrInvalidKeySize) {
+		t.Fatalf("NewGCMStreamWriter with a 15-byte key: got %v, want %v", err, ErrInvalidKeySize)
+	}
+	for _, chunkSize := range []int{-1, MaxStreamChunkSize + 1} {
+		if _, err := NewGCMStreamWriter(
+			io.Discard,
+			randomBytes(t, 32),
+			chunkSize,
+		); !errors.Is(err, ErrInvalidStreamChunkSize) {
+			t.Fatalf(
+				"NewGCMStreamWriter with chunk size %d: got %v, want %v",
+				chunkSize,
+				err,
+				ErrInvalidStreamChunkSize,
+			)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package aes
+
+import (
+	"sync"
+)
+
+// NIST SP 800-38D limits the number of invocations of AES-GCM with random 96-bit nonces to 2^32 per key, which keeps
+// the probability of a nonce collision below 2^-32. A UsageTracker counts the encryptions and bytes of each key ID in
+// a UsageStore, calls a warning function at a soft threshold and refuses to encrypt at the hard limit.
+
+const (
+	// GCMRandomNonceLimit is the maximum number of messages that can be encrypted with random nonces under one key
+	GCMRandomNonceLimit = 1 << 32
+)
+
+// Nonce modes of a tracked GCMEncryptor
+const (
+	NonceModeRandom NonceMode = iota + 1
+	NonceModeCounter
+)
+
+var (
+	// DefaultUsageLimits are the default usage limits for random nonces
+	DefaultUsageLimits = UsageLimits{
+		SoftMessages: GCMRandomNonceLimit / 2,
+		HardMessages: GCMRandomNonceLimit,
+	}
+)
+
+type (
+	// NonceMode is the way a tracked GCMEncryptor generates its nonces
+	//
+	//   - NonceModeRandom: random 96-bit nonces
+	//   - NonceModeCounter: deterministic nonces made of 4 zero bytes followed by the 64-bit big endian message count
+	//     of the key, which are unique as long as a single writer uses the key and its usage store is durable
+	NonceMode uint8
+
+	// Usage is the usage of a key
+	Usage struct {
+		Messages uint64
+		Bytes    uint64
+	}
+
+	// UsageLimits are the message limits of a key. A zero limit is disabled
+	UsageLimits struct {
+		SoftMessages uint64
+		HardMessages uint64
+	}
+
+	// UsageStore stores the usage of each key ID. Implementations backed by a database allow the usage to survive
+	// restarts and to be shared between processes, and must be safe for concurrent use
+	UsageStore interface {
+		// Add atomically adds the messages and bytes to the usage of the key ID and returns the updated usage
+		Add(keyID string, messages, bytes uint64) (Usage, error)
+
+		// Get returns the usage of the key ID
+		Get(keyID string) (Usage, error)
+	}
+
+	// MemoryUsageStore is an in-memory UsageStore, whose usage is lost when the process exits. It is safe for
+	// concurrent use
+	MemoryUsageStore struct {
+		mutex  sync.Mutex
+		usages map[string]Usage
+	}
+
+	// UsageTracker enforces usage limits on the keys of a UsageStore. It is safe for concurrent use
+	UsageTracker struct {
+		store       UsageStore
+		limits      UsageLimits
+		onSoftLimit func(keyID string, usage Usage)
+	}
+)
+
+// String returns the name of the nonce mode
+//
+// Returns:
+//
+//   - The name of the nonce mode
+func (n NonceMode) String() string {
+	switch n {
+	case NonceModeRandom:
+		return "random"
+	case NonceModeCounter:
+		return "counter"
+	default:
+		return "unknown"
+	}
+}
+
+// NewMemoryUsageStore creates a new MemoryUsageStore
+//
+// Returns:
+//
+//   - A pointer to the MemoryUsageStore
+func NewMemoryUsageStore() *MemoryUsageStore {
+	return &MemoryUsageStore{usages: make(map[string]Usage)}
+}
+
+// Add atomically adds the messages and bytes to the usage of the key ID
+//
+// Parameters:
+//
+//   - keyID: The key ID
+//   - messages: The number of messages to add
+//   - bytes: The number of bytes to add
+//
+// Returns:
+//
+//   - The updated usage
+//   - An error, which is always nil
+func (m *MemoryUsageStore) Add(keyID string, messages, bytes uint64) (
+	Usage,
+	error,
+) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	usage := m.usages[keyID]
+	usage.Messages += messages
+	usage.Bytes += bytes
+	m.usages[keyID] = usage
+	return usage, nil
+}
+
+// Get returns the usage of the key ID
+//
+// Parameters:
+//
+//   - keyID: The key ID
+//
+// Returns:
+//
+//   - The usage, which is zero for unknown key IDs
+//   - An error, which is always nil
+func (m *MemoryUsageStore) Get(keyID string) (Usage, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.usages[keyID], nil
+}
+
+// NewUsageTracker creates a new UsageTracker
+//
+// Parameters:
+//
+//   - store: The usage store
+//   - limits: The usage limits, e.g. DefaultUsageLimits
+//   - onSoftLimit: The function called once when a key reaches the soft limit (may be nil)
+//
+// Returns:
+//
+//   - A pointer to the UsageTracker
+//   - An error if the store is nil or the soft limit is greater than the hard limit
+func NewUsageTracker(
+	store UsageStore,
+	limits UsageLimits,
+	onSoftLimit func(keyID string, usage Usage),
+) (*UsageTracker, error) {
+	if store == nil {
+		return nil, newError("NewUsageTracker", ErrNilUsageStore)
+	}
+	if limits.HardMessages != 0 && limits.SoftMessages > limits.HardMessages {
+		return nil, newError("NewUsageTracker", ErrInvalidUsageLimits)
+	}
+	return &UsageTracker{
+		store:       store,
+		limits:      limits,
+		onSoftLimit: onSoftLimit,
+	}, nil
+}
+
+// Track records one encryption of the given number of bytes with the key ID and checks the usage limits. A refused
+// encryption is still counted, so the key stays refused
+//
+// Parameters:
+//
+//   - keyID: The key ID
+//   - bytes: The number of plain text bytes
+//
+// Returns:
+//
+//   - The updated usage
+//   - ErrKeyUsageLimitExceeded if the key reached the hard limit, or any error returned by the store
+func (u *UsageTracker) Track(keyID string, bytes int) (Usage, error) {
+	usage, err := u.store.Add(keyID, 1, uint64(bytes))
+	if err != nil {
+		return Usage{}, err
+	}
+
+	// Refuse the encryption past the hard limit
+	if u.limits.HardMessages != 0 && usage.Messages > u.limits.HardMessages {
+		return usage, newError("UsageTracker.Track", ErrKeyUsageLimitExceeded)
+	}
+
+	// Warn once when the soft limit is reached
+	if u.onSoftLimit != nil && u.limits.SoftMessages != 0 && usage.Messages == u.limits.SoftMessages {
+		u.onSoftLimit(keyID, usage)
+	}
+	return usage, nil
+}
+
+// Usage returns the usage of the key ID
+//
+// Parameters:
+//
+//   - keyID: The key ID
+//
+// Returns:
+//
+//   - The usage
+//   - An error returned by the store
+func (u *UsageTracker) Usage(keyID string) (Usage, error) {
+	return u.store.Get(keyID)
+}
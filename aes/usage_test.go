@@ -0,0 +1,192 @@
+package aes
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type (
+	// failingUsageStore is a UsageStore whose Add always fails
+	failingUsageStore struct {
+		MemoryUsageStore
+	}
+)
+
+// errTestUsageStore is the error returned by failingUsageStore
+var errTestUsageStore = errors.New("test usage store failure")
+
+// Add always fails
+func (f *failingUsageStore) Add(string, uint64, uint64) (Usage, error) {
+	return Usage{}, errTestUsageStore
+}
+
+func TestUsageTrackerSoftLimit(t *testing.T) {
+	const (
+		softMessages = 50
+		goroutines   = 8
+		perGoroutine = 25
+	)
+
+	var calls atomic.Int32
+	var softUsage Usage
+	tracker, err := NewUsageTracker(
+		NewMemoryUsageStore(),
+		UsageLimits{SoftMessages: softMessages},
+		func(keyID string, usage Usage) {
+			calls.Add(1)
+			softUsage = usage
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewUsageTracker: %v", err)
+	}
+
+	// The callback fires exactly once, even with concurrent encryptions crossing the soft limit
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perGoroutine {
+				if _, err := tracker.Track("key-1", 10); err != nil {
+					t.Errorf("UsageTracker.Track: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("soft limit callback called %d times, want 1", got)
+	}
+	if softUsage.Messages != softMessages {
+		t.Fatalf("soft limit callback usage = %+v, want %d messages", softUsage, softMessages)
+	}
+	usage, err := tracker.Usage("key-1")
+	if err != nil {
+		t.Fatalf("UsageTracker.Usage: %v", err)
+	}
+	if usage.Messages != goroutines*perGoroutine || usage.Bytes != 10*goroutines*perGoroutine {
+		t.Fatalf("UsageTracker.Usage = %+v, want %d messages", usage, goroutines*perGoroutine)
+	}
+}
+
+func TestUsageTrackerHardLimit(t *testing.T) {
+	tracker, err := NewUsageTracker(
+		NewMemoryUsageStore(),
+		UsageLimits{SoftMessages: 2, HardMessages: 3},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewUsageTracker: %v", err)
+	}
+	encryptor, err := NewTrackedGCMEncryptor(randomBytes(t, 32), "key-1", tracker, NonceModeRandom)
+	if err != nil {
+		t.Fatalf("NewTrackedGCMEncryptor: %v", err)
+	}
+
+	for range 3 {
+		if _, err = encryptor.EncryptBytes(nil, []byte("data"), nil); err != nil {
+			t.Fatalf("GCMEncryptor.EncryptBytes: %v", err)
+		}
+	}
+
+	// The key stays refused once it reached the hard limit
+	for range 2 {
+		if _, err = encryptor.EncryptBytes(nil, []byte("data"), nil); !errors.Is(
+			err,
+			ErrKeyUsageLimitExceeded,
+		) {
+			t.Fatalf("GCMEncryptor.EncryptBytes past the hard limit: got %v, want %v", err, ErrKeyUsageLimitExceeded)
+		}
+	}
+
+	// Other keys of the tracker are not affected
+	if _, err = tracker.Track("key-2", 4); err != nil {
+		t.Fatalf("UsageTracker.Track of another key: %v", err)
+	}
+}
+
+func TestTrackedGCMEncryptorCounterNonces(t *testing.T) {
+	const messages = 1000
+
+	tracker, err := NewUsageTracker(NewMemoryUsageStore(), DefaultUsageLimits, nil)
+	if err != nil {
+		t.Fatalf("NewUsageTracker: %v", err)
+	}
+	encryptor, err := NewTrackedGCMEncryptor(randomBytes(t, 32), "key-1", tracker, NonceModeCounter)
+	if err != nil {
+		t.Fatalf("NewTrackedGCMEncryptor: %v", err)
+	}
+
+	nonces := make(map[[gcmNonceSize]byte]struct{}, messages)
+	for i := range messages {
+		cipherText, err := encryptor.EncryptBytes(nil, []byte("data"), nil)
+		if err != nil {
+			t.Fatalf("GCMEncryptor.EncryptBytes: %v", err)
+		}
+
+		// The nonce is the message count of the key
+		nonce := [gcmNonceSize]byte(cipherText[:gcmNonceSize])
+		if count := binary.BigEndian.Uint64(nonce[gcmNonceSize-8:]); count != uint64(i+1) {
+			t.Fatalf("nonce counter = %d, want %d", count, i+1)
+		}
+		if _, ok := nonces[nonce]; ok {
+			t.Fatalf("nonce %x was repeated", nonce)
+		}
+		nonces[nonce] = struct{}{}
+
+		plainText, err := encryptor.DecryptBytes(nil, cipherText, nil)
+		if err != nil || string(plainText) != "data" {
+			t.Fatalf("GCMEncryptor.DecryptBytes = %q, %v", plainText, err)
+		}
+	}
+}
+
+func TestUsageTrackerInvalid(t *testing.T) {
+	if _, err := NewUsageTracker(nil, DefaultUsageLimits, nil); !errors.Is(
+		err,
+		ErrNilUsageStore,
+	) {
+		t.Fatalf("NewUsageTracker with a nil store: got %v, want %v", err, ErrNilUsageStore)
+	}
+	if _, err := NewUsageTracker(
+		NewMemoryUsageStore(),
+		UsageLimits{SoftMessages: 2, HardMessages: 1},
+		nil,
+	); !errors.Is(err, ErrInvalidUsageLimits) {
+		t.Fatalf("NewUsageTracker with a soft limit above the hard limit: got %v, want %v", err, ErrInvalidUsageLimits)
+	}
+
+	tracker, err := NewUsageTracker(&failingUsageStore{}, DefaultUsageLimits, nil)
+	if err != nil {
+		t.Fatalf("NewUsageTracker: %v", err)
+	}
+	if _, err = NewTrackedGCMEncryptor(randomBytes(t, 32), "key-1", nil, NonceModeRandom); !errors.Is(
+		err,
+		ErrNilUsageTracker,
+	) {
+		t.Fatalf("NewTrackedGCMEncryptor with a nil tracker: got %v, want %v", err, ErrNilUsageTracker)
+	}
+	if _, err = NewTrackedGCMEncryptor(randomBytes(t, 32), "key-1", tracker, 0); !errors.Is(
+		err,
+		ErrInvalidNonceMode,
+	) {
+		t.Fatalf("NewTrackedGCMEncryptor with an invalid nonce mode: got %v, want %v", err, ErrInvalidNonceMode)
+	}
+
+	// Encryptions that cannot be recorded are refused
+	encryptor, err := NewTrackedGCMEncryptor(randomBytes(t, 32), "key-1", tracker, NonceModeCounter)
+	if err != nil {
+		t.Fatalf("NewTrackedGCMEncryptor: %v", err)
+	}
+	if _, err = encryptor.EncryptBytes(nil, []byte("data"), nil); !errors.Is(
+		err,
+		errTestUsageStore,
+	) {
+		t.Fatalf("GCMEncryptor.EncryptBytes with a failing store: got %v, want %v", err, errTestUsageStore)
+	}
+}
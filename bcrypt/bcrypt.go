@@ -34,7 +34,7 @@ func HashPassword(password string, cost int) (string, error) {
 		passwordBytes, cost,
 	)
 	if err != nil {
-		return "", gocrypto.ErrFailedToHashPassword
+		return "", newError("HashPassword", gocrypto.ErrFailedToHashPassword)
 	}
 
 	return string(hash), nil
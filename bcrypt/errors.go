@@ -0,0 +1,22 @@
+package bcrypt
+
+import (
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// packageName is the package name reported by the errors of the package
+const packageName = "bcrypt"
+
+// newError wraps an error with the operation that failed and the package name
+//
+// Parameters:
+//
+//   - op: The name of the operation
+//   - err: The error to wrap
+//
+// Returns:
+//
+//   - The wrapped error, or nil if err is nil
+func newError(op string, err error) error {
+	return gocrypto.NewError(packageName, op, err)
+}
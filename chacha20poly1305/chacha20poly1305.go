@@ -0,0 +1,255 @@
+package chacha20poly1305
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// KeySize is the size in bytes of the key
+	KeySize = chacha20poly1305.KeySize
+
+	// NonceSize is the size in bytes of the ChaCha20-Poly1305 nonce
+	NonceSize = chacha20poly1305.NonceSize
+
+	// NonceSizeX is the size in bytes of the XChaCha20-Poly1305 nonce, which is large enough to be generated randomly
+	// for a practically unlimited number of messages
+	NonceSizeX = chacha20poly1305.NonceSizeX
+
+	// Overhead is the size in bytes of the Poly1305 tag
+	Overhead = chacha20poly1305.Overhead
+)
+
+// seal encrypts the plain text with a random nonce
+//
+// Parameters:
+//
+//   - aead: The ChaCha20-Poly1305 or XChaCha20-Poly1305 cipher
+//   - plainText: The plain text to encrypt
+//   - additionalData: The additional authenticated data
+//
+// Returns:
+//
+//   - A pointer to the nonce followed by the cipher text and the tag, in hexadecimal format
+//   - An error if any occurred during the encryption process
+func seal(aead cipher.AEAD, plainText, additionalData []byte) (*string, error) {
+	// Create a new random nonce
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	// Encrypt the plain text
+	cipherText := aead.Seal(nonce, nonce, plainText, additionalData)
+
+	// Return the encrypted cipher text as a hexadecimal string
+	enc := hex.EncodeToString(cipherText)
+
+	return &enc, nil
+}
+
+// open decrypts a string produced by seal
+//
+// Parameters:
+//
+//   - aead: The ChaCha20-Poly1305 or XChaCha20-Poly1305 cipher
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - additionalData: The additional authenticated data used for encryption
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - An error if any occurred during the decryption process
+func open(aead cipher.AEAD, encryptedText *string, additionalData []byte) (
+	*string,
+	error,
+) {
+	// Check if the encrypted text is nil
+	if encryptedText == nil {
+		return nil, newError("Decrypt", ErrNilEncryptedText)
+	}
+
+	// Decode the encrypted text from a hexadecimal string
+	cipherText, err := hex.DecodeString(*encryptedText)
+	if err != nil {
+		return nil, newError("Decrypt", ErrInvalidEncoding)
+	}
+
+	// Split the nonce from the cipher text
+	nonceSize := aead.NonceSize()
+	if len(cipherText) < nonceSize+aead.Overhead() {
+		return nil, newError("Decrypt", ErrCiphertextTooShort)
+	}
+	nonce, cipherText := cipherText[:nonceSize], cipherText[nonceSize:]
+
+	// Decrypt the cipher text
+	plainText, err := aead.Open(nil, nonce, cipherText, additionalData)
+	if err != nil {
+		return nil, newError("Decrypt", ErrAuthenticationFailed)
+	}
+
+	// Return the decrypted plain text
+	dec := string(plainText)
+
+	return &dec, nil
+}
+
+// Encrypt encrypts a string using ChaCha20-Poly1305 (RFC 8439) with a random 96-bit nonce
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func Encrypt(plainText, key []byte) (*string, error) {
+	return EncryptWithAAD(plainText, key, nil)
+}
+
+// EncryptWithAAD encrypts a string using ChaCha20-Poly1305 (RFC 8439) with a random 96-bit nonce, binding it to the
+// given additional authenticated data
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 32 bytes long)
+//   - additionalData: The additional authenticated data
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptWithAAD(plainText, key, additionalData []byte) (*string, error) {
+	// Create a new ChaCha20-Poly1305 cipher with the key
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, newError("EncryptWithAAD", ErrInvalidKeySize)
+	}
+	return seal(aead, plainText, additionalData)
+}
+
+// Decrypt decrypts a string using ChaCha20-Poly1305 (RFC 8439)
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - key: The key to use for decryption (must be 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - An error if any occurred during the decryption process
+func Decrypt(encryptedText *string, key []byte) (*string, error) {
+	return DecryptWithAAD(encryptedText, key, nil)
+}
+
+// DecryptWithAAD decrypts a string using ChaCha20-Poly1305 (RFC 8439), verifying the given additional authenticated
+// data
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - key: The key to use for decryption (must be 32 bytes long)
+//   - additionalData: The additional authenticated data used for encryption
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - ErrAuthenticationFailed if the encrypted text was tampered with or the additional data does not match, or any
+//     other error that occurred during the decryption process
+func DecryptWithAAD(encryptedText *string, key, additionalData []byte) (
+	*string,
+	error,
+) {
+	// Create a new ChaCha20-Poly1305 cipher with the key
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, newError("DecryptWithAAD", ErrInvalidKeySize)
+	}
+	return open(aead, encryptedText, additionalData)
+}
+
+// EncryptX encrypts a string using XChaCha20-Poly1305 with a random 192-bit nonce, which is safe for a very high
+// number of messages under the same key
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptX(plainText, key []byte) (*string, error) {
+	return EncryptXWithAAD(plainText, key, nil)
+}
+
+// EncryptXWithAAD encrypts a string using XChaCha20-Poly1305 with a random 192-bit nonce, binding it to the given
+// additional authenticated data
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key to use for encryption (must be 32 bytes long)
+//   - additionalData: The additional authenticated data
+//
+// Returns:
+//
+//   - A pointer to the encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptXWithAAD(plainText, key, additionalData []byte) (*string, error) {
+	// Create a new XChaCha20-Poly1305 cipher with the key
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, newError("EncryptXWithAAD", ErrInvalidKeySize)
+	}
+	return seal(aead, plainText, additionalData)
+}
+
+// DecryptX decrypts a string using XChaCha20-Poly1305
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - key: The key to use for decryption (must be 32 bytes long)
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - An error if any occurred during the decryption process
+func DecryptX(encryptedText *string, key []byte) (*string, error) {
+	return DecryptXWithAAD(encryptedText, key, nil)
+}
+
+// DecryptXWithAAD decrypts a string using XChaCha20-Poly1305, verifying the given additional authenticated data
+//
+// Parameters:
+//
+//   - encryptedText: A pointer to the encrypted string in hexadecimal format
+//   - key: The key to use for decryption (must be 32 bytes long)
+//   - additionalData: The additional authenticated data used for encryption
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - ErrAuthenticationFailed if the encrypted text was tampered with or the additional data does not match, or any
+//     other error that occurred during the decryption process
+func DecryptXWithAAD(encryptedText *string, key, additionalData []byte) (
+	*string,
+	error,
+) {
+	// Create a new XChaCha20-Poly1305 cipher with the key
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, newError("DecryptXWithAAD", ErrInvalidKeySize)
+	}
+	return open(aead, encryptedText, additionalData)
+}
@@ -0,0 +1,133 @@
+package chacha20poly1305
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Names under which the ciphers are registered as gocrypto ciphers
+const (
+	CipherName  = "chacha20-poly1305"
+	CipherNameX = "xchacha20-poly1305"
+)
+
+type (
+	// aeadCipher is the gocrypto.Cipher of ChaCha20-Poly1305 and XChaCha20-Poly1305
+	aeadCipher struct {
+		aead cipher.AEAD
+		name string
+	}
+)
+
+func init() {
+	gocrypto.RegisterCipher(CipherName, NewCipher)
+	gocrypto.RegisterCipher(CipherNameX, NewXCipher)
+}
+
+// NewCipher creates the gocrypto.Cipher of ChaCha20-Poly1305, with random 96-bit nonces
+//
+// Parameters:
+//
+//   - key: The key to use for encryption and decryption (must be 32 bytes long)
+//
+// Returns:
+//
+//   - The cipher
+//   - An error if the key is invalid
+func NewCipher(key []byte) (gocrypto.Cipher, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, newError("NewCipher", ErrInvalidKeySize)
+	}
+	return &aeadCipher{aead: aead, name: CipherName}, nil
+}
+
+// NewXCipher creates the gocrypto.Cipher of XChaCha20-Poly1305, with random 192-bit nonces
+//
+// Parameters:
+//
+//   - key: The key to use for encryption and decryption (must be 32 bytes long)
+//
+// Returns:
+//
+//   - The cipher
+//   - An error if the key is invalid
+func NewXCipher(key []byte) (gocrypto.Cipher, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, newError("NewXCipher", ErrInvalidKeySize)
+	}
+	return &aeadCipher{aead: aead, name: CipherNameX}, nil
+}
+
+// Encrypt encrypts the plain text with a random nonce
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - additionalData: The additional authenticated data (may be nil)
+//
+// Returns:
+//
+//   - The nonce followed by the cipher text and the tag
+//   - An error if any occurred during the encryption process
+func (a *aeadCipher) Encrypt(plainText, additionalData []byte) ([]byte, error) {
+	// Create a new random nonce
+	nonceSize := a.aead.NonceSize()
+	nonce := make([]byte, nonceSize, nonceSize+len(plainText)+a.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	// Encrypt the plain text
+	return a.aead.Seal(nonce, nonce, plainText, additionalData), nil
+}
+
+// Decrypt decrypts a cipher text produced by Encrypt
+//
+// Parameters:
+//
+//   - cipherText: The nonce followed by the cipher text and the tag
+//   - additionalData: The additional authenticated data used for encryption (may be nil)
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - An error if any occurred during the decryption process
+func (a *aeadCipher) Decrypt(cipherText, additionalData []byte) ([]byte, error) {
+	// Split the nonce from the cipher text
+	nonceSize := a.aead.NonceSize()
+	if len(cipherText) < nonceSize+a.aead.Overhead() {
+		return nil, newError("aeadCipher.Decrypt", ErrCiphertextTooShort)
+	}
+	nonce, cipherText := cipherText[:nonceSize], cipherText[nonceSize:]
+
+	// Decrypt the cipher text
+	plainText, err := a.aead.Open(nil, nonce, cipherText, additionalData)
+	if err != nil {
+		return nil, newError("aeadCipher.Decrypt", ErrAuthenticationFailed)
+	}
+	return plainText, nil
+}
+
+// Overhead returns the size in bytes of the nonce and the tag
+//
+// Returns:
+//
+//   - The overhead
+func (a *aeadCipher) Overhead() int {
+	return a.aead.NonceSize() + a.aead.Overhead()
+}
+
+// Algorithm returns the name of the cipher
+//
+// Returns:
+//
+//   - The name of the cipher
+func (a *aeadCipher) Algorithm() string {
+	return a.name
+}
@@ -0,0 +1,95 @@
+package gocrypto
+
+import (
+	"sort"
+	"sync"
+)
+
+type (
+	// Cipher is an encryption mode with a prepared key. The cipher text produced by Encrypt is self-contained (it
+	// includes any nonce or tag), so it can be passed as is to Decrypt
+	Cipher interface {
+		// Encrypt encrypts the plain text, binding it to the additional authenticated data if the mode supports it
+		Encrypt(plainText, additionalData []byte) ([]byte, error)
+
+		// Decrypt decrypts a cipher text produced by Encrypt, verifying the additional authenticated data if the mode
+		// supports it
+		Decrypt(cipherText, additionalData []byte) ([]byte, error)
+
+		// Overhead returns the difference in bytes between the cipher text and the plain text lengths
+		Overhead() int
+
+		// Algorithm returns the name under which the cipher is registered
+		Algorithm() string
+	}
+
+	// CipherFactory creates a Cipher with the given key
+	CipherFactory func(key []byte) (Cipher, error)
+)
+
+var (
+	// ciphersMutex guards the cipher registry
+	ciphersMutex sync.RWMutex
+
+	// ciphers is the cipher registry, indexed by algorithm name
+	ciphers = make(map[string]CipherFactory)
+)
+
+// RegisterCipher registers a cipher factory under the given algorithm name, so the cipher can be created by name with
+// NewCipher. Packages providing ciphers register them in their init function. It panics if the factory is nil or if
+// the name is already registered
+//
+// Parameters:
+//
+//   - name: The algorithm name (e.g., "aes-gcm")
+//   - factory: The cipher factory
+func RegisterCipher(name string, factory CipherFactory) {
+	ciphersMutex.Lock()
+	defer ciphersMutex.Unlock()
+
+	if factory == nil {
+		panic("gocrypto: cipher factory is nil for " + name)
+	}
+	if _, ok := ciphers[name]; ok {
+		panic("gocrypto: cipher is already registered: " + name)
+	}
+	ciphers[name] = factory
+}
+
+// NewCipher creates a cipher by its algorithm name, which allows the algorithm to be selected from configuration
+//
+// Parameters:
+//
+//   - name: The algorithm name
+//   - key: The key to use for encryption and decryption
+//
+// Returns:
+//
+//   - The cipher
+//   - ErrUnknownCipher if no cipher is registered under the name, or any other error that occurred while creating it
+func NewCipher(name string, key []byte) (Cipher, error) {
+	ciphersMutex.RLock()
+	factory, ok := ciphers[name]
+	ciphersMutex.RUnlock()
+	if !ok {
+		return nil, newError("NewCipher", ErrUnknownCipher)
+	}
+	return factory(key)
+}
+
+// Ciphers returns the sorted names of the registered ciphers
+//
+// Returns:
+//
+//   - The algorithm names
+func Ciphers() []string {
+	ciphersMutex.RLock()
+	defer ciphersMutex.RUnlock()
+
+	names := make([]string, 0, len(ciphers))
+	for name := range ciphers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
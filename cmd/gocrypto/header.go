@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// The encrypted file starts with a header that describes how the key is obtained, followed by an AES-GCM stream
+// produced by aes.GCMStreamWriter, so decryption only needs the key or the passphrase.
+//
+// Header: magic (4 bytes) || version (1 byte) || key source (1 byte) || key source parameters
+// Passphrase parameters: iterations (4 bytes, big endian) || salt length (1 byte) || salt
+//
+// The header is not authenticated on its own, but any modification of the passphrase parameters derives a different
+// key, which makes the first chunk of the stream fail authentication.
+
+const (
+	// fileVersion is the current version of the file format
+	fileVersion = 1
+
+	// maxIterations is the maximum number of PBKDF2 iterations accepted from a file header
+	maxIterations = 10000000
+)
+
+// Key sources, stored in the file header
+const (
+	keySourceKey keySource = iota + 1
+	keySourcePassphrase
+)
+
+var (
+	// fileMagic is the prefix that identifies an encrypted file
+	fileMagic = [4]byte{'G', 'O', 'C', 'R'}
+
+	ErrInvalidFileHeader      = errors.New("input is not a gocrypto encrypted file")
+	ErrUnsupportedFileVersion = errors.New("unsupported gocrypto file version")
+)
+
+type (
+	// keySource is the identifier of the way the key of a file is obtained
+	keySource uint8
+
+	// fileHeader is the header of an encrypted file
+	fileHeader struct {
+		source     keySource
+		iterations uint32
+		salt       []byte
+	}
+)
+
+// write writes the header
+//
+// Parameters:
+//
+//   - writer: The writer
+//
+// Returns:
+//
+//   - An error if any occurred while writing the header
+func (f *fileHeader) write(writer io.Writer) error {
+	header := make([]byte, 0, len(fileMagic)+7+len(f.salt))
+	header = append(header, fileMagic[:]...)
+	header = append(header, fileVersion, byte(f.source))
+	if f.source == keySourcePassphrase {
+		header = binary.BigEndian.AppendUint32(header, f.iterations)
+		header = append(header, byte(len(f.salt)))
+		header = append(header, f.salt...)
+	}
+	_, err := writer.Write(header)
+	return err
+}
+
+// readFileHeader reads the header of an encrypted file
+//
+// Parameters:
+//
+//   - reader: The reader
+//
+// Returns:
+//
+//   - A pointer to the header
+//   - An error if the header is not valid or any occurred while reading it
+func readFileHeader(reader io.Reader) (*fileHeader, error) {
+	// Read the magic, the version and the key source
+	prefix := make([]byte, len(fileMagic)+2)
+	if _, err := io.ReadFull(reader, prefix); err != nil {
+		return nil, ErrInvalidFileHeader
+	}
+	if [4]byte(prefix[:4]) != fileMagic {
+		return nil, ErrInvalidFileHeader
+	}
+	if prefix[4] != fileVersion {
+		return nil, ErrUnsupportedFileVersion
+	}
+
+	header := &fileHeader{source: keySource(prefix[5])}
+	switch header.source {
+	case keySourceKey:
+		return header, nil
+	case keySourcePassphrase:
+	default:
+		return nil, ErrInvalidFileHeader
+	}
+
+	// Read the passphrase parameters
+	parameters := make([]byte, 5)
+	if _, err := io.ReadFull(reader, parameters); err != nil {
+		return nil, ErrInvalidFileHeader
+	}
+	header.iterations = binary.BigEndian.Uint32(parameters[:4])
+	if header.iterations == 0 || header.iterations > maxIterations {
+		return nil, ErrInvalidFileHeader
+	}
+	header.salt = make([]byte, parameters[4])
+	if _, err := io.ReadFull(reader, header.salt); err != nil {
+		return nil, ErrInvalidFileHeader
+	}
+	return header, nil
+}
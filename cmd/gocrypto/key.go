@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+
+	gocryptopbkdf2 "github.com/ralvarezdev/go-crypto/pbkdf2"
+	gocryptorandombytes "github.com/ralvarezdev/go-crypto/random/bytes"
+)
+
+const (
+	// defaultIterations is the default number of PBKDF2 iterations used to derive the key from a passphrase
+	defaultIterations = 600000
+
+	// saltSize is the size in bytes of the PBKDF2 salt
+	saltSize = 16
+
+	// derivedKeySize is the size in bytes of the key derived from a passphrase
+	derivedKeySize = 32
+)
+
+var (
+	ErrNoKeySource        = errors.New("one of -key-file, -key-env, -passphrase-file or -passphrase-env is required")
+	ErrTooManyKeySources  = errors.New("only one of -key-file, -key-env, -passphrase-file or -passphrase-env can be set")
+	ErrInvalidKey         = errors.New("key must be 16, 24 or 32 bytes long, raw or in hexadecimal format")
+	ErrEmptyPassphrase    = errors.New("passphrase is empty")
+	ErrEmptyEnvVar        = errors.New("environment variable is not set or is empty")
+	ErrKeySourceMismatch  = errors.New("file was encrypted with a different kind of key source")
+	ErrInvalidIterations  = errors.New("iterations must be between 1 and 10000000")
+	ErrUnexpectedArgument = errors.New("unexpected argument")
+)
+
+type (
+	// keyFlags are the flags that select the key source
+	keyFlags struct {
+		keyFile        string
+		keyEnv         string
+		passphraseFile string
+		passphraseEnv  string
+	}
+)
+
+// source returns the kind of key source selected by the flags
+//
+// Returns:
+//
+//   - The key source
+//   - An error if no key source or more than one is selected
+func (k *keyFlags) source() (keySource, error) {
+	var sources []keySource
+	if k.keyFile != "" {
+		sources = append(sources, keySourceKey)
+	}
+	if k.keyEnv != "" {
+		sources = append(sources, keySourceKey)
+	}
+	if k.passphraseFile != "" {
+		sources = append(sources, keySourcePassphrase)
+	}
+	if k.passphraseEnv != "" {
+		sources = append(sources, keySourcePassphrase)
+	}
+
+	switch len(sources) {
+	case 0:
+		return 0, ErrNoKeySource
+	case 1:
+		return sources[0], nil
+	default:
+		return 0, ErrTooManyKeySources
+	}
+}
+
+// readSecret reads the secret from the file or the environment variable selected by the flags
+//
+// Parameters:
+//
+//   - path: The path of the file (may be empty)
+//   - envVar: The name of the environment variable (may be empty)
+//
+// Returns:
+//
+//   - The secret
+//   - An error if the secret cannot be read
+func readSecret(path, envVar string) ([]byte, error) {
+	if path != "" {
+		secret, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return secret, nil
+	}
+
+	secret := os.Getenv(envVar)
+	if secret == "" {
+		return nil, ErrEmptyEnvVar
+	}
+	return []byte(secret), nil
+}
+
+// parseKey parses a key, either in hexadecimal format or raw. The hexadecimal format is tried first, since a
+// hexadecimal 16 byte key has the same length as a raw 32 byte key
+//
+// Parameters:
+//
+//   - secret: The key
+//
+// Returns:
+//
+//   - The key
+//   - ErrInvalidKey if the key is not valid
+func parseKey(secret []byte) ([]byte, error) {
+	key, err := hex.DecodeString(string(bytes.TrimSpace(secret)))
+	if err != nil {
+		key = secret
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	default:
+		return nil, ErrInvalidKey
+	}
+}
+
+// newFileHeader creates the header of a new encrypted file
+//
+// Parameters:
+//
+//   - source: The key source
+//   - iterations: The number of PBKDF2 iterations, used for passphrases
+//
+// Returns:
+//
+//   - A pointer to the header
+//   - An error if the salt cannot be generated
+func newFileHeader(source keySource, iterations int) (*fileHeader, error) {
+	header := &fileHeader{source: source}
+	if source != keySourcePassphrase {
+		return header, nil
+	}
+
+	if iterations < 1 || iterations > maxIterations {
+		return nil, ErrInvalidIterations
+	}
+	salt, err := gocryptorandombytes.Generate(saltSize)
+	if err != nil {
+		return nil, err
+	}
+	header.iterations = uint32(iterations)
+	header.salt = salt
+	return header, nil
+}
+
+// resolveKey obtains the key of a file from the flags and the file header
+//
+// Parameters:
+//
+//   - flags: The key flags
+//   - header: The file header
+//
+// Returns:
+//
+//   - The key
+//   - An error if the key cannot be obtained
+func resolveKey(flags *keyFlags, header *fileHeader) ([]byte, error) {
+	source, err := flags.source()
+	if err != nil {
+		return nil, err
+	}
+	if source != header.source {
+		return nil, ErrKeySourceMismatch
+	}
+
+	// Read the key directly
+	if source == keySourceKey {
+		secret, err := readSecret(flags.keyFile, flags.keyEnv)
+		if err != nil {
+			return nil, err
+		}
+		return parseKey(secret)
+	}
+
+	// Derive the key from the passphrase
+	passphrase, err := readSecret(flags.passphraseFile, flags.passphraseEnv)
+	if err != nil {
+		return nil, err
+	}
+	passphrase = bytes.TrimRight(passphrase, "\r\n")
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+	return gocryptopbkdf2.DeriveKey(
+		string(passphrase),
+		header.salt,
+		int(header.iterations),
+		derivedKeySize,
+		sha256.New,
+	), nil
+}
@@ -0,0 +1,295 @@
+// Command gocrypto encrypts and decrypts files with AES-GCM.
+//
+// Usage:
+//
+//	gocrypto encrypt [-in file] [-out file] <key source> [-iterations n] [-chunk-size n]
+//	gocrypto decrypt [-in file] [-out file] <key source>
+//
+// The key source is one of:
+//
+//	-key-file path          file holding a 16, 24 or 32 byte key, raw or in hexadecimal format
+//	-key-env name           environment variable holding the key in hexadecimal format
+//	-passphrase-file path   file holding a passphrase, from which the key is derived with PBKDF2-SHA256
+//	-passphrase-env name    environment variable holding a passphrase
+//
+// The input and the output default to stdin and stdout. The encrypted output records how the key is obtained and the
+// key derivation parameters, so decryption only needs the key or the passphrase.
+//
+// Decryption never leaves output of an input that fails authentication: the output file is removed, and the plain text
+// written to stdout is held in memory until the whole input was authenticated. Use -out to decrypt files that do not
+// fit in memory.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	gocryptoaes "github.com/ralvarezdev/go-crypto/aes"
+)
+
+// usage is the usage message of the command
+const usage = `usage:
+  gocrypto encrypt [-in file] [-out file] <key source> [-iterations n] [-chunk-size n]
+  gocrypto decrypt [-in file] [-out file] <key source>
+
+Run 'gocrypto <command> -h' for the flags of a command.
+`
+
+type (
+	// options are the flags of the subcommands
+	options struct {
+		in         string
+		out        string
+		keys       keyFlags
+		iterations int
+		chunkSize  int
+	}
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command
+//
+// Parameters:
+//
+//   - args: The command line arguments, without the program name
+//   - stdin: The standard input
+//   - stdout: The standard output
+//   - stderr: The standard error
+//
+// Returns:
+//
+//   - The exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	// Parse the flags of the subcommand
+	command := args[0]
+	var encrypt bool
+	switch command {
+	case "encrypt":
+		encrypt = true
+	case "decrypt":
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "gocrypto: unknown command %q\n\n%s", command, usage)
+		return 2
+	}
+
+	opts, err := parseFlags(command, args[1:], encrypt, stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(stderr, "gocrypto %s: %v\n", command, err)
+		return 2
+	}
+
+	// Run the subcommand
+	if err = process(opts, encrypt, stdin, stdout); err != nil {
+		fmt.Fprintf(stderr, "gocrypto %s: %v\n", command, err)
+		return 1
+	}
+	return 0
+}
+
+// parseFlags parses the flags of a subcommand
+//
+// Parameters:
+//
+//   - command: The name of the subcommand
+//   - args: The arguments of the subcommand
+//   - encrypt: Whether the subcommand is encrypt
+//   - stderr: The output of the flag errors and usage
+//
+// Returns:
+//
+//   - A pointer to the options
+//   - An error if the flags are not valid
+func parseFlags(
+	command string,
+	args []string,
+	encrypt bool,
+	stderr io.Writer,
+) (*options, error) {
+	opts := &options{}
+	flags := flag.NewFlagSet("gocrypto "+command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&opts.in, "in", "", "input file (default stdin)")
+	flags.StringVar(&opts.out, "out", "", "output file (default stdout)")
+	flags.StringVar(&opts.keys.keyFile, "key-file", "", "file holding the key, raw or in hexadecimal format")
+	flags.StringVar(&opts.keys.keyEnv, "key-env", "", "environment variable holding the key in hexadecimal format")
+	flags.StringVar(&opts.keys.passphraseFile, "passphrase-file", "", "file holding the passphrase")
+	flags.StringVar(&opts.keys.passphraseEnv, "passphrase-env", "", "environment variable holding the passphrase")
+	if encrypt {
+		flags.IntVar(&opts.iterations, "iterations", defaultIterations, "PBKDF2 iterations for passphrases")
+		flags.IntVar(&opts.chunkSize, "chunk-size", gocryptoaes.DefaultStreamChunkSize, "plain text bytes per chunk")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedArgument, flags.Arg(0))
+	}
+	if _, err := opts.keys.source(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// process encrypts or decrypts the input into the output. The output file is removed if the process fails, and the
+// plain text decrypted to stdout is only written once the whole input was authenticated
+//
+// Parameters:
+//
+//   - opts: The options
+//   - encrypt: Whether to encrypt or decrypt
+//   - stdin: The standard input
+//   - stdout: The standard output
+//
+// Returns:
+//
+//   - An error if any occurred during the process
+func process(opts *options, encrypt bool, stdin io.Reader, stdout io.Writer) (
+	err error,
+) {
+	// Open the input
+	input := stdin
+	if opts.in != "" {
+		file, openErr := os.Open(opts.in)
+		if openErr != nil {
+			return openErr
+		}
+		defer file.Close()
+		input = file
+	}
+
+	// Create the output
+	output := stdout
+	if opts.out != "" {
+		file, createErr := os.OpenFile(
+			opts.out,
+			os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
+			0o600,
+		)
+		if createErr != nil {
+			return createErr
+		}
+		defer func() {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err == nil {
+				return
+			}
+			if removeErr := os.Remove(opts.out); removeErr != nil {
+				err = fmt.Errorf("%w (removing the partial output: %w)", err, removeErr)
+			}
+		}()
+		output = file
+	}
+
+	if encrypt {
+		return encryptStream(opts, input, output)
+	}
+	if opts.out != "" {
+		return decryptStream(opts, input, output)
+	}
+
+	// Buffer the plain text, since the chunks of a truncated or tampered input that were authenticated before the
+	// failure would otherwise already be written to stdout
+	var buffer bytes.Buffer
+	err = decryptStream(opts, input, &buffer)
+	plainText := buffer.Bytes()
+	defer clear(plainText)
+	if err != nil {
+		return err
+	}
+	_, err = output.Write(plainText)
+	return err
+}
+
+// encryptStream writes the file header and the encrypted stream of the input
+//
+// Parameters:
+//
+//   - opts: The options
+//   - input: The plain text input
+//   - output: The encrypted output
+//
+// Returns:
+//
+//   - An error if any occurred during the encryption
+func encryptStream(opts *options, input io.Reader, output io.Writer) error {
+	// Create the file header and obtain the key
+	source, err := opts.keys.source()
+	if err != nil {
+		return err
+	}
+	header, err := newFileHeader(source, opts.iterations)
+	if err != nil {
+		return err
+	}
+	key, err := resolveKey(&opts.keys, header)
+	if err != nil {
+		return err
+	}
+	defer clear(key)
+
+	// Write the header and the encrypted stream
+	if err = header.write(output); err != nil {
+		return err
+	}
+	writer, err := gocryptoaes.NewGCMStreamWriter(output, key, opts.chunkSize)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(writer, input); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// decryptStream reads the file header and decrypts the stream of the input
+//
+// Parameters:
+//
+//   - opts: The options
+//   - input: The encrypted input
+//   - output: The plain text output
+//
+// Returns:
+//
+//   - An error if any occurred during the decryption
+func decryptStream(opts *options, input io.Reader, output io.Writer) error {
+	// Read the file header and obtain the key
+	header, err := readFileHeader(input)
+	if err != nil {
+		return err
+	}
+	key, err := resolveKey(&opts.keys, header)
+	if err != nil {
+		return err
+	}
+	defer clear(key)
+
+	// Decrypt the stream
+	reader, err := gocryptoaes.NewGCMStreamReader(input, key)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(output, reader)
+	return err
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testIterations is the number of PBKDF2 iterations used by the tests, to keep them fast
+const testIterations = 1000
+
+// runCommand runs the command and returns its exit code, standard output and standard error
+func runCommand(t *testing.T, stdin []byte, args ...string) (int, []byte, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(args, bytes.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.Bytes(), stderr.String()
+}
+
+// writeTestFile writes a file in a temporary directory and returns its path
+func writeTestFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+	return path
+}
+
+// testKeySources returns the flags of every key source, backed by temporary files and environment variables
+func testKeySources(t *testing.T) map[string][]string {
+	t.Helper()
+
+	key := hex.EncodeToString(bytes.Repeat([]byte{0x42}, 32))
+	t.Setenv("GOCRYPTO_TEST_KEY", key)
+	t.Setenv("GOCRYPTO_TEST_PASSPHRASE", "correct horse battery staple")
+
+	return map[string][]string{
+		"key file":        {"-key-file", writeTestFile(t, "key", []byte(key+"\n"))},
+		"raw key file":    {"-key-file", writeTestFile(t, "raw-key", bytes.Repeat([]byte{0x42}, 32))},
+		"key env":         {"-key-env", "GOCRYPTO_TEST_KEY"},
+		"passphrase file": {"-passphrase-file", writeTestFile(t, "passphrase", []byte("correct horse battery staple\n"))},
+		"passphrase env":  {"-passphrase-env", "GOCRYPTO_TEST_PASSPHRASE"},
+	}
+}
+
+// encryptArgs returns the arguments of the encrypt command with the key source and a small chunk size
+func encryptArgs(keySource []string, extra ...string) []string {
+	args := append([]string{"encrypt", "-iterations", strconv.Itoa(testIterations), "-chunk-size", "64"}, keySource...)
+	return append(args, extra...)
+}
+
+func TestRunRoundTrip(t *testing.T) {
+	plainText := bytes.Repeat([]byte("gocrypto round trip "), 50)
+
+	for name, keySource := range testKeySources(t) {
+		t.Run(
+			name, func(t *testing.T) {
+				// Through stdin and stdout
+				code, encrypted, stderr := runCommand(t, plainText, encryptArgs(keySource)...)
+				if code != 0 {
+					t.Fatalf("encrypt exited with %d: %s", code, stderr)
+				}
+				if bytes.Contains(encrypted, plainText[:20]) {
+					t.Fatalf("encrypt output contains the plain text")
+				}
+				code, decrypted, stderr := runCommand(t, encrypted, append([]string{"decrypt"}, keySource...)...)
+				if code != 0 {
+					t.Fatalf("decrypt exited with %d: %s", code, stderr)
+				}
+				if !bytes.Equal(decrypted, plainText) {
+					t.Fatalf("decrypt returned a different plain text")
+				}
+
+				// Through files
+				dir := t.TempDir()
+				in := writeTestFile(t, "plain", plainText)
+				encryptedPath := filepath.Join(dir, "encrypted")
+				decryptedPath := filepath.Join(dir, "decrypted")
+				if code, _, stderr = runCommand(
+					t,
+					nil,
+					encryptArgs(keySource, "-in", in, "-out", encryptedPath)...,
+				); code != 0 {
+					t.Fatalf("encrypt exited with %d: %s", code, stderr)
+				}
+				if code, _, stderr = runCommand(
+					t,
+					nil,
+					append([]string{"decrypt", "-in", encryptedPath, "-out", decryptedPath}, keySource...)...,
+				); code != 0 {
+					t.Fatalf("decrypt exited with %d: %s", code, stderr)
+				}
+				decrypted, err := os.ReadFile(decryptedPath)
+				if err != nil {
+					t.Fatalf("reading %s: %v", decryptedPath, err)
+				}
+				if !bytes.Equal(decrypted, plainText) {
+					t.Fatalf("decrypt wrote a different plain text")
+				}
+			},
+		)
+	}
+}
+
+func TestRunKeySourceMismatch(t *testing.T) {
+	keySources := testKeySources(t)
+
+	code, encrypted, stderr := runCommand(t, []byte("data"), encryptArgs(keySources["key file"])...)
+	if code != 0 {
+		t.Fatalf("encrypt exited with %d: %s", code, stderr)
+	}
+
+	// A file encrypted with a key cannot be decrypted with a passphrase, and the output file is removed
+	out := filepath.Join(t.TempDir(), "decrypted")
+	code, _, stderr = runCommand(
+		t,
+		encrypted,
+		append([]string{"decrypt", "-out", out}, keySources["passphrase env"]...)...,
+	)
+	if code != 1 {
+		t.Fatalf("decrypt with a passphrase exited with %d, want 1", code)
+	}
+	if !strings.Contains(stderr, ErrKeySourceMismatch.Error()) {
+		t.Fatalf("decrypt with a passphrase: got %q, want %q", stderr, ErrKeySourceMismatch)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Fatalf("decrypt with a passphrase left the output file: %v", err)
+	}
+}
+
+func TestRunDecryptFailureWritesNothing(t *testing.T) {
+	keySources := testKeySources(t)
+	plainText := bytes.Repeat([]byte("chunk of plain text "), 20)
+
+	code, encrypted, stderr := runCommand(t, plainText, encryptArgs(keySources["key env"])...)
+	if code != 0 {
+		t.Fatalf("encrypt exited with %d: %s", code, stderr)
+	}
+
+	// The first chunks authenticate, but the last one does not
+	tampered := bytes.Clone(encrypted)
+	tampered[len(tampered)-1] ^= 1
+	truncated := encrypted[:len(encrypted)-10]
+
+	for name, input := range map[string][]byte{"tampered": tampered, "truncated": truncated} {
+		t.Run(
+			name, func(t *testing.T) {
+				code, stdout, _ := runCommand(t, input, append([]string{"decrypt"}, keySources["key env"]...)...)
+				if code != 1 {
+					t.Fatalf("decrypt exited with %d, want 1", code)
+				}
+				if len(stdout) != 0 {
+					t.Fatalf("decrypt wrote %d bytes to stdout before failing", len(stdout))
+				}
+
+				out := filepath.Join(t.TempDir(), "decrypted")
+				code, _, _ = runCommand(
+					t,
+					input,
+					append([]string{"decrypt", "-out", out}, keySources["key env"]...)...,
+				)
+				if code != 1 {
+					t.Fatalf("decrypt to a file exited with %d, want 1", code)
+				}
+				if _, err := os.Stat(out); !os.IsNotExist(err) {
+					t.Fatalf("decrypt left the output file: %v", err)
+				}
+			},
+		)
+	}
+}
+
+func TestRunInvalidArguments(t *testing.T) {
+	keySources := testKeySources(t)
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+		err  string
+	}{
+		{name: "no command", code: 2},
+		{name: "unknown command", args: []string{"sign"}, code: 2, err: "unknown command"},
+		{name: "no key source", args: []string{"encrypt"}, code: 2, err: ErrNoKeySource.Error()},
+		{
+			name: "two key sources",
+			args: append(append([]string{"encrypt"}, keySources["key env"]...), keySources["passphrase env"]...),
+			code: 2,
+			err:  ErrTooManyKeySources.Error(),
+		},
+		{
+			name: "unexpected argument",
+			args: append(append([]string{"decrypt"}, keySources["key env"]...), "file"),
+			code: 2,
+			err:  ErrUnexpectedArgument.Error(),
+		},
+		{
+			name: "unset environment variable",
+			args: []string{"encrypt", "-key-env", "GOCRYPTO_TEST_UNSET"},
+			code: 1,
+			err:  ErrEmptyEnvVar.Error(),
+		},
+		{
+			name: "invalid iterations",
+			args: append([]string{"encrypt", "-iterations", "0"}, keySources["passphrase env"]...),
+			code: 1,
+			err:  ErrInvalidIterations.Error(),
+		},
+		{
+			name: "not an encrypted file",
+			args: append([]string{"decrypt"}, keySources["key env"]...),
+			code: 1,
+			err:  ErrInvalidFileHeader.Error(),
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				code, _, stderr := runCommand(t, []byte("plain"), test.args...)
+				if code != test.code {
+					t.Fatalf("run exited with %d, want %d: %s", code, test.code, stderr)
+				}
+				if !strings.Contains(stderr, test.err) {
+					t.Fatalf("run: got %q, want %q", stderr, test.err)
+				}
+			},
+		)
+	}
+}
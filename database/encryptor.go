@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+
+	gocryptoaes "github.com/ralvarezdev/go-crypto/aes"
+)
+
+type (
+	// encryptorContextKey is the context key of the encryptor
+	encryptorContextKey struct{}
+)
+
+var (
+	// defaultEncryptor is the process-wide encryptor
+	defaultEncryptor atomic.Pointer[gocryptoaes.GCMEncryptor]
+)
+
+// SetDefaultEncryptor sets the process-wide encryptor, used by the encrypted types when no encryptor is bound from a
+// context
+//
+// Parameters:
+//
+//   - encryptor: The encryptor, or nil to unset it
+func SetDefaultEncryptor(encryptor *gocryptoaes.GCMEncryptor) {
+	defaultEncryptor.Store(encryptor)
+}
+
+// SetDefaultKey sets the process-wide encryptor from a key
+//
+// Parameters:
+//
+//   - key: The key (must be 16, 24 or 32 bytes long)
+//
+// Returns:
+//
+//   - An error if the key is invalid
+func SetDefaultKey(key []byte) error {
+	encryptor, err := gocryptoaes.NewGCMEncryptor(key)
+	if err != nil {
+		return err
+	}
+	SetDefaultEncryptor(encryptor)
+	return nil
+}
+
+// NewContext returns a copy of the context that carries the encryptor
+//
+// Parameters:
+//
+//   - ctx: The parent context
+//   - encryptor: The encryptor
+//
+// Returns:
+//
+//   - The context with the encryptor
+func NewContext(
+	ctx context.Context,
+	encryptor *gocryptoaes.GCMEncryptor,
+) context.Context {
+	return context.WithValue(ctx, encryptorContextKey{}, encryptor)
+}
+
+// FromContext returns the encryptor carried by the context
+//
+// Parameters:
+//
+//   - ctx: The context
+//
+// Returns:
+//
+//   - The encryptor, or nil if the context does not carry one
+func FromContext(ctx context.Context) *gocryptoaes.GCMEncryptor {
+	if ctx == nil {
+		return nil
+	}
+	encryptor, _ := ctx.Value(encryptorContextKey{}).(*gocryptoaes.GCMEncryptor)
+	return encryptor
+}
+
+// resolveEncryptor returns the bound encryptor, or the process-wide encryptor if none is bound
+//
+// Parameters:
+//
+//   - encryptor: The bound encryptor (may be nil)
+//
+// Returns:
+//
+//   - The encryptor
+//   - ErrNoEncryptor if no encryptor is available
+func resolveEncryptor(encryptor *gocryptoaes.GCMEncryptor) (
+	*gocryptoaes.GCMEncryptor,
+	error,
+) {
+	if encryptor != nil {
+		return encryptor, nil
+	}
+	if encryptor = defaultEncryptor.Load(); encryptor != nil {
+		return encryptor, nil
+	}
+	return nil, newError("resolveEncryptor", ErrNoEncryptor)
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"errors"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// packageName is the package name reported by the errors of the package
+const packageName = "database"
+
+var (
+	ErrNoEncryptor         = errors.New("no encryptor in the context and no default encryptor is set")
+	ErrUnsupportedScanType = errors.New("unsupported scan source type")
+)
+
+// newError wraps an error with the operation that failed and the package name
+//
+// Parameters:
+//
+//   - op: The name of the operation
+//   - err: The error to wrap
+//
+// Returns:
+//
+//   - The wrapped error, or nil if err is nil
+func newError(op string, err error) error {
+	return gocrypto.NewError(packageName, op, err)
+}
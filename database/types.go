@@ -0,0 +1,329 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+
+	gocryptoaes "github.com/ralvarezdev/go-crypto/aes"
+)
+
+// The encrypted types hold the plain text in memory and the cipher text in the database and in JSON, encrypted with
+// AES-GCM. Like sql.NullString, a value that is not valid is stored as NULL and marshaled as JSON null.
+//
+// The key is taken from the encryptor bound with WithContext or, if none is bound, from the process-wide encryptor set
+// with SetDefaultEncryptor or SetDefaultKey. Since driver.Valuer and sql.Scanner do not receive a context, a
+// context-provided encryptor is bound to the value before passing it to the database:
+//
+//	db.ExecContext(ctx, query, email.WithContext(ctx))
+//	row.Scan(email.WithContext(ctx))
+
+var (
+	// jsonNull is the JSON null literal
+	jsonNull = []byte("null")
+)
+
+type (
+	// EncryptedString is a nullable string stored encrypted, as the same hexadecimal format produced by aes.EncryptGCM
+	EncryptedString struct {
+		String    string
+		Valid     bool
+		encryptor *gocryptoaes.GCMEncryptor
+	}
+
+	// EncryptedBytes is a nullable byte slice stored encrypted, as the raw nonce followed by the cipher text
+	EncryptedBytes struct {
+		Bytes     []byte
+		Valid     bool
+		encryptor *gocryptoaes.GCMEncryptor
+	}
+)
+
+// NewEncryptedString creates a valid EncryptedString
+//
+// Parameters:
+//
+//   - value: The plain text
+//
+// Returns:
+//
+//   - The EncryptedString
+func NewEncryptedString(value string) EncryptedString {
+	return EncryptedString{String: value, Valid: true}
+}
+
+// WithContext binds the encryptor carried by the context, if any, to the value
+//
+// Parameters:
+//
+//   - ctx: The context
+//
+// Returns:
+//
+//   - The receiver, so it can be passed directly to the database
+func (e *EncryptedString) WithContext(ctx context.Context) *EncryptedString {
+	e.encryptor = FromContext(ctx)
+	return e
+}
+
+// encrypt encrypts the plain text
+//
+// Returns:
+//
+//   - The encrypted string in hexadecimal format
+//   - An error if any occurred during the encryption process
+func (e EncryptedString) encrypt() (string, error) {
+	encryptor, err := resolveEncryptor(e.encryptor)
+	if err != nil {
+		return "", err
+	}
+
+	encryptedText, err := encryptor.Encrypt([]byte(e.String), nil)
+	if err != nil {
+		return "", err
+	}
+	return *encryptedText, nil
+}
+
+// decrypt decrypts an encrypted string into the value
+//
+// Parameters:
+//
+//   - encryptedText: The encrypted string in hexadecimal format
+//
+// Returns:
+//
+//   - An error if any occurred during the decryption process
+func (e *EncryptedString) decrypt(encryptedText string) error {
+	encryptor, err := resolveEncryptor(e.encryptor)
+	if err != nil {
+		return err
+	}
+
+	plainText, err := encryptor.Decrypt(&encryptedText, nil)
+	if err != nil {
+		return err
+	}
+	e.String, e.Valid = *plainText, true
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+//
+// Returns:
+//
+//   - The encrypted string in hexadecimal format, or nil if the value is not valid
+//   - An error if any occurred during the encryption process
+func (e EncryptedString) Value() (driver.Value, error) {
+	if !e.Valid {
+		return nil, nil
+	}
+	return e.encrypt()
+}
+
+// Scan implements the sql.Scanner interface
+//
+// Parameters:
+//
+//   - src: The encrypted string in hexadecimal format, as a string or a byte slice, or nil
+//
+// Returns:
+//
+//   - An error if the source type is not supported or any occurred during the decryption process
+func (e *EncryptedString) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		e.String, e.Valid = "", false
+		return nil
+	case string:
+		return e.decrypt(src)
+	case []byte:
+		return e.decrypt(string(src))
+	default:
+		return newError("EncryptedString.Scan", ErrUnsupportedScanType)
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+//
+// Returns:
+//
+//   - The encrypted string in hexadecimal format as a JSON string, or JSON null if the value is not valid
+//   - An error if any occurred during the encryption process
+func (e EncryptedString) MarshalJSON() ([]byte, error) {
+	if !e.Valid {
+		return jsonNull, nil
+	}
+
+	encryptedText, err := e.encrypt()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encryptedText)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+//
+// Parameters:
+//
+//   - data: The encrypted string in hexadecimal format as a JSON string, or JSON null
+//
+// Returns:
+//
+//   - An error if the data is not a JSON string or any occurred during the decryption process
+func (e *EncryptedString) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, jsonNull) {
+		e.String, e.Valid = "", false
+		return nil
+	}
+
+	var encryptedText string
+	if err := json.Unmarshal(data, &encryptedText); err != nil {
+		return err
+	}
+	return e.decrypt(encryptedText)
+}
+
+// NewEncryptedBytes creates a valid EncryptedBytes
+//
+// Parameters:
+//
+//   - value: The plain text
+//
+// Returns:
+//
+//   - The EncryptedBytes
+func NewEncryptedBytes(value []byte) EncryptedBytes {
+	return EncryptedBytes{Bytes: value, Valid: true}
+}
+
+// WithContext binds the encryptor carried by the context, if any, to the value
+//
+// Parameters:
+//
+//   - ctx: The context
+//
+// Returns:
+//
+//   - The receiver, so it can be passed directly to the database
+func (e *EncryptedBytes) WithContext(ctx context.Context) *EncryptedBytes {
+	e.encryptor = FromContext(ctx)
+	return e
+}
+
+// encrypt encrypts the plain text
+//
+// Returns:
+//
+//   - The nonce followed by the cipher text
+//   - An error if any occurred during the encryption process
+func (e EncryptedBytes) encrypt() ([]byte, error) {
+	encryptor, err := resolveEncryptor(e.encryptor)
+	if err != nil {
+		return nil, err
+	}
+	return encryptor.EncryptBytes(nil, e.Bytes, nil)
+}
+
+// decrypt decrypts a cipher text into the value
+//
+// Parameters:
+//
+//   - cipherText: The nonce followed by the cipher text
+//
+// Returns:
+//
+//   - An error if any occurred during the decryption process
+func (e *EncryptedBytes) decrypt(cipherText []byte) error {
+	encryptor, err := resolveEncryptor(e.encryptor)
+	if err != nil {
+		return err
+	}
+
+	plainText, err := encryptor.DecryptBytes(nil, cipherText, nil)
+	if err != nil {
+		return err
+	}
+	if plainText == nil {
+		plainText = []byte{}
+	}
+	e.Bytes, e.Valid = plainText, true
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+//
+// Returns:
+//
+//   - The nonce followed by the cipher text, or nil if the value is not valid
+//   - An error if any occurred during the encryption process
+func (e EncryptedBytes) Value() (driver.Value, error) {
+	if !e.Valid {
+		return nil, nil
+	}
+	return e.encrypt()
+}
+
+// Scan implements the sql.Scanner interface
+//
+// Parameters:
+//
+//   - src: The nonce followed by the cipher text, as a byte slice or a string, or nil
+//
+// Returns:
+//
+//   - An error if the source type is not supported or any occurred during the decryption process
+func (e *EncryptedBytes) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		e.Bytes, e.Valid = nil, false
+		return nil
+	case []byte:
+		return e.decrypt(src)
+	case string:
+		return e.decrypt([]byte(src))
+	default:
+		return newError("EncryptedBytes.Scan", ErrUnsupportedScanType)
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+//
+// Returns:
+//
+//   - The nonce followed by the cipher text as a base64 JSON string, or JSON null if the value is not valid
+//   - An error if any occurred during the encryption process
+func (e EncryptedBytes) MarshalJSON() ([]byte, error) {
+	if !e.Valid {
+		return jsonNull, nil
+	}
+
+	cipherText, err := e.encrypt()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(cipherText)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+//
+// Parameters:
+//
+//   - data: The nonce followed by the cipher text as a base64 JSON string, or JSON null
+//
+// Returns:
+//
+//   - An error if the data is not a base64 JSON string or any occurred during the decryption process
+func (e *EncryptedBytes) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, jsonNull) {
+		e.Bytes, e.Valid = nil, false
+		return nil
+	}
+
+	var cipherText []byte
+	if err := json.Unmarshal(data, &cipherText); err != nil {
+		return err
+	}
+	return e.decrypt(cipherText)
+}
@@ -0,0 +1,250 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	gocryptoaes "github.com/ralvarezdev/go-crypto/aes"
+)
+
+// newTestContext returns a context carrying an encryptor with a random key
+func newTestContext(t *testing.T) context.Context {
+	t.Helper()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+	encryptor, err := gocryptoaes.NewGCMEncryptor(key)
+	if err != nil {
+		t.Fatalf("NewGCMEncryptor: %v", err)
+	}
+	return NewContext(context.Background(), encryptor)
+}
+
+func TestEncryptedStringValueScan(t *testing.T) {
+	ctx := newTestContext(t)
+
+	for _, value := range []string{"jane@example.com", ""} {
+		encrypted := NewEncryptedString(value)
+		stored, err := encrypted.WithContext(ctx).Value()
+		if err != nil {
+			t.Fatalf("EncryptedString.Value: %v", err)
+		}
+		storedText, ok := stored.(string)
+		if !ok || storedText == value {
+			t.Fatalf("EncryptedString.Value = %v, want an encrypted string", stored)
+		}
+
+		// Drivers may return the column as a string or a byte slice
+		for _, src := range []any{storedText, []byte(storedText)} {
+			var scanned EncryptedString
+			if err = scanned.WithContext(ctx).Scan(src); err != nil {
+				t.Fatalf("EncryptedString.Scan: %v", err)
+			}
+			if !scanned.Valid || scanned.String != value {
+				t.Fatalf("EncryptedString.Scan = %+v, want %q", scanned, value)
+			}
+		}
+	}
+}
+
+func TestEncryptedBytesValueScan(t *testing.T) {
+	ctx := newTestContext(t)
+
+	for _, value := range [][]byte{{0x00, 0x01, 0xff}, {}} {
+		encrypted := NewEncryptedBytes(value)
+		stored, err := encrypted.WithContext(ctx).Value()
+		if err != nil {
+			t.Fatalf("EncryptedBytes.Value: %v", err)
+		}
+		storedBytes, ok := stored.([]byte)
+		if !ok || bytes.Equal(storedBytes, value) {
+			t.Fatalf("EncryptedBytes.Value = %v, want encrypted bytes", stored)
+		}
+
+		for _, src := range []any{storedBytes, string(storedBytes)} {
+			var scanned EncryptedBytes
+			if err = scanned.WithContext(ctx).Scan(src); err != nil {
+				t.Fatalf("EncryptedBytes.Scan: %v", err)
+			}
+			if !scanned.Valid || scanned.Bytes == nil || !bytes.Equal(scanned.Bytes, value) {
+				t.Fatalf("EncryptedBytes.Scan = %+v, want %x", scanned, value)
+			}
+		}
+	}
+}
+
+func TestEncryptedNull(t *testing.T) {
+	ctx := newTestContext(t)
+
+	// Values that are not valid are stored as NULL, without an encryptor
+	if stored, err := (EncryptedString{}).Value(); stored != nil || err != nil {
+		t.Fatalf("EncryptedString.Value = %v, %v, want nil", stored, err)
+	}
+	if stored, err := (EncryptedBytes{}).Value(); stored != nil || err != nil {
+		t.Fatalf("EncryptedBytes.Value = %v, %v, want nil", stored, err)
+	}
+
+	// Scanning NULL resets a previously valid value
+	encryptedString := NewEncryptedString("value")
+	if err := encryptedString.WithContext(ctx).Scan(nil); err != nil {
+		t.Fatalf("EncryptedString.Scan of NULL: %v", err)
+	}
+	if encryptedString.Valid || encryptedString.String != "" {
+		t.Fatalf("EncryptedString.Scan of NULL = %+v, want an invalid value", encryptedString)
+	}
+	encryptedBytes := NewEncryptedBytes([]byte("value"))
+	if err := encryptedBytes.WithContext(ctx).Scan(nil); err != nil {
+		t.Fatalf("EncryptedBytes.Scan of NULL: %v", err)
+	}
+	if encryptedBytes.Valid || encryptedBytes.Bytes != nil {
+		t.Fatalf("EncryptedBytes.Scan of NULL = %+v, want an invalid value", encryptedBytes)
+	}
+}
+
+func TestEncryptedJSON(t *testing.T) {
+	ctx := newTestContext(t)
+
+	type record struct {
+		Email  *EncryptedString `json:"email"`
+		Avatar *EncryptedBytes  `json:"avatar"`
+	}
+	email := NewEncryptedString("jane@example.com")
+	avatar := NewEncryptedBytes([]byte{0x89, 'P', 'N', 'G'})
+
+	data, err := json.Marshal(record{Email: email.WithContext(ctx), Avatar: avatar.WithContext(ctx)})
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if bytes.Contains(data, []byte("jane@example.com")) {
+		t.Fatalf("json.Marshal = %s, contains the plain text", data)
+	}
+
+	var decoded record
+	decoded.Email = new(EncryptedString).WithContext(ctx)
+	decoded.Avatar = new(EncryptedBytes).WithContext(ctx)
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if !decoded.Email.Valid || decoded.Email.String != email.String {
+		t.Fatalf("json.Unmarshal email = %+v, want %q", decoded.Email, email.String)
+	}
+	if !decoded.Avatar.Valid || !bytes.Equal(decoded.Avatar.Bytes, avatar.Bytes) {
+		t.Fatalf("json.Unmarshal avatar = %+v, want %x", decoded.Avatar, avatar.Bytes)
+	}
+
+	// Values that are not valid are marshaled as JSON null
+	data, err = json.Marshal(record{Email: &EncryptedString{}, Avatar: &EncryptedBytes{}})
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if string(data) != `{"email":null,"avatar":null}` {
+		t.Fatalf("json.Marshal = %s, want null values", data)
+	}
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if decoded.Email != nil || decoded.Avatar != nil {
+		t.Fatalf("json.Unmarshal of null values = %+v, want nil pointers", decoded)
+	}
+
+	var encryptedString EncryptedString
+	if err = encryptedString.UnmarshalJSON([]byte("null")); err != nil || encryptedString.Valid {
+		t.Fatalf("EncryptedString.UnmarshalJSON of null = %+v, %v", encryptedString, err)
+	}
+}
+
+func TestEncryptedTampered(t *testing.T) {
+	ctx := newTestContext(t)
+
+	encrypted := NewEncryptedBytes([]byte("value"))
+	stored, err := encrypted.WithContext(ctx).Value()
+	if err != nil {
+		t.Fatalf("EncryptedBytes.Value: %v", err)
+	}
+	tampered := bytes.Clone(stored.([]byte))
+	tampered[len(tampered)-1] ^= 1
+
+	var scanned EncryptedBytes
+	if err = scanned.WithContext(ctx).Scan(tampered); !errors.Is(
+		err,
+		gocryptoaes.ErrAuthenticationFailed,
+	) {
+		t.Fatalf("EncryptedBytes.Scan of a tampered value: got %v, want %v", err, gocryptoaes.ErrAuthenticationFailed)
+	}
+	if scanned.Valid {
+		t.Fatalf("EncryptedBytes.Scan of a tampered value set the value")
+	}
+
+	// A value encrypted with another key
+	var other EncryptedBytes
+	if err = other.WithContext(newTestContext(t)).Scan(stored); !errors.Is(
+		err,
+		gocryptoaes.ErrAuthenticationFailed,
+	) {
+		t.Fatalf("EncryptedBytes.Scan with another key: got %v, want %v", err, gocryptoaes.ErrAuthenticationFailed)
+	}
+}
+
+func TestEncryptedUnsupportedScanType(t *testing.T) {
+	ctx := newTestContext(t)
+
+	var encryptedString EncryptedString
+	if err := encryptedString.WithContext(ctx).Scan(42); !errors.Is(
+		err,
+		ErrUnsupportedScanType,
+	) {
+		t.Fatalf("EncryptedString.Scan of an int: got %v, want %v", err, ErrUnsupportedScanType)
+	}
+	var encryptedBytes EncryptedBytes
+	if err := encryptedBytes.WithContext(ctx).Scan(42); !errors.Is(
+		err,
+		ErrUnsupportedScanType,
+	) {
+		t.Fatalf("EncryptedBytes.Scan of an int: got %v, want %v", err, ErrUnsupportedScanType)
+	}
+}
+
+func TestDefaultEncryptor(t *testing.T) {
+	t.Cleanup(func() { SetDefaultEncryptor(nil) })
+
+	// Without an encryptor, valid values cannot be stored
+	SetDefaultEncryptor(nil)
+	if _, err := NewEncryptedString("value").Value(); !errors.Is(
+		err,
+		ErrNoEncryptor,
+	) {
+		t.Fatalf("EncryptedString.Value without an encryptor: got %v, want %v", err, ErrNoEncryptor)
+	}
+
+	if err := SetDefaultKey(make([]byte, 15)); !errors.Is(
+		err,
+		gocryptoaes.ErrInvalidKeySize,
+	) {
+		t.Fatalf("SetDefaultKey with a 15-byte key: got %v, want %v", err, gocryptoaes.ErrInvalidKeySize)
+	}
+	if err := SetDefaultKey(make([]byte, 32)); err != nil {
+		t.Fatalf("SetDefaultKey: %v", err)
+	}
+	stored, err := NewEncryptedString("value").Value()
+	if err != nil {
+		t.Fatalf("EncryptedString.Value: %v", err)
+	}
+	var scanned EncryptedString
+	if err = scanned.Scan(stored); err != nil || scanned.String != "value" {
+		t.Fatalf("EncryptedString.Scan = %+v, %v", scanned, err)
+	}
+
+	// An encryptor bound from a context takes precedence over the default one
+	if err = scanned.WithContext(newTestContext(t)).Scan(stored); !errors.Is(
+		err,
+		gocryptoaes.ErrAuthenticationFailed,
+	) {
+		t.Fatalf("EncryptedString.Scan with a context encryptor: got %v, want %v", err, gocryptoaes.ErrAuthenticationFailed)
+	}
+}
@@ -0,0 +1,77 @@
+package gocrypto
+
+import (
+	"errors"
+)
+
+type (
+	// Error is the error returned by the packages of the module, which adds the package and the operation that failed
+	// to an underlying error. The underlying error is usually a sentinel error such as ErrAuthenticationFailed, so it
+	// can be checked with errors.Is, while errors.As gives access to the context
+	Error struct {
+		// Package is the name of the package that returned the error (e.g., "aes")
+		Package string
+
+		// Op is the name of the function or method where the error originated (e.g., "DecryptGCMBytes")
+		Op string
+
+		// Err is the underlying error
+		Err error
+	}
+)
+
+// NewError wraps an error with the package and the operation that failed. If the error is already an *Error, it is
+// returned unchanged, so the context of the operation where it originated is kept
+//
+// Parameters:
+//
+//   - pkg: The name of the package (e.g., "aes")
+//   - op: The name of the operation (e.g., "DecryptGCMBytes")
+//   - err: The error to wrap
+//
+// Returns:
+//
+//   - The wrapped error, or nil if err is nil
+func NewError(pkg, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var target *Error
+	if errors.As(err, &target) {
+		return err
+	}
+	return &Error{Package: pkg, Op: op, Err: err}
+}
+
+// Error returns the error message, prefixed with the package and the operation
+//
+// Returns:
+//
+//   - The error message
+func (e *Error) Error() string {
+	return e.Package + ": " + e.Op + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+//
+// Returns:
+//
+//   - The underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError wraps an error with the operation that failed and the root package name
+//
+// Parameters:
+//
+//   - op: The name of the operation
+//   - err: The error to wrap
+//
+// Returns:
+//
+//   - The wrapped error, or nil if err is nil
+func newError(op string, err error) error {
+	return NewError("gocrypto", op, err)
+}
@@ -5,4 +5,12 @@ import "errors"
 var (
 	ErrFailedToHashPassword = errors.New("failed to hash password")
 	ErrPasswordNotHashed    = errors.New("password is not hashed")
+	ErrUnknownCipher        = errors.New("unknown cipher")
+	ErrNilSecretKey         = errors.New("secret key is nil")
+	ErrSecretKeyDestroyed   = errors.New("secret key was destroyed")
+	ErrInvalidSecretKeySize = errors.New("secret key size must be positive")
+	ErrCiphertextTooShort   = errors.New("ciphertext is too short")
+	ErrAuthenticationFailed = errors.New("message authentication failed")
+	ErrInvalidKeySize       = errors.New("invalid key size")
+	ErrInvalidEncoding      = errors.New("invalid encoding")
 )
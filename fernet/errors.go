@@ -0,0 +1,36 @@
+package fernet
+
+import (
+	"errors"
+	"fmt"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// packageName is the package name reported by the errors of the package
+const packageName = "fernet"
+
+var (
+	ErrInvalidKey   = errors.New("invalid fernet key")
+	ErrInvalidToken = errors.New("invalid fernet token")
+	ErrTokenExpired = errors.New("fernet token has expired")
+	ErrNoKeys       = errors.New("no fernet keys")
+
+	// ErrTokenAuthenticationFailed is returned when the tag of a token does not match, and is both an
+	// ErrInvalidToken and a gocrypto.ErrAuthenticationFailed
+	ErrTokenAuthenticationFailed = fmt.Errorf("%w: %w", ErrInvalidToken, gocrypto.ErrAuthenticationFailed)
+)
+
+// newError wraps an error with the operation that failed and the package name
+//
+// Parameters:
+//
+//   - op: The name of the operation
+//   - err: The error to wrap
+//
+// Returns:
+//
+//   - The wrapped error, or nil if err is nil
+func newError(op string, err error) error {
+	return gocrypto.NewError(packageName, op, err)
+}
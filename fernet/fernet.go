@@ -0,0 +1,322 @@
+package fernet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"time"
+
+	gocryptoaes "github.com/ralvarezdev/go-crypto/aes"
+)
+
+// A Fernet token (https://github.com/fernet/spec) is the base64url encoding of:
+//
+//	version (1 byte, 0x80) || timestamp (8 bytes, big endian seconds) || IV (16 bytes) || cipher text || HMAC (32 bytes)
+//
+// The cipher text is produced with AES-128-CBC and PKCS #7 padding, and the HMAC-SHA256 tag covers everything before
+// it. A key is the base64url encoding of a 16-byte signing key followed by a 16-byte encryption key.
+
+const (
+	// Version is the version byte of the tokens
+	Version = 0x80
+
+	// KeySize is the size in bytes of a decoded key
+	KeySize = 32
+
+	// MaxClockSkew is the maximum time a token timestamp may be in the future
+	MaxClockSkew = 60 * time.Second
+
+	// headerSize is the size in bytes of the version, the timestamp and the IV
+	headerSize = 1 + 8 + aes.BlockSize
+
+	// tagSize is the size in bytes of the HMAC-SHA256 tag
+	tagSize = sha256.Size
+)
+
+type (
+	// Key is a Fernet key
+	Key struct {
+		signingKey    [16]byte
+		encryptionKey [16]byte
+	}
+)
+
+// GenerateKey generates a new random key
+//
+// Returns:
+//
+//   - The key in base64url format
+//   - An error if the random generation failed
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(key), nil
+}
+
+// ParseKey parses a key in base64url format
+//
+// Parameters:
+//
+//   - encodedKey: The key in base64url format
+//
+// Returns:
+//
+//   - A pointer to the key
+//   - ErrInvalidKey if the key is not valid
+func ParseKey(encodedKey string) (*Key, error) {
+	decodedKey, err := base64.URLEncoding.DecodeString(encodedKey)
+	if err != nil || len(decodedKey) != KeySize {
+		return nil, newError("ParseKey", ErrInvalidKey)
+	}
+
+	var key Key
+	copy(key.signingKey[:], decodedKey[:16])
+	copy(key.encryptionKey[:], decodedKey[16:])
+	return &key, nil
+}
+
+// Encode returns the key in base64url format
+//
+// Returns:
+//
+//   - The key in base64url format
+func (k *Key) Encode() string {
+	decodedKey := make([]byte, 0, KeySize)
+	decodedKey = append(decodedKey, k.signingKey[:]...)
+	decodedKey = append(decodedKey, k.encryptionKey[:]...)
+	return base64.URLEncoding.EncodeToString(decodedKey)
+}
+
+// computeTag computes the HMAC-SHA256 tag of a token
+//
+// Parameters:
+//
+//   - signingKey: The signing key
+//   - data: The token up to the tag
+//
+// Returns:
+//
+//   - The tag
+func computeTag(signingKey, data []byte) []byte {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// encrypt encrypts the plain text into a token
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key
+//   - now: The time stored in the token
+//   - iv: The IV
+//
+// Returns:
+//
+//   - The token
+//   - An error if any occurred during the encryption process
+func encrypt(plainText []byte, key *Key, now time.Time, iv []byte) (
+	string,
+	error,
+) {
+	// Create a new AES cipher block with the encryption key
+	block, err := aes.NewCipher(key.encryptionKey[:])
+	if err != nil {
+		return "", err
+	}
+
+	// Pad the plain text
+	padded, err := gocryptoaes.PKCS7Pad(plainText, aes.BlockSize)
+	if err != nil {
+		return "", err
+	}
+
+	// Write the header
+	token := make([]byte, headerSize+len(padded), headerSize+len(padded)+tagSize)
+	token[0] = Version
+	binary.BigEndian.PutUint64(token[1:9], uint64(now.Unix()))
+	copy(token[9:headerSize], iv)
+
+	// Encrypt the padded plain text using the CBC block cipher and append the tag
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(token[headerSize:], padded)
+	token = append(token, computeTag(key.signingKey[:], token)...)
+	return base64.URLEncoding.EncodeToString(token), nil
+}
+
+// Encrypt encrypts the plain text into a token stamped with the current time
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key
+//
+// Returns:
+//
+//   - The token
+//   - An error if any occurred during the encryption process
+func Encrypt(plainText []byte, key *Key) (string, error) {
+	return EncryptAtTime(plainText, key, time.Now())
+}
+
+// EncryptAtTime encrypts the plain text into a token stamped with the given time
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - key: The key
+//   - now: The time stored in the token
+//
+// Returns:
+//
+//   - The token
+//   - An error if any occurred during the encryption process
+func EncryptAtTime(plainText []byte, key *Key, now time.Time) (string, error) {
+	// Check the key
+	if key == nil {
+		return "", newError("EncryptAtTime", ErrInvalidKey)
+	}
+
+	// Create a new random IV
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return "", err
+	}
+	return encrypt(plainText, key, now, iv)
+}
+
+// verify decodes a token and verifies its version and tag
+//
+// Parameters:
+//
+//   - token: The token
+//   - key: The key
+//
+// Returns:
+//
+//   - The decoded token without the tag
+//   - The time stored in the token
+//   - ErrInvalidToken if the token is malformed, or ErrTokenAuthenticationFailed if it was not produced with the key
+func verify(token string, key *Key) ([]byte, time.Time, error) {
+	// Check the key
+	if key == nil {
+		return nil, time.Time{}, newError("Decrypt", ErrInvalidKey)
+	}
+
+	// Decode the token and check its version and length
+	data, err := base64.URLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, time.Time{}, newError("Decrypt", ErrInvalidToken)
+	}
+	if len(data) < headerSize+aes.BlockSize+tagSize || data[0] != Version {
+		return nil, time.Time{}, newError("Decrypt", ErrInvalidToken)
+	}
+	if (len(data)-headerSize-tagSize)%aes.BlockSize != 0 {
+		return nil, time.Time{}, newError("Decrypt", ErrInvalidToken)
+	}
+
+	// Verify the tag
+	tagOffset := len(data) - tagSize
+	data, tag := data[:tagOffset], data[tagOffset:]
+	if !hmac.Equal(tag, computeTag(key.signingKey[:], data)) {
+		return nil, time.Time{}, newError("Decrypt", ErrTokenAuthenticationFailed)
+	}
+
+	timestamp := binary.BigEndian.Uint64(data[1:9])
+	return data, time.Unix(int64(timestamp), 0), nil
+}
+
+// Decrypt decrypts a token, enforcing the TTL against the current time
+//
+// Parameters:
+//
+//   - token: The token
+//   - key: The key
+//   - ttl: The maximum age of the token, or 0 to accept tokens of any age
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - ErrTokenExpired if the token is older than the TTL, ErrInvalidToken if it is not valid (also matching
+//     gocrypto.ErrAuthenticationFailed if its tag does not match), or any other error that occurred during the
+//     decryption process
+func Decrypt(token string, key *Key, ttl time.Duration) ([]byte, error) {
+	return DecryptAtTime(token, key, ttl, time.Now())
+}
+
+// DecryptAtTime decrypts a token, enforcing the TTL against the given time
+//
+// Parameters:
+//
+//   - token: The token
+//   - key: The key
+//   - ttl: The maximum age of the token, or 0 to accept tokens of any age
+//   - now: The time against which the TTL is enforced
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - ErrTokenExpired if the token is older than the TTL, ErrInvalidToken if it is not valid (also matching
+//     gocrypto.ErrAuthenticationFailed if its tag does not match), or any other error that occurred during the
+//     decryption process
+func DecryptAtTime(
+	token string,
+	key *Key,
+	ttl time.Duration,
+	now time.Time,
+) ([]byte, error) {
+	// Verify the token
+	data, timestamp, err := verify(token, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the timestamp
+	if ttl > 0 {
+		if timestamp.Add(ttl).Before(now) {
+			return nil, newError("DecryptAtTime", ErrTokenExpired)
+		}
+		if timestamp.After(now.Add(MaxClockSkew)) {
+			return nil, newError("DecryptAtTime", ErrInvalidToken)
+		}
+	}
+
+	// Create a new AES cipher block with the encryption key
+	block, err := aes.NewCipher(key.encryptionKey[:])
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt the cipher text using the CBC block cipher and remove the padding
+	iv, cipherText := data[9:headerSize], data[headerSize:]
+	padded := make([]byte, len(cipherText))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(padded, cipherText)
+	plainText, err := gocryptoaes.PKCS7Unpad(padded, aes.BlockSize)
+	if err != nil {
+		return nil, newError("DecryptAtTime", ErrInvalidToken)
+	}
+	return plainText, nil
+}
+
+// ExtractTimestamp returns the time stored in a token after verifying it, without decrypting it
+//
+// Parameters:
+//
+//   - token: The token
+//   - key: The key
+//
+// Returns:
+//
+//   - The time stored in the token
+//   - ErrInvalidToken if the token is not valid
+func ExtractTimestamp(token string, key *Key) (time.Time, error) {
+	_, timestamp, err := verify(token, key)
+	return timestamp, err
+}
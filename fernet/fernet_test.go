@@ -0,0 +1,231 @@
+package fernet
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// The test vectors in testdata are the official vectors of the Fernet specification
+// (https://github.com/fernet/spec)
+
+type (
+	// generateVector is a test vector of generate.json
+	generateVector struct {
+		Token  string `json:"token"`
+		Now    string `json:"now"`
+		IV     []int  `json:"iv"`
+		Src    string `json:"src"`
+		Secret string `json:"secret"`
+	}
+
+	// verifyVector is a test vector of verify.json
+	verifyVector struct {
+		Token  string `json:"token"`
+		Now    string `json:"now"`
+		TTLSec int    `json:"ttl_sec"`
+		Src    string `json:"src"`
+		Secret string `json:"secret"`
+	}
+
+	// invalidVector is a test vector of invalid.json
+	invalidVector struct {
+		Desc   string `json:"desc"`
+		Token  string `json:"token"`
+		Now    string `json:"now"`
+		TTLSec int    `json:"ttl_sec"`
+		Secret string `json:"secret"`
+	}
+)
+
+// loadVectors decodes the test vectors of a file in testdata
+func loadVectors(t *testing.T, name string, vectors any) {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("reading %s: %v", name, err)
+	}
+	if err = json.Unmarshal(content, vectors); err != nil {
+		t.Fatalf("decoding %s: %v", name, err)
+	}
+}
+
+// parseVector parses the key and the time of a test vector
+func parseVector(t *testing.T, secret, now string) (*Key, time.Time) {
+	t.Helper()
+
+	key, err := ParseKey(secret)
+	if err != nil {
+		t.Fatalf("ParseKey: %v", err)
+	}
+	parsedNow, err := time.Parse(time.RFC3339, now)
+	if err != nil {
+		t.Fatalf("parsing time %q: %v", now, err)
+	}
+	return key, parsedNow
+}
+
+func TestGenerateVectors(t *testing.T) {
+	var vectors []generateVector
+	loadVectors(t, "generate.json", &vectors)
+
+	for _, vector := range vectors {
+		key, now := parseVector(t, vector.Secret, vector.Now)
+		iv := make([]byte, len(vector.IV))
+		for i, b := range vector.IV {
+			iv[i] = byte(b)
+		}
+
+		token, err := encrypt([]byte(vector.Src), key, now, iv)
+		if err != nil {
+			t.Fatalf("encrypt: %v", err)
+		}
+		if token != vector.Token {
+			t.Fatalf("encrypt = %s, want %s", token, vector.Token)
+		}
+	}
+}
+
+func TestVerifyVectors(t *testing.T) {
+	var vectors []verifyVector
+	loadVectors(t, "verify.json", &vectors)
+
+	for _, vector := range vectors {
+		key, now := parseVector(t, vector.Secret, vector.Now)
+		ttl := time.Duration(vector.TTLSec) * time.Second
+
+		plainText, err := DecryptAtTime(vector.Token, key, ttl, now)
+		if err != nil {
+			t.Fatalf("DecryptAtTime with TTL %v: %v", ttl, err)
+		}
+		if string(plainText) != vector.Src {
+			t.Fatalf("DecryptAtTime = %q, want %q", plainText, vector.Src)
+		}
+	}
+}
+
+func TestInvalidVectors(t *testing.T) {
+	var vectors []invalidVector
+	loadVectors(t, "invalid.json", &vectors)
+
+	for _, vector := range vectors {
+		t.Run(
+			vector.Desc, func(t *testing.T) {
+				key, now := parseVector(t, vector.Secret, vector.Now)
+				ttl := time.Duration(vector.TTLSec) * time.Second
+
+				_, err := DecryptAtTime(vector.Token, key, ttl, now)
+				if !errors.Is(err, ErrInvalidToken) && !errors.Is(
+					err,
+					ErrTokenExpired,
+				) {
+					t.Fatalf("DecryptAtTime: got %v, want an invalid token error", err)
+				}
+
+				// A tag mismatch is also an authentication failure
+				if vector.Desc == "incorrect mac" && !errors.Is(
+					err,
+					gocrypto.ErrAuthenticationFailed,
+				) {
+					t.Fatalf("DecryptAtTime: got %v, want %v", err, gocrypto.ErrAuthenticationFailed)
+				}
+			},
+		)
+	}
+}
+
+func TestMultiFernetRotate(t *testing.T) {
+	var vectors []generateVector
+	loadVectors(t, "generate.json", &vectors)
+	vector := vectors[0]
+	oldKey, now := parseVector(t, vector.Secret, vector.Now)
+
+	encodedKey, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	newKey, err := ParseKey(encodedKey)
+	if err != nil {
+		t.Fatalf("ParseKey: %v", err)
+	}
+	multiFernet, err := NewMultiFernet(newKey, oldKey)
+	if err != nil {
+		t.Fatalf("NewMultiFernet: %v", err)
+	}
+
+	// Rotate the token to the new key, keeping its timestamp
+	rotated, err := multiFernet.Rotate(vector.Token)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	timestamp, err := ExtractTimestamp(rotated, newKey)
+	if err != nil {
+		t.Fatalf("ExtractTimestamp: %v", err)
+	}
+	if !timestamp.Equal(now) {
+		t.Fatalf("ExtractTimestamp = %v, want %v", timestamp, now)
+	}
+	for _, want := range []error{
+		ErrInvalidToken,
+		ErrTokenAuthenticationFailed,
+		gocrypto.ErrAuthenticationFailed,
+	} {
+		if _, err = Decrypt(rotated, oldKey, 0); !errors.Is(err, want) {
+			t.Fatalf("Decrypt with the old key: got %v, want %v", err, want)
+		}
+	}
+	plainText, err := multiFernet.Decrypt(rotated, 0)
+	if err != nil {
+		t.Fatalf("MultiFernet.Decrypt: %v", err)
+	}
+	if string(plainText) != vector.Src {
+		t.Fatalf("MultiFernet.Decrypt = %q, want %q", plainText, vector.Src)
+	}
+}
+
+func TestMultiFernetAuthenticationFailed(t *testing.T) {
+	var vectors []generateVector
+	loadVectors(t, "generate.json", &vectors)
+	vector := vectors[0]
+
+	encodedKey, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	otherKey, err := ParseKey(encodedKey)
+	if err != nil {
+		t.Fatalf("ParseKey: %v", err)
+	}
+	multiFernet, err := NewMultiFernet(otherKey)
+	if err != nil {
+		t.Fatalf("NewMultiFernet: %v", err)
+	}
+
+	// A well-formed token of another key fails authentication
+	if _, err = multiFernet.Decrypt(vector.Token, 0); !errors.Is(
+		err,
+		gocrypto.ErrAuthenticationFailed,
+	) || !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("MultiFernet.Decrypt: got %v, want %v", err, ErrTokenAuthenticationFailed)
+	}
+	if _, err = multiFernet.Rotate(vector.Token); !errors.Is(
+		err,
+		gocrypto.ErrAuthenticationFailed,
+	) || !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("MultiFernet.Rotate: got %v, want %v", err, ErrTokenAuthenticationFailed)
+	}
+
+	// A malformed token is invalid, but was never authenticated
+	if _, err = multiFernet.Decrypt("not a token", 0); !errors.Is(
+		err,
+		ErrInvalidToken,
+	) || errors.Is(err, gocrypto.ErrAuthenticationFailed) {
+		t.Fatalf("MultiFernet.Decrypt of a malformed token: got %v, want %v", err, ErrInvalidToken)
+	}
+}
@@ -0,0 +1,162 @@
+package fernet
+
+import (
+	"errors"
+	"time"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+type (
+	// MultiFernet encrypts with its first key and decrypts with any of its keys, which allows keys to be rotated: a
+	// new key is added at the front, existing tokens are re-encrypted with Rotate, and the old key is then removed
+	MultiFernet struct {
+		keys []*Key
+	}
+)
+
+// NewMultiFernet creates a new MultiFernet
+//
+// Parameters:
+//
+//   - keys: The keys, the first of which is used for encryption
+//
+// Returns:
+//
+//   - A pointer to the MultiFernet
+//   - ErrNoKeys if no keys are given, or ErrInvalidKey if any key is nil
+func NewMultiFernet(keys ...*Key) (*MultiFernet, error) {
+	if len(keys) == 0 {
+		return nil, newError("NewMultiFernet", ErrNoKeys)
+	}
+	for _, key := range keys {
+		if key == nil {
+			return nil, newError("NewMultiFernet", ErrInvalidKey)
+		}
+	}
+	return &MultiFernet{keys: append([]*Key(nil), keys...)}, nil
+}
+
+// Encrypt encrypts the plain text with the first key into a token stamped with the current time
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//
+// Returns:
+//
+//   - The token
+//   - An error if any occurred during the encryption process
+func (m *MultiFernet) Encrypt(plainText []byte) (string, error) {
+	return EncryptAtTime(plainText, m.keys[0], time.Now())
+}
+
+// EncryptAtTime encrypts the plain text with the first key into a token stamped with the given time
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt
+//   - now: The time stored in the token
+//
+// Returns:
+//
+//   - The token
+//   - An error if any occurred during the encryption process
+func (m *MultiFernet) EncryptAtTime(plainText []byte, now time.Time) (
+	string,
+	error,
+) {
+	return EncryptAtTime(plainText, m.keys[0], now)
+}
+
+// Decrypt decrypts a token with the first key that verifies it, enforcing the TTL against the current time
+//
+// Parameters:
+//
+//   - token: The token
+//   - ttl: The maximum age of the token, or 0 to accept tokens of any age
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - ErrTokenExpired if the token is older than the TTL, ErrInvalidToken if no key verifies it (also matching
+//     gocrypto.ErrAuthenticationFailed if the token is well-formed), or any other error that occurred during the
+//     decryption process
+func (m *MultiFernet) Decrypt(token string, ttl time.Duration) ([]byte, error) {
+	return m.DecryptAtTime(token, ttl, time.Now())
+}
+
+// DecryptAtTime decrypts a token with the first key that verifies it, enforcing the TTL against the given time
+//
+// Parameters:
+//
+//   - token: The token
+//   - ttl: The maximum age of the token, or 0 to accept tokens of any age
+//   - now: The time against which the TTL is enforced
+//
+// Returns:
+//
+//   - The decrypted plain text
+//   - ErrTokenExpired if the token is older than the TTL, ErrInvalidToken if no key verifies it (also matching
+//     gocrypto.ErrAuthenticationFailed if the token is well-formed), or any other error that occurred during the
+//     decryption process
+func (m *MultiFernet) DecryptAtTime(
+	token string,
+	ttl time.Duration,
+	now time.Time,
+) ([]byte, error) {
+	var err error
+	for _, key := range m.keys {
+		var plainText []byte
+		if plainText, err = DecryptAtTime(token, key, ttl, now); !errors.Is(
+			err,
+			ErrInvalidToken,
+		) {
+			return plainText, err
+		}
+	}
+
+	// A well-formed token whose tag no key verifies failed authentication
+	if errors.Is(err, gocrypto.ErrAuthenticationFailed) {
+		return nil, newError("MultiFernet.DecryptAtTime", ErrTokenAuthenticationFailed)
+	}
+	return nil, newError("MultiFernet.DecryptAtTime", ErrInvalidToken)
+}
+
+// Rotate re-encrypts a token produced with any of the keys with the first key, preserving its timestamp. The TTL is
+// not enforced, so expired tokens are rotated as well
+//
+// Parameters:
+//
+//   - token: The token
+//
+// Returns:
+//
+//   - The rotated token
+//   - ErrInvalidToken if no key verifies the token (also matching gocrypto.ErrAuthenticationFailed if the token is
+//     well-formed), or any other error that occurred during the process
+func (m *MultiFernet) Rotate(token string) (string, error) {
+	var err error
+	for _, key := range m.keys {
+		var timestamp time.Time
+		timestamp, err = ExtractTimestamp(token, key)
+		if errors.Is(err, ErrInvalidToken) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		var plainText []byte
+		if plainText, err = DecryptAtTime(token, key, 0, timestamp); err != nil {
+			return "", err
+		}
+		return m.EncryptAtTime(plainText, timestamp)
+	}
+
+	// A well-formed token whose tag no key verifies failed authentication
+	if errors.Is(err, gocrypto.ErrAuthenticationFailed) {
+		return "", newError("MultiFernet.Rotate", ErrTokenAuthenticationFailed)
+	}
+	return "", newError("MultiFernet.Rotate", ErrInvalidToken)
+}
@@ -0,0 +1,195 @@
+package fpe
+
+import (
+	"math/big"
+)
+
+// Common alphabets
+const (
+	AlphabetDigits            = "0123456789"
+	AlphabetLowerAlphanumeric = "0123456789abcdefghijklmnopqrstuvwxyz"
+	AlphabetAlphanumeric      = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+const (
+	// minDomainSize is the minimum number of possible inputs required by NIST SP 800-38G Rev. 1
+	minDomainSize = 1000000
+
+	// maxRadix is the maximum radix supported by NIST SP 800-38G
+	maxRadix = 1 << 16
+)
+
+type (
+	// alphabet maps the characters of an alphabet to their numeral values, the radix being the number of characters
+	alphabet struct {
+		characters []rune
+		numerals   map[rune]uint16
+		radix      *big.Int
+	}
+)
+
+// newAlphabet creates a new alphabet
+//
+// Parameters:
+//
+//   - characters: The characters of the alphabet, in order of numeral value
+//
+// Returns:
+//
+//   - A pointer to the alphabet
+//   - ErrInvalidAlphabet if the alphabet has less than 2 or more than 65536 characters, or repeated characters
+func newAlphabet(characters string) (*alphabet, error) {
+	runes := []rune(characters)
+	if len(runes) < 2 || len(runes) > maxRadix {
+		return nil, newError("newAlphabet", ErrInvalidAlphabet)
+	}
+
+	numerals := make(map[rune]uint16, len(runes))
+	for i, character := range runes {
+		if _, ok := numerals[character]; ok {
+			return nil, newError("newAlphabet", ErrInvalidAlphabet)
+		}
+		numerals[character] = uint16(i)
+	}
+	return &alphabet{
+		characters: runes,
+		numerals:   numerals,
+		radix:      big.NewInt(int64(len(runes))),
+	}, nil
+}
+
+// minLength returns the minimum input length for which the domain has at least one million elements
+//
+// Returns:
+//
+//   - The minimum input length, which is at least 2
+func (a *alphabet) minLength() int {
+	length := 0
+	domain := big.NewInt(1)
+	limit := big.NewInt(minDomainSize)
+	for domain.Cmp(limit) < 0 {
+		domain.Mul(domain, a.radix)
+		length++
+	}
+	return max(length, 2)
+}
+
+// toNumerals converts a string to its numerals
+//
+// Parameters:
+//
+//   - text: The string
+//
+// Returns:
+//
+//   - The numerals
+//   - ErrInvalidCharacter if a character is not in the alphabet
+func (a *alphabet) toNumerals(text string) ([]uint16, error) {
+	numerals := make([]uint16, 0, len(text))
+	for _, character := range text {
+		numeral, ok := a.numerals[character]
+		if !ok {
+			return nil, newError("alphabet.toNumerals", ErrInvalidCharacter)
+		}
+		numerals = append(numerals, numeral)
+	}
+	return numerals, nil
+}
+
+// toString converts numerals to a string
+//
+// Parameters:
+//
+//   - numerals: The numerals
+//
+// Returns:
+//
+//   - The string
+func (a *alphabet) toString(numerals []uint16) string {
+	runes := make([]rune, len(numerals))
+	for i, numeral := range numerals {
+		runes[i] = a.characters[numeral]
+	}
+	return string(runes)
+}
+
+// num returns the number represented by the numerals, most significant first (NUM_radix)
+//
+// Parameters:
+//
+//   - numerals: The numerals
+//
+// Returns:
+//
+//   - The number
+func (a *alphabet) num(numerals []uint16) *big.Int {
+	number := new(big.Int)
+	digit := new(big.Int)
+	for _, numeral := range numerals {
+		number.Mul(number, a.radix)
+		number.Add(number, digit.SetUint64(uint64(numeral)))
+	}
+	return number
+}
+
+// numReversed returns the number represented by the numerals, least significant first (NUM_radix(REV(X)))
+//
+// Parameters:
+//
+//   - numerals: The numerals
+//
+// Returns:
+//
+//   - The number
+func (a *alphabet) numReversed(numerals []uint16) *big.Int {
+	number := new(big.Int)
+	digit := new(big.Int)
+	for i := len(numerals) - 1; i >= 0; i-- {
+		number.Mul(number, a.radix)
+		number.Add(number, digit.SetUint64(uint64(numerals[i])))
+	}
+	return number
+}
+
+// str writes the number into the numerals, most significant first (STR^m_radix). The number must be lower than
+// radix^len(numerals), and is consumed
+//
+// Parameters:
+//
+//   - number: The number
+//   - numerals: The destination numerals
+func (a *alphabet) str(number *big.Int, numerals []uint16) {
+	digit := new(big.Int)
+	for i := len(numerals) - 1; i >= 0; i-- {
+		number.QuoRem(number, a.radix, digit)
+		numerals[i] = uint16(digit.Uint64())
+	}
+}
+
+// strReversed writes the number into the numerals, least significant first (REV(STR^m_radix)). The number must be
+// lower than radix^len(numerals), and is consumed
+//
+// Parameters:
+//
+//   - number: The number
+//   - numerals: The destination numerals
+func (a *alphabet) strReversed(number *big.Int, numerals []uint16) {
+	digit := new(big.Int)
+	for i := range numerals {
+		number.QuoRem(number, a.radix, digit)
+		numerals[i] = uint16(digit.Uint64())
+	}
+}
+
+// pow returns radix^exponent
+//
+// Parameters:
+//
+//   - exponent: The exponent
+//
+// Returns:
+//
+//   - The power
+func (a *alphabet) pow(exponent int) *big.Int {
+	return new(big.Int).Exp(a.radix, big.NewInt(int64(exponent)), nil)
+}
@@ -0,0 +1,32 @@
+package fpe
+
+import (
+	"errors"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// packageName is the package name reported by the errors of the package
+const packageName = "fpe"
+
+var (
+	ErrInvalidAlphabet    = errors.New("alphabet must have between 2 and 65536 unique characters")
+	ErrInvalidCharacter   = errors.New("character is not in the alphabet")
+	ErrInvalidLength      = errors.New("input length is out of the supported domain")
+	ErrInvalidTweakLength = errors.New("invalid tweak length")
+	ErrInvalidKeySize     = gocrypto.ErrInvalidKeySize
+)
+
+// newError wraps an error with the operation that failed and the package name
+//
+// Parameters:
+//
+//   - op: The name of the operation
+//   - err: The error to wrap
+//
+// Returns:
+//
+//   - The wrapped error, or nil if err is nil
+func newError(op string, err error) error {
+	return gocrypto.NewError(packageName, op, err)
+}
@@ -0,0 +1,243 @@
+package fpe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"math/big"
+)
+
+const (
+	// ff1Rounds is the number of Feistel rounds of FF1
+	ff1Rounds = 10
+
+	// ff1MaxLength is the maximum input length of FF1
+	ff1MaxLength = 1<<32 - 1
+)
+
+type (
+	// FF1 is the FF1 format-preserving encryption mode of NIST SP 800-38G with the AES block cipher. It is safe for
+	// concurrent use
+	FF1 struct {
+		block          cipher.Block
+		alphabet       *alphabet
+		minLength      int
+		maxTweakLength int
+	}
+)
+
+// NewFF1 creates a new FF1 cipher
+//
+// Parameters:
+//
+//   - key: The AES key (must be 16, 24 or 32 bytes long)
+//   - characters: The alphabet, whose length is the radix, in order of numeral value (e.g., AlphabetDigits)
+//   - maxTweakLength: The maximum tweak length in bytes
+//
+// Returns:
+//
+//   - A pointer to the FF1 cipher
+//   - An error if the key or the alphabet is invalid
+func NewFF1(key []byte, characters string, maxTweakLength int) (*FF1, error) {
+	// Create a new AES cipher block with the given key
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, newError("NewFF1", ErrInvalidKeySize)
+	}
+
+	// Create the alphabet
+	alphabet, err := newAlphabet(characters)
+	if err != nil {
+		return nil, err
+	}
+	if maxTweakLength < 0 || uint64(maxTweakLength) > ff1MaxLength {
+		return nil, newError("NewFF1", ErrInvalidTweakLength)
+	}
+
+	return &FF1{
+		block:          block,
+		alphabet:       alphabet,
+		minLength:      alphabet.minLength(),
+		maxTweakLength: maxTweakLength,
+	}, nil
+}
+
+// prf applies the CBC-MAC based pseudorandom function of FF1 with a zero IV
+//
+// Parameters:
+//
+//   - data: The input, whose length is a multiple of the AES block size
+//
+// Returns:
+//
+//   - The output block
+func (f *FF1) prf(data []byte) [aes.BlockSize]byte {
+	var y [aes.BlockSize]byte
+	for offset := 0; offset < len(data); offset += aes.BlockSize {
+		for i := range y {
+			y[i] ^= data[offset+i]
+		}
+		f.block.Encrypt(y[:], y[:])
+	}
+	return y
+}
+
+// round computes the round value y of FF1
+//
+// Parameters:
+//
+//   - pq: The P block followed by the Q block, whose last b+1 bytes are overwritten with the round number and the
+//     numeral value
+//   - round: The round number
+//   - number: The numeral value of the half that is not modified
+//   - b: The byte length of the numeral value
+//   - d: The byte length of the round output
+//
+// Returns:
+//
+//   - The round value
+func (f *FF1) round(
+	pq []byte,
+	round int,
+	number *big.Int,
+	b, d int,
+) *big.Int {
+	// Q = T || [0]^((-t-b-1) mod 16) || [i]^1 || [NUM(B)]^b
+	pq[len(pq)-b-1] = byte(round)
+	number.FillBytes(pq[len(pq)-b:])
+
+	// R = PRF(P || Q)
+	r := f.prf(pq)
+
+	// S = R || CIPH(R ^ [1]^16) || CIPH(R ^ [2]^16) || ..., truncated to d bytes
+	s := make([]byte, 0, (d+aes.BlockSize-1)/aes.BlockSize*aes.BlockSize)
+	s = append(s, r[:]...)
+	for j := 1; len(s) < d; j++ {
+		var block [aes.BlockSize]byte
+		binary.BigEndian.PutUint64(block[8:], uint64(j))
+		for i := range block {
+			block[i] ^= r[i]
+		}
+		f.block.Encrypt(block[:], block[:])
+		s = append(s, block[:]...)
+	}
+	return new(big.Int).SetBytes(s[:d])
+}
+
+// crypt encrypts or decrypts the numerals with FF1
+//
+// Parameters:
+//
+//   - text: The plain text or the cipher text
+//   - tweak: The tweak
+//   - encrypt: True to encrypt, false to decrypt
+//
+// Returns:
+//
+//   - The cipher text or the plain text
+//   - An error if the text or the tweak is invalid
+func (f *FF1) crypt(text string, tweak []byte, encrypt bool) (string, error) {
+	// Check the tweak and the text
+	if len(tweak) > f.maxTweakLength {
+		return "", newError("FF1.crypt", ErrInvalidTweakLength)
+	}
+	numerals, err := f.alphabet.toNumerals(text)
+	if err != nil {
+		return "", err
+	}
+	n := len(numerals)
+	if n < f.minLength || uint64(n) > ff1MaxLength {
+		return "", newError("FF1.crypt", ErrInvalidLength)
+	}
+
+	// Split the text
+	u := n / 2
+	v := n - u
+	a, b := numerals[:u], numerals[u:]
+
+	// Get the byte lengths of the numeral values and of the round outputs
+	maxNumber := f.alphabet.pow(v)
+	byteLength := (maxNumber.Sub(maxNumber, big.NewInt(1)).BitLen() + 7) / 8
+	d := 4*((byteLength+3)/4) + 4
+
+	// P = [1]^1 || [2]^1 || [1]^1 || [radix]^3 || [10]^1 || [u mod 256]^1 || [n]^4 || [t]^4, followed by the tweak
+	// and the zero padding of Q
+	t := len(tweak)
+	padding := ((-t-byteLength-1)%16 + 16) % 16
+	pq := make([]byte, aes.BlockSize+t+padding+1+byteLength)
+	pq[0], pq[1], pq[2] = 1, 2, 1
+	radix := len(f.alphabet.characters)
+	pq[3], pq[4], pq[5] = byte(radix>>16), byte(radix>>8), byte(radix)
+	pq[6], pq[7] = ff1Rounds, byte(u)
+	binary.BigEndian.PutUint32(pq[8:12], uint32(n))
+	binary.BigEndian.PutUint32(pq[12:16], uint32(t))
+	copy(pq[aes.BlockSize:], tweak)
+
+	// Get the moduli of both halves
+	modU, modV := f.alphabet.pow(u), f.alphabet.pow(v)
+
+	// Apply the Feistel rounds on copies of the halves, with A and B of lengths u and v
+	a = append([]uint16(nil), a...)
+	b = append([]uint16(nil), b...)
+	for i := 0; i < ff1Rounds; i++ {
+		round := i
+		if !encrypt {
+			round = ff1Rounds - 1 - i
+		}
+		modulus := modV
+		m := v
+		if round%2 == 0 {
+			modulus, m = modU, u
+		}
+
+		if encrypt {
+			// c = (NUM(A) + y) mod radix^m, A = B, B = STR(c)
+			y := f.round(pq, round, f.alphabet.num(b), byteLength, d)
+			c := y.Add(y, f.alphabet.num(a))
+			c.Mod(c, modulus)
+			next := make([]uint16, m)
+			f.alphabet.str(c, next)
+			a, b = b, next
+		} else {
+			// c = (NUM(B) - y) mod radix^m, B = A, A = STR(c)
+			y := f.round(pq, round, f.alphabet.num(a), byteLength, d)
+			c := new(big.Int).Sub(f.alphabet.num(b), y)
+			c.Mod(c, modulus)
+			next := make([]uint16, m)
+			f.alphabet.str(c, next)
+			a, b = next, a
+		}
+	}
+	return f.alphabet.toString(append(a, b...)), nil
+}
+
+// Encrypt encrypts a string whose characters are all in the alphabet, producing a string of the same length and
+// alphabet
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt, long enough for its domain to have at least one million elements
+//   - tweak: The tweak, which may be empty and must be the same for decryption
+//
+// Returns:
+//
+//   - The cipher text
+//   - An error if the plain text or the tweak is invalid
+func (f *FF1) Encrypt(plainText string, tweak []byte) (string, error) {
+	return f.crypt(plainText, tweak, true)
+}
+
+// Decrypt decrypts a string produced by Encrypt
+//
+// Parameters:
+//
+//   - cipherText: The cipher text to decrypt
+//   - tweak: The tweak used for encryption
+//
+// Returns:
+//
+//   - The plain text
+//   - An error if the cipher text or the tweak is invalid
+func (f *FF1) Decrypt(cipherText string, tweak []byte) (string, error) {
+	return f.crypt(cipherText, tweak, false)
+}
@@ -0,0 +1,161 @@
+package fpe
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// mustDecodeHex decodes a hexadecimal test vector, failing the test if it is not valid
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid test vector %q: %v", s, err)
+	}
+	return data
+}
+
+// ff1Vectors are the FF1 samples of NIST SP 800-38G
+var ff1Vectors = []struct {
+	name       string
+	key        string
+	tweak      string
+	characters string
+	plainText  string
+	cipherText string
+}{
+	{
+		name:       "sample 1",
+		key:        "2B7E151628AED2A6ABF7158809CF4F3C",
+		characters: AlphabetDigits,
+		plainText:  "0123456789",
+		cipherText: "2433477484",
+	},
+	{
+		name:       "sample 2",
+		key:        "2B7E151628AED2A6ABF7158809CF4F3C",
+		tweak:      "39383736353433323130",
+		characters: AlphabetDigits,
+		plainText:  "0123456789",
+		cipherText: "6124200773",
+	},
+	{
+		name:       "sample 3",
+		key:        "2B7E151628AED2A6ABF7158809CF4F3C",
+		tweak:      "3737373770717273373737",
+		characters: AlphabetLowerAlphanumeric,
+		plainText:  "0123456789abcdefghi",
+		cipherText: "a9tv40mll9kdu509eum",
+	},
+	{
+		name:       "sample 4",
+		key:        "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F",
+		characters: AlphabetDigits,
+		plainText:  "0123456789",
+		cipherText: "2830668132",
+	},
+	{
+		name:       "sample 5",
+		key:        "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F",
+		tweak:      "39383736353433323130",
+		characters: AlphabetDigits,
+		plainText:  "0123456789",
+		cipherText: "2496655549",
+	},
+	{
+		name:       "sample 6",
+		key:        "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F",
+		tweak:      "3737373770717273373737",
+		characters: AlphabetLowerAlphanumeric,
+		plainText:  "0123456789abcdefghi",
+		cipherText: "xbj3kv35jrawxv32ysr",
+	},
+	{
+		name:       "sample 7",
+		key:        "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94",
+		characters: AlphabetDigits,
+		plainText:  "0123456789",
+		cipherText: "6657667009",
+	},
+	{
+		name:       "sample 8",
+		key:        "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94",
+		tweak:      "39383736353433323130",
+		characters: AlphabetDigits,
+		plainText:  "0123456789",
+		cipherText: "1001623463",
+	},
+	{
+		name:       "sample 9",
+		key:        "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94",
+		tweak:      "3737373770717273373737",
+		characters: AlphabetLowerAlphanumeric,
+		plainText:  "0123456789abcdefghi",
+		cipherText: "xs8a0azh2avyalyzuwd",
+	},
+}
+
+func TestFF1Vectors(t *testing.T) {
+	for _, vector := range ff1Vectors {
+		t.Run(
+			vector.name, func(t *testing.T) {
+				ff1, err := NewFF1(
+					mustDecodeHex(t, vector.key),
+					vector.characters,
+					16,
+				)
+				if err != nil {
+					t.Fatalf("NewFF1: %v", err)
+				}
+				tweak := mustDecodeHex(t, vector.tweak)
+
+				cipherText, err := ff1.Encrypt(vector.plainText, tweak)
+				if err != nil {
+					t.Fatalf("Encrypt: %v", err)
+				}
+				if cipherText != vector.cipherText {
+					t.Fatalf("Encrypt = %s, want %s", cipherText, vector.cipherText)
+				}
+
+				plainText, err := ff1.Decrypt(vector.cipherText, tweak)
+				if err != nil {
+					t.Fatalf("Decrypt: %v", err)
+				}
+				if plainText != vector.plainText {
+					t.Fatalf("Decrypt = %s, want %s", plainText, vector.plainText)
+				}
+			},
+		)
+	}
+}
+
+func TestFF1InvalidInput(t *testing.T) {
+	ff1, err := NewFF1(mustDecodeHex(t, ff1Vectors[0].key), AlphabetDigits, 4)
+	if err != nil {
+		t.Fatalf("NewFF1: %v", err)
+	}
+
+	if _, err = ff1.Encrypt("12345", nil); !errors.Is(err, ErrInvalidLength) {
+		t.Fatalf("Encrypt with a short input: got %v, want %v", err, ErrInvalidLength)
+	}
+	if _, err = ff1.Encrypt("12345a7890", nil); !errors.Is(
+		err,
+		ErrInvalidCharacter,
+	) {
+		t.Fatalf("Encrypt with an invalid character: got %v, want %v", err, ErrInvalidCharacter)
+	}
+	if _, err = ff1.Encrypt("0123456789", make([]byte, 5)); !errors.Is(
+		err,
+		ErrInvalidTweakLength,
+	) {
+		t.Fatalf("Encrypt with a long tweak: got %v, want %v", err, ErrInvalidTweakLength)
+	}
+	if _, err = NewFF1(make([]byte, 20), AlphabetDigits, 0); !errors.Is(
+		err,
+		ErrInvalidKeySize,
+	) {
+		t.Fatalf("NewFF1 with an invalid key: got %v, want %v", err, ErrInvalidKeySize)
+	}
+}
@@ -0,0 +1,237 @@
+package fpe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"math/big"
+	"slices"
+)
+
+const (
+	// FF31TweakSize is the size in bytes of the FF3-1 tweak
+	FF31TweakSize = 7
+
+	// ff3Rounds is the number of Feistel rounds of FF3-1
+	ff3Rounds = 8
+
+	// ff3HalfTweakSize is the size in bytes of each half of the expanded tweak
+	ff3HalfTweakSize = 4
+)
+
+type (
+	// FF31 is the FF3-1 format-preserving encryption mode of NIST SP 800-38G Rev. 1 with the AES block cipher. It is
+	// safe for concurrent use
+	FF31 struct {
+		block     cipher.Block
+		alphabet  *alphabet
+		minLength int
+		maxLength int
+	}
+)
+
+// NewFF31 creates a new FF3-1 cipher
+//
+// Parameters:
+//
+//   - key: The AES key (must be 16, 24 or 32 bytes long)
+//   - characters: The alphabet, whose length is the radix, in order of numeral value (e.g., AlphabetDigits)
+//
+// Returns:
+//
+//   - A pointer to the FF3-1 cipher
+//   - An error if the key or the alphabet is invalid
+func NewFF31(key []byte, characters string) (*FF31, error) {
+	// FF3-1 uses the AES block cipher with the byte-reversed key
+	block, err := aes.NewCipher(reversed(key))
+	if err != nil {
+		return nil, newError("NewFF31", ErrInvalidKeySize)
+	}
+
+	// Create the alphabet
+	alphabet, err := newAlphabet(characters)
+	if err != nil {
+		return nil, err
+	}
+
+	// The maximum length is 2 * floor(log_radix(2^96))
+	halfLength := 0
+	limit := new(big.Int).Lsh(big.NewInt(1), 96)
+	for domain := new(big.Int).Set(alphabet.radix); domain.Cmp(limit) <= 0; domain.Mul(
+		domain,
+		alphabet.radix,
+	) {
+		halfLength++
+	}
+
+	return &FF31{
+		block:     block,
+		alphabet:  alphabet,
+		minLength: alphabet.minLength(),
+		maxLength: 2 * halfLength,
+	}, nil
+}
+
+// reversed returns a reversed copy of the bytes (REVB)
+//
+// Parameters:
+//
+//   - data: The bytes
+//
+// Returns:
+//
+//   - The reversed copy
+func reversed(data []byte) []byte {
+	reversedData := slices.Clone(data)
+	slices.Reverse(reversedData)
+	return reversedData
+}
+
+// expandTweak splits the 56-bit FF3-1 tweak into its 32-bit left and right halves
+//
+// Parameters:
+//
+//   - tweak: The 56-bit tweak
+//
+// Returns:
+//
+//   - The left half, T[0..27] || 0^4
+//   - The right half, T[32..55] || T[28..31] || 0^4
+func expandTweak(tweak []byte) ([ff3HalfTweakSize]byte, [ff3HalfTweakSize]byte) {
+	left := [ff3HalfTweakSize]byte{tweak[0], tweak[1], tweak[2], tweak[3] & 0xf0}
+	right := [ff3HalfTweakSize]byte{tweak[4], tweak[5], tweak[6], tweak[3] << 4}
+	return left, right
+}
+
+// crypt encrypts or decrypts the numerals with the FF3 Feistel rounds
+//
+// Parameters:
+//
+//   - numerals: The plain text or the cipher text numerals
+//   - left: The left half of the tweak
+//   - right: The right half of the tweak
+//   - encrypt: True to encrypt, false to decrypt
+//
+// Returns:
+//
+//   - The cipher text or the plain text numerals
+func (f *FF31) crypt(
+	numerals []uint16,
+	left, right [ff3HalfTweakSize]byte,
+	encrypt bool,
+) []uint16 {
+	// Split the text, with A and B of lengths u and v
+	n := len(numerals)
+	u := (n + 1) / 2
+	v := n - u
+	a := slices.Clone(numerals[:u])
+	b := slices.Clone(numerals[u:])
+
+	// Get the moduli of both halves
+	modU, modV := f.alphabet.pow(u), f.alphabet.pow(v)
+
+	var p [aes.BlockSize]byte
+	for i := 0; i < ff3Rounds; i++ {
+		round := i
+		if !encrypt {
+			round = ff3Rounds - 1 - i
+		}
+		modulus, m, w := modV, v, left
+		if round%2 == 0 {
+			modulus, m, w = modU, u, right
+		}
+
+		// P = (W ^ [i]^4) || [NUM(REV(B))]^12, where B is the half that is not modified
+		unmodified := b
+		if !encrypt {
+			unmodified = a
+		}
+		copy(p[:ff3HalfTweakSize], w[:])
+		p[3] ^= byte(round)
+		f.alphabet.numReversed(unmodified).FillBytes(p[ff3HalfTweakSize:])
+
+		// S = REVB(CIPH(REVB(P))), y = NUM(S)
+		slices.Reverse(p[:])
+		f.block.Encrypt(p[:], p[:])
+		slices.Reverse(p[:])
+		y := new(big.Int).SetBytes(p[:])
+
+		next := make([]uint16, m)
+		if encrypt {
+			// c = (NUM(REV(A)) + y) mod radix^m, A = B, B = REV(STR(c))
+			c := y.Add(y, f.alphabet.numReversed(a))
+			c.Mod(c, modulus)
+			f.alphabet.strReversed(c, next)
+			a, b = b, next
+		} else {
+			// c = (NUM(REV(B)) - y) mod radix^m, B = A, A = REV(STR(c))
+			c := new(big.Int).Sub(f.alphabet.numReversed(b), y)
+			c.Mod(c, modulus)
+			f.alphabet.strReversed(c, next)
+			a, b = next, a
+		}
+	}
+	return append(a, b...)
+}
+
+// process checks the inputs and encrypts or decrypts a string
+//
+// Parameters:
+//
+//   - text: The plain text or the cipher text
+//   - tweak: The 56-bit tweak
+//   - encrypt: True to encrypt, false to decrypt
+//
+// Returns:
+//
+//   - The cipher text or the plain text
+//   - An error if the text or the tweak is invalid
+func (f *FF31) process(text string, tweak []byte, encrypt bool) (
+	string,
+	error,
+) {
+	// Check the tweak and the text
+	if len(tweak) != FF31TweakSize {
+		return "", newError("FF31.process", ErrInvalidTweakLength)
+	}
+	numerals, err := f.alphabet.toNumerals(text)
+	if err != nil {
+		return "", err
+	}
+	if len(numerals) < f.minLength || len(numerals) > f.maxLength {
+		return "", newError("FF31.process", ErrInvalidLength)
+	}
+
+	left, right := expandTweak(tweak)
+	return f.alphabet.toString(f.crypt(numerals, left, right, encrypt)), nil
+}
+
+// Encrypt encrypts a string whose characters are all in the alphabet, producing a string of the same length and
+// alphabet
+//
+// Parameters:
+//
+//   - plainText: The plain text to encrypt, long enough for its domain to have at least one million elements
+//   - tweak: The 56-bit tweak, which must be the same for decryption
+//
+// Returns:
+//
+//   - The cipher text
+//   - An error if the plain text or the tweak is invalid
+func (f *FF31) Encrypt(plainText string, tweak []byte) (string, error) {
+	return f.process(plainText, tweak, true)
+}
+
+// Decrypt decrypts a string produced by Encrypt
+//
+// Parameters:
+//
+//   - cipherText: The cipher text to decrypt
+//   - tweak: The 56-bit tweak used for encryption
+//
+// Returns:
+//
+//   - The plain text
+//   - An error if the cipher text or the tweak is invalid
+func (f *FF31) Decrypt(cipherText string, tweak []byte) (string, error) {
+	return f.process(cipherText, tweak, false)
+}
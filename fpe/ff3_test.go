@@ -0,0 +1,190 @@
+package fpe
+
+import (
+	"errors"
+	"testing"
+)
+
+// ff31Vectors are FF3-1 sample vectors of the NIST ACVP test suite, with 56-bit tweaks
+var ff31Vectors = []struct {
+	name       string
+	key        string
+	tweak      string
+	characters string
+	plainText  string
+	cipherText string
+}{
+	{
+		name:       "radix 10, 18 numerals",
+		key:        "EF4359D8D580AA4F7F036D6F04FC6A94",
+		tweak:      "D8E7920AFA330A",
+		characters: AlphabetDigits,
+		plainText:  "890121234567890000",
+		cipherText: "477064185124354662",
+	},
+	{
+		name:       "radix 10, 10 numerals",
+		key:        "2DE79D232DF5585D68CE47882AE256D6",
+		tweak:      "CBD09280979564",
+		characters: AlphabetDigits,
+		plainText:  "3992520240",
+		cipherText: "8901801106",
+	},
+	{
+		name:       "radix 10, 56 numerals",
+		key:        "01C63017111438F7FC8E24EB16C71AB5",
+		tweak:      "C4E822DCD09F27",
+		characters: AlphabetDigits,
+		plainText:  "60761757463116869318437658042297305934914824457484538562",
+		cipherText: "35637144092473838892796702739628394376915177448290847293",
+	},
+	{
+		name:       "radix 26, 10 numerals",
+		key:        "718385E6542534604419E83CE387A437",
+		tweak:      "B6F35084FA90E1",
+		characters: "abcdefghijklmnopqrstuvwxyz",
+		plainText:  "wfmwlrorcd",
+		cipherText: "ywowehycyd",
+	},
+	{
+		name:       "radix 26, 40 numerals",
+		key:        "DB602DFF22ED7E84C8D8C865A941A238",
+		tweak:      "EBEFD63BCC2083",
+		characters: "abcdefghijklmnopqrstuvwxyz",
+		plainText:  "kkuomenbzqvggfbteqdyanwpmhzdmoicekiihkrm",
+		cipherText: "belcfahcwwytwrckieymthabgjjfkxtxauipmjja",
+	},
+}
+
+// ff3Vectors are FF3 samples of NIST SP 800-38G with 64-bit tweaks, which exercise the Feistel rounds shared with
+// FF3-1 through the expanded tweak halves
+var ff3Vectors = []struct {
+	name       string
+	tweak      string
+	plainText  string
+	cipherText string
+}{
+	{
+		name:       "sample 1",
+		tweak:      "D8E7920AFA330A73",
+		plainText:  "890121234567890000",
+		cipherText: "750918814058654607",
+	},
+	{
+		name:       "sample 2",
+		tweak:      "9A768A92F60E12D8",
+		plainText:  "890121234567890000",
+		cipherText: "018989839189395384",
+	},
+	{
+		name:       "sample 3",
+		tweak:      "D8E7920AFA330A73",
+		plainText:  "89012123456789000000789000000",
+		cipherText: "48598367162252569629397416226",
+	},
+	{
+		name:       "sample 4",
+		tweak:      "0000000000000000",
+		plainText:  "89012123456789000000789000000",
+		cipherText: "34695224821734535122613701434",
+	},
+}
+
+func TestFF31Vectors(t *testing.T) {
+	for _, vector := range ff31Vectors {
+		t.Run(
+			vector.name, func(t *testing.T) {
+				ff31, err := NewFF31(
+					mustDecodeHex(t, vector.key),
+					vector.characters,
+				)
+				if err != nil {
+					t.Fatalf("NewFF31: %v", err)
+				}
+				tweak := mustDecodeHex(t, vector.tweak)
+
+				cipherText, err := ff31.Encrypt(vector.plainText, tweak)
+				if err != nil {
+					t.Fatalf("Encrypt: %v", err)
+				}
+				if cipherText != vector.cipherText {
+					t.Fatalf("Encrypt = %s, want %s", cipherText, vector.cipherText)
+				}
+
+				plainText, err := ff31.Decrypt(vector.cipherText, tweak)
+				if err != nil {
+					t.Fatalf("Decrypt: %v", err)
+				}
+				if plainText != vector.plainText {
+					t.Fatalf("Decrypt = %s, want %s", plainText, vector.plainText)
+				}
+			},
+		)
+	}
+}
+
+func TestFF3Vectors(t *testing.T) {
+	ff31, err := NewFF31(
+		mustDecodeHex(t, "EF4359D8D580AA4F7F036D6F04FC6A94"),
+		AlphabetDigits,
+	)
+	if err != nil {
+		t.Fatalf("NewFF31: %v", err)
+	}
+
+	for _, vector := range ff3Vectors {
+		t.Run(
+			vector.name, func(t *testing.T) {
+				tweak := mustDecodeHex(t, vector.tweak)
+				left := [ff3HalfTweakSize]byte(tweak[:ff3HalfTweakSize])
+				right := [ff3HalfTweakSize]byte(tweak[ff3HalfTweakSize:])
+
+				numerals, err := ff31.alphabet.toNumerals(vector.plainText)
+				if err != nil {
+					t.Fatalf("toNumerals: %v", err)
+				}
+				cipherText := ff31.alphabet.toString(
+					ff31.crypt(numerals, left, right, true),
+				)
+				if cipherText != vector.cipherText {
+					t.Fatalf("crypt = %s, want %s", cipherText, vector.cipherText)
+				}
+
+				numerals, err = ff31.alphabet.toNumerals(vector.cipherText)
+				if err != nil {
+					t.Fatalf("toNumerals: %v", err)
+				}
+				plainText := ff31.alphabet.toString(
+					ff31.crypt(numerals, left, right, false),
+				)
+				if plainText != vector.plainText {
+					t.Fatalf("crypt = %s, want %s", plainText, vector.plainText)
+				}
+			},
+		)
+	}
+}
+
+func TestFF31InvalidInput(t *testing.T) {
+	ff31, err := NewFF31(mustDecodeHex(t, ff31Vectors[0].key), AlphabetDigits)
+	if err != nil {
+		t.Fatalf("NewFF31: %v", err)
+	}
+	tweak := mustDecodeHex(t, ff31Vectors[0].tweak)
+
+	if _, err = ff31.Encrypt("890121234567890000", tweak[:6]); !errors.Is(
+		err,
+		ErrInvalidTweakLength,
+	) {
+		t.Fatalf("Encrypt with a short tweak: got %v, want %v", err, ErrInvalidTweakLength)
+	}
+	if _, err = ff31.Encrypt("12345", tweak); !errors.Is(err, ErrInvalidLength) {
+		t.Fatalf("Encrypt with a short input: got %v, want %v", err, ErrInvalidLength)
+	}
+	if _, err = ff31.Encrypt("8901212345678900x0", tweak); !errors.Is(
+		err,
+		ErrInvalidCharacter,
+	) {
+		t.Fatalf("Encrypt with an invalid character: got %v, want %v", err, ErrInvalidCharacter)
+	}
+}
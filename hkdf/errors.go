@@ -0,0 +1,31 @@
+package hkdf
+
+import (
+	"errors"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// packageName is the package name reported by the errors of the package
+const packageName = "hkdf"
+
+var (
+	ErrInvalidKeyLength  = errors.New("invalid hkdf key length")
+	ErrMasterKeyTooShort = errors.New("master key must be at least 16 bytes long")
+	ErrEmptySubkeyName   = errors.New("subkey name is empty")
+	ErrSubkeyNameTooLong = errors.New("subkey name is too long")
+)
+
+// newError wraps an error with the operation that failed and the package name
+//
+// Parameters:
+//
+//   - op: The name of the operation
+//   - err: The error to wrap
+//
+// Returns:
+//
+//   - The wrapped error, or nil if err is nil
+func newError(op string, err error) error {
+	return gocrypto.NewError(packageName, op, err)
+}
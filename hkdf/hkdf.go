@@ -0,0 +1,78 @@
+package hkdf
+
+import (
+	"crypto/hkdf"
+	"hash"
+)
+
+// The functions are thin wrappers around the standard library crypto/hkdf package, which the aes package also uses to
+// derive its keys, so there is a single HKDF implementation in the module.
+
+// Extract extracts a pseudorandom key from the secret and the salt, as the HKDF-Extract step of RFC 5869
+//
+// Parameters:
+//
+//   - hashFn: the hash function to use (e.g., sha256.New)
+//   - secret: the input keying material, which must already have high entropy (use pbkdf2 for passwords)
+//   - salt: the optional salt (may be nil, in which case a string of zeros of the hash length is used)
+//
+// Returns:
+//
+//   - the pseudorandom key, as long as the hash output
+//   - an error if the extraction failed
+func Extract(hashFn func() hash.Hash, secret, salt []byte) ([]byte, error) {
+	return hkdf.Extract(hashFn, secret, salt)
+}
+
+// Expand expands a pseudorandom key into the output keying material for the given context, as the HKDF-Expand step
+// of RFC 5869
+//
+// Parameters:
+//
+//   - hashFn: the hash function to use (e.g., sha256.New)
+//   - pseudorandomKey: the pseudorandom key, usually the output of Extract
+//   - info: the context and application specific label, which makes keys derived for different purposes independent
+//   - length: the length of the output keying material in bytes (at most 255 times the hash output length)
+//
+// Returns:
+//
+//   - the output keying material
+//   - ErrInvalidKeyLength if the length is not valid, or any other error that occurred during the expansion
+func Expand(
+	hashFn func() hash.Hash,
+	pseudorandomKey, info []byte,
+	length int,
+) ([]byte, error) {
+	if length <= 0 || length > 255*hashFn().Size() {
+		return nil, newError("Expand", ErrInvalidKeyLength)
+	}
+
+	return hkdf.Expand(hashFn, pseudorandomKey, string(info), length)
+}
+
+// DeriveKey derives a key from the secret by applying Extract and then Expand
+//
+// Parameters:
+//
+//   - hashFn: the hash function to use (e.g., sha256.New)
+//   - secret: the input keying material, which must already have high entropy (use pbkdf2 for passwords)
+//   - salt: the optional salt (may be nil)
+//   - info: the context and application specific label
+//   - length: the length of the derived key in bytes (at most 255 times the hash output length)
+//
+// Returns:
+//
+//   - the derived key
+//   - ErrInvalidKeyLength if the length is not valid, or any other error that occurred during the derivation
+func DeriveKey(
+	hashFn func() hash.Hash,
+	secret, salt, info []byte,
+	length int,
+) ([]byte, error) {
+	pseudorandomKey, err := Extract(hashFn, secret, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(pseudorandomKey)
+	return Expand(hashFn, pseudorandomKey, info, length)
+}
@@ -0,0 +1,120 @@
+package hkdf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// hkdfVectors are the HKDF-SHA256 test vectors of RFC 5869, appendix A
+var hkdfVectors = []struct {
+	name            string
+	secret          string
+	salt            string
+	info            string
+	length          int
+	pseudorandomKey string
+	key             string
+}{
+	{
+		name:            "A.1 basic test case",
+		secret:          "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b",
+		salt:            "000102030405060708090a0b0c",
+		info:            "f0f1f2f3f4f5f6f7f8f9",
+		length:          42,
+		pseudorandomKey: "077709362c2e32df0ddc3f0dc47bba6390b6c73bb50f9c3122ec844ad7c2b3e5",
+		key:             "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865",
+	},
+	{
+		name: "A.2 longer inputs and outputs",
+		secret: "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f" +
+			"303132333435363738393a3b3c3d3e3f404142434445464748494a4b4c4d4e4f",
+		salt: "606162636465666768696a6b6c6d6e6f707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f" +
+			"909192939495969798999a9b9c9d9e9fa0a1a2a3a4a5a6a7a8a9aaabacadaeaf",
+		info: "b0b1b2b3b4b5b6b7b8b9babbbcbdbebfc0c1c2c3c4c5c6c7c8c9cacbcccdcecfd0d1d2d3d4d5d6d7d8d9dadbdcdddedf" +
+			"e0e1e2e3e4e5e6e7e8e9eaebecedeeeff0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+		length:          82,
+		pseudorandomKey: "06a6b88c5853361a06104c9ceb35b45cef760014904671014a193f40c15fc244",
+		key: "b11e398dc80327a1c8e7f78c596a49344f012eda2d4efad8a050cc4c19afa97c59045a99cac7827271cb41c65e590e09" +
+			"da3275600c2f09b8367793a9aca3db71cc30c58179ec3e87c14c01d5c1f3434f1d87",
+	},
+	{
+		name:            "A.3 zero-length salt and info",
+		secret:          "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b",
+		length:          42,
+		pseudorandomKey: "19ef24a32c717b167f33a91d6f648bdf96596776afdb6377ac434c1c293ccb04",
+		key:             "8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d9d201395faa4b61a96c8",
+	},
+}
+
+// mustDecodeHex decodes a hexadecimal string, failing the test if it is not valid
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("hex.DecodeString(%q): %v", s, err)
+	}
+	return decoded
+}
+
+func TestHKDFVectors(t *testing.T) {
+	for _, vector := range hkdfVectors {
+		t.Run(
+			vector.name, func(t *testing.T) {
+				secret := mustDecodeHex(t, vector.secret)
+				salt := mustDecodeHex(t, vector.salt)
+				info := mustDecodeHex(t, vector.info)
+				pseudorandomKey := mustDecodeHex(t, vector.pseudorandomKey)
+				key := mustDecodeHex(t, vector.key)
+
+				got, err := Extract(sha256.New, secret, salt)
+				if err != nil {
+					t.Fatalf("Extract: %v", err)
+				}
+				if !bytes.Equal(got, pseudorandomKey) {
+					t.Fatalf("Extract = %x, want %x", got, pseudorandomKey)
+				}
+
+				if got, err = Expand(sha256.New, pseudorandomKey, info, vector.length); err != nil {
+					t.Fatalf("Expand: %v", err)
+				}
+				if !bytes.Equal(got, key) {
+					t.Fatalf("Expand = %x, want %x", got, key)
+				}
+
+				if got, err = DeriveKey(sha256.New, secret, salt, info, vector.length); err != nil {
+					t.Fatalf("DeriveKey: %v", err)
+				}
+				if !bytes.Equal(got, key) {
+					t.Fatalf("DeriveKey = %x, want %x", got, key)
+				}
+			},
+		)
+	}
+}
+
+func TestExpandInvalidLength(t *testing.T) {
+	pseudorandomKey := make([]byte, sha256.Size)
+
+	for _, length := range []int{-1, 0, 255*sha256.Size + 1} {
+		if _, err := Expand(sha256.New, pseudorandomKey, nil, length); !errors.Is(
+			err,
+			ErrInvalidKeyLength,
+		) {
+			t.Fatalf("Expand with a length of %d: got %v, want %v", length, err, ErrInvalidKeyLength)
+		}
+		if _, err := DeriveKey(sha256.New, pseudorandomKey, nil, nil, length); !errors.Is(
+			err,
+			ErrInvalidKeyLength,
+		) {
+			t.Fatalf("DeriveKey with a length of %d: got %v, want %v", length, err, ErrInvalidKeyLength)
+		}
+	}
+
+	if _, err := Expand(sha256.New, pseudorandomKey, nil, 255*sha256.Size); err != nil {
+		t.Fatalf("Expand with the maximum length: %v", err)
+	}
+}
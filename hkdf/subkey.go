@@ -0,0 +1,196 @@
+package hkdf
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// Subkeys are derived with HKDF-SHA256 from the master key and the optional salt, with the info label:
+//
+// subkeyInfoPrefix || name || length (2 bytes, big endian)
+//
+// The length is part of the label so that subkeys of the same name and different lengths are independent, instead of
+// one being a prefix of the other.
+
+const (
+	// MinMasterKeySize is the minimum size in bytes of the master key
+	MinMasterKeySize = 16
+
+	// AESKeySize is the size in bytes of the subkeys derived by AESKey, suitable for AES-256
+	AESKeySize = 32
+
+	// MaxSubkeyNameLength is the maximum length in bytes of a subkey name
+	MaxSubkeyNameLength = 255
+
+	// subkeyInfoPrefix is the prefix of the info label of every subkey, which separates them from keys derived with
+	// HKDF by other protocols
+	subkeyInfoPrefix = "go-crypto subkey v1 "
+)
+
+// Common subkey names
+const (
+	SubkeyDBColumn    = "db-column"
+	SubkeyCookie      = "cookie"
+	SubkeyTOTPSecrets = "totp-secrets"
+)
+
+type (
+	// SubkeyDeriver derives named, purpose-specific subkeys from a high-entropy master key, so a single master key can
+	// be configured and every purpose still gets an independent key. It is safe for concurrent use
+	SubkeyDeriver struct {
+		pseudorandomKey []byte
+	}
+)
+
+// NewSubkeyDeriver creates a new SubkeyDeriver
+//
+// Parameters:
+//
+//   - masterKey: The master key, which must have high entropy (e.g., random bytes, not a password) and is not retained
+//   - salt: The optional salt (may be nil), which must be the same to derive the same subkeys again
+//
+// Returns:
+//
+//   - A pointer to the SubkeyDeriver
+//   - ErrMasterKeyTooShort if the master key is shorter than MinMasterKeySize, or any other error that occurred while
+//     extracting the pseudorandom key
+func NewSubkeyDeriver(masterKey, salt []byte) (*SubkeyDeriver, error) {
+	if len(masterKey) < MinMasterKeySize {
+		return nil, newError("NewSubkeyDeriver", ErrMasterKeyTooShort)
+	}
+	pseudorandomKey, err := Extract(sha256.New, masterKey, salt)
+	if err != nil {
+		return nil, err
+	}
+	return &SubkeyDeriver{pseudorandomKey: pseudorandomKey}, nil
+}
+
+// NewSubkeyDeriverWithSecretKey creates a new SubkeyDeriver with the master key held by a SecretKey
+//
+// Parameters:
+//
+//   - masterKey: The master key, which must have high entropy and can be destroyed once the deriver is created
+//   - salt: The optional salt (may be nil)
+//
+// Returns:
+//
+//   - A pointer to the SubkeyDeriver
+//   - An error if the master key is nil, destroyed or too short
+func NewSubkeyDeriverWithSecretKey(
+	masterKey *gocrypto.SecretKey,
+	salt []byte,
+) (*SubkeyDeriver, error) {
+	masterKeyBytes, err := masterKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return NewSubkeyDeriver(masterKeyBytes, salt)
+}
+
+// info returns the info label of a subkey
+//
+// Parameters:
+//
+//   - name: The name of the subkey
+//   - length: The length of the subkey in bytes
+//
+// Returns:
+//
+//   - The info label
+//   - An error if the name is empty or too long
+func info(name string, length int) ([]byte, error) {
+	if name == "" {
+		return nil, newError("SubkeyDeriver.Subkey", ErrEmptySubkeyName)
+	}
+	if len(name) > MaxSubkeyNameLength {
+		return nil, newError("SubkeyDeriver.Subkey", ErrSubkeyNameTooLong)
+	}
+
+	label := make([]byte, 0, len(subkeyInfoPrefix)+len(name)+2)
+	label = append(label, subkeyInfoPrefix...)
+	label = append(label, name...)
+	return binary.BigEndian.AppendUint16(label, uint16(length)), nil
+}
+
+// Subkey derives the subkey of the given name and length. The same name and length always derive the same subkey
+//
+// Parameters:
+//
+//   - name: The name of the subkey, which identifies its purpose (e.g., SubkeyCookie)
+//   - length: The length of the subkey in bytes (at most 8160)
+//
+// Returns:
+//
+//   - The subkey
+//   - An error if the name or the length is not valid
+func (s *SubkeyDeriver) Subkey(name string, length int) ([]byte, error) {
+	if length <= 0 || length > 255*sha256.Size {
+		return nil, newError("SubkeyDeriver.Subkey", ErrInvalidKeyLength)
+	}
+	label, err := info(name, length)
+	if err != nil {
+		return nil, err
+	}
+	return Expand(sha256.New, s.pseudorandomKey, label, length)
+}
+
+// AESKey derives the subkey of the given name with the AES-256 key size, which can be passed to the aes functions
+//
+// Parameters:
+//
+//   - name: The name of the subkey
+//
+// Returns:
+//
+//   - The 32 bytes subkey
+//   - An error if the name is not valid
+func (s *SubkeyDeriver) AESKey(name string) ([]byte, error) {
+	return s.Subkey(name, AESKeySize)
+}
+
+// SecretSubkey derives the subkey of the given name and length as a SecretKey
+//
+// Parameters:
+//
+//   - name: The name of the subkey
+//   - length: The length of the subkey in bytes (at most 8160)
+//
+// Returns:
+//
+//   - A pointer to the SecretKey
+//   - An error if the name or the length is not valid
+func (s *SubkeyDeriver) SecretSubkey(name string, length int) (
+	*gocrypto.SecretKey,
+	error,
+) {
+	subkey, err := s.Subkey(name, length)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(subkey)
+	return gocrypto.NewSecretKey(subkey), nil
+}
+
+// DeriveSubkey derives a single named subkey from the master key without a salt. Use a SubkeyDeriver to derive
+// several subkeys from the same master key
+//
+// Parameters:
+//
+//   - masterKey: The master key, which must have high entropy
+//   - name: The name of the subkey
+//   - length: The length of the subkey in bytes (at most 8160)
+//
+// Returns:
+//
+//   - The subkey
+//   - An error if the master key, the name or the length is not valid
+func DeriveSubkey(masterKey []byte, name string, length int) ([]byte, error) {
+	deriver, err := NewSubkeyDeriver(masterKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(deriver.pseudorandomKey)
+	return deriver.Subkey(name, length)
+}
@@ -0,0 +1,175 @@
+package hkdf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// testMasterKey is the master key of the subkey tests
+var testMasterKey = bytes.Repeat([]byte{0x5a}, 32)
+
+// newTestSubkeyDeriver creates a SubkeyDeriver with the test master key and the given salt
+func newTestSubkeyDeriver(t *testing.T, salt []byte) *SubkeyDeriver {
+	t.Helper()
+
+	deriver, err := NewSubkeyDeriver(testMasterKey, salt)
+	if err != nil {
+		t.Fatalf("NewSubkeyDeriver: %v", err)
+	}
+	return deriver
+}
+
+func TestSubkeyDeterministic(t *testing.T) {
+	deriver := newTestSubkeyDeriver(t, nil)
+
+	first, err := deriver.AESKey(SubkeyCookie)
+	if err != nil {
+		t.Fatalf("SubkeyDeriver.AESKey: %v", err)
+	}
+	second, err := newTestSubkeyDeriver(t, nil).Subkey(SubkeyCookie, AESKeySize)
+	if err != nil {
+		t.Fatalf("SubkeyDeriver.Subkey: %v", err)
+	}
+	if len(first) != AESKeySize || !bytes.Equal(first, second) {
+		t.Fatalf("SubkeyDeriver.AESKey = %x, SubkeyDeriver.Subkey = %x, want equal %d-byte keys", first, second, AESKeySize)
+	}
+
+	// DeriveSubkey uses no salt
+	third, err := DeriveSubkey(testMasterKey, SubkeyCookie, AESKeySize)
+	if err != nil {
+		t.Fatalf("DeriveSubkey: %v", err)
+	}
+	if !bytes.Equal(first, third) {
+		t.Fatalf("DeriveSubkey = %x, want %x", third, first)
+	}
+
+	// The subkey is HKDF-SHA256 with the documented info label
+	label := append([]byte(subkeyInfoPrefix+SubkeyCookie), 0, AESKeySize)
+	want, err := DeriveKey(sha256.New, testMasterKey, nil, label, AESKeySize)
+	if err != nil {
+		t.Fatalf("DeriveKey: %v", err)
+	}
+	if !bytes.Equal(first, want) {
+		t.Fatalf("SubkeyDeriver.AESKey = %x, want %x", first, want)
+	}
+}
+
+func TestSubkeyIndependence(t *testing.T) {
+	deriver := newTestSubkeyDeriver(t, nil)
+
+	derive := func(deriver *SubkeyDeriver, name string, length int) []byte {
+		subkey, err := deriver.Subkey(name, length)
+		if err != nil {
+			t.Fatalf("SubkeyDeriver.Subkey(%q, %d): %v", name, length, err)
+		}
+		return subkey
+	}
+
+	subkeys := map[string][]byte{
+		"db-column/32":    derive(deriver, SubkeyDBColumn, 32),
+		"cookie/32":       derive(deriver, SubkeyCookie, 32),
+		"totp-secrets/32": derive(deriver, SubkeyTOTPSecrets, 32),
+		"cookie/64":       derive(deriver, SubkeyCookie, 64),
+		"cookie/16":       derive(deriver, SubkeyCookie, 16),
+		"salted cookie/32": derive(
+			newTestSubkeyDeriver(t, []byte("salt")),
+			SubkeyCookie,
+			32,
+		),
+	}
+
+	// No subkey is equal to, or a prefix of, another one
+	for name, subkey := range subkeys {
+		for otherName, other := range subkeys {
+			if name == otherName {
+				continue
+			}
+			shorter, longer := subkey, other
+			if len(shorter) > len(longer) {
+				shorter, longer = longer, shorter
+			}
+			if bytes.HasPrefix(longer, shorter) {
+				t.Fatalf("subkeys %s and %s are not independent", name, otherName)
+			}
+		}
+	}
+}
+
+func TestSubkeyInvalid(t *testing.T) {
+	deriver := newTestSubkeyDeriver(t, nil)
+
+	tests := []struct {
+		name   string
+		subkey string
+		length int
+		err    error
+	}{
+		{name: "empty name", subkey: "", length: 32, err: ErrEmptySubkeyName},
+		{name: "too long name", subkey: strings.Repeat("a", MaxSubkeyNameLength+1), length: 32, err: ErrSubkeyNameTooLong},
+		{name: "zero length", subkey: SubkeyCookie, length: 0, err: ErrInvalidKeyLength},
+		{name: "negative length", subkey: SubkeyCookie, length: -1, err: ErrInvalidKeyLength},
+		{name: "too large length", subkey: SubkeyCookie, length: 255*sha256.Size + 1, err: ErrInvalidKeyLength},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if _, err := deriver.Subkey(test.subkey, test.length); !errors.Is(
+					err,
+					test.err,
+				) {
+					t.Fatalf("SubkeyDeriver.Subkey: got %v, want %v", err, test.err)
+				}
+				if _, err := DeriveSubkey(testMasterKey, test.subkey, test.length); !errors.Is(
+					err,
+					test.err,
+				) {
+					t.Fatalf("DeriveSubkey: got %v, want %v", err, test.err)
+				}
+			},
+		)
+	}
+
+	// The longest name and length are accepted
+	if _, err := deriver.Subkey(strings.Repeat("a", MaxSubkeyNameLength), 255*sha256.Size); err != nil {
+		t.Fatalf("SubkeyDeriver.Subkey with the longest name and length: %v", err)
+	}
+}
+
+func TestNewSubkeyDeriverInvalid(t *testing.T) {
+	if _, err := NewSubkeyDeriver(make([]byte, MinMasterKeySize-1), nil); !errors.Is(
+		err,
+		ErrMasterKeyTooShort,
+	) {
+		t.Fatalf("NewSubkeyDeriver with a short master key: got %v, want %v", err, ErrMasterKeyTooShort)
+	}
+
+	// A SecretKey master key derives the same subkeys, and a destroyed one is rejected
+	secretKey := gocrypto.NewSecretKey(testMasterKey)
+	deriver, err := NewSubkeyDeriverWithSecretKey(secretKey, nil)
+	if err != nil {
+		t.Fatalf("NewSubkeyDeriverWithSecretKey: %v", err)
+	}
+	secretKey.Destroy()
+	subkey, err := deriver.SecretSubkey(SubkeyCookie, AESKeySize)
+	if err != nil {
+		t.Fatalf("SubkeyDeriver.SecretSubkey: %v", err)
+	}
+	want, err := newTestSubkeyDeriver(t, nil).AESKey(SubkeyCookie)
+	if err != nil {
+		t.Fatalf("SubkeyDeriver.AESKey: %v", err)
+	}
+	if got, _ := subkey.Bytes(); !bytes.Equal(got, want) {
+		t.Fatalf("SubkeyDeriver.SecretSubkey = %x, want %x", got, want)
+	}
+	if _, err = NewSubkeyDeriverWithSecretKey(secretKey, nil); !errors.Is(
+		err,
+		gocrypto.ErrSecretKeyDestroyed,
+	) {
+		t.Fatalf("NewSubkeyDeriverWithSecretKey with a destroyed key: got %v, want %v", err, gocrypto.ErrSecretKeyDestroyed)
+	}
+}
@@ -0,0 +1,217 @@
+package kms
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+
+	gocryptoaes "github.com/ralvarezdev/go-crypto/aes"
+	gocryptorandombytes "github.com/ralvarezdev/go-crypto/random/bytes"
+)
+
+// The KMS envelope stores a per-object data encryption key, wrapped by a key encryption key held in a KMS, alongside
+// the value encrypted with AES-GCM under that data encryption key.
+//
+// Binary: magic (2 bytes) || version (1 byte) || key ID length (1 byte) || key ID ||
+// wrapped key length (2 bytes, big endian) || wrapped key || nonce || cipher text
+//
+// The text form is the hexadecimal encoding of the binary form. Everything before the nonce is bound to the cipher
+// text as additional authenticated data.
+
+const (
+	// EnvelopeVersion is the current version of the KMS envelope format
+	EnvelopeVersion = 1
+
+	// DataKeySize is the size in bytes of the generated data encryption keys
+	DataKeySize = 32
+
+	// MaxKeyIDLength is the maximum length in bytes of a key encryption key ID
+	MaxKeyIDLength = 255
+
+	// MaxWrappedKeyLength is the maximum length in bytes of a wrapped data encryption key
+	MaxWrappedKeyLength = 65535
+)
+
+var (
+	// EnvelopeMagic is the prefix that identifies a KMS envelope
+	EnvelopeMagic = [2]byte{0xae, 0x4b}
+)
+
+// encodeHeader encodes the KMS envelope header
+//
+// Parameters:
+//
+//   - keyID: The ID of the key encryption key
+//   - wrappedKey: The wrapped data encryption key
+//
+// Returns:
+//
+//   - The encoded header
+//   - An error if the key ID or the wrapped key are too long
+func encodeHeader(keyID string, wrappedKey []byte) ([]byte, error) {
+	if len(keyID) > MaxKeyIDLength {
+		return nil, newError("EncryptEnvelope", ErrKeyIDTooLong)
+	}
+	if len(wrappedKey) > MaxWrappedKeyLength {
+		return nil, newError("EncryptEnvelope", ErrWrappedKeyTooLong)
+	}
+
+	header := make([]byte, 0, 6+len(keyID)+len(wrappedKey))
+	header = append(header, EnvelopeMagic[:]...)
+	header = append(header, EnvelopeVersion, byte(len(keyID)))
+	header = append(header, keyID...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrappedKey)))
+	return append(header, wrappedKey...), nil
+}
+
+// decodeHeader decodes the KMS envelope header
+//
+// Parameters:
+//
+//   - data: The binary form of the KMS envelope
+//
+// Returns:
+//
+//   - The ID of the key encryption key
+//   - The wrapped data encryption key
+//   - The length in bytes of the header
+//   - An error if the data is not a valid KMS envelope
+func decodeHeader(data []byte) (string, []byte, int, error) {
+	// Check the magic, the version and the key ID length
+	if len(data) < 4 || data[0] != EnvelopeMagic[0] || data[1] != EnvelopeMagic[1] {
+		return "", nil, 0, newError("DecryptEnvelope", ErrInvalidEnvelope)
+	}
+	if data[2] != EnvelopeVersion {
+		return "", nil, 0, newError("DecryptEnvelope", ErrUnsupportedVersion)
+	}
+	offset := 4
+	keyIDLength := int(data[3])
+
+	// Get the key ID and the wrapped key length
+	if len(data) < offset+keyIDLength+2 {
+		return "", nil, 0, newError("DecryptEnvelope", ErrInvalidEnvelope)
+	}
+	keyID := string(data[offset : offset+keyIDLength])
+	offset += keyIDLength
+	wrappedKeyLength := int(binary.BigEndian.Uint16(data[offset:]))
+	offset += 2
+
+	// Get the wrapped key
+	if len(data) < offset+wrappedKeyLength {
+		return "", nil, 0, newError("DecryptEnvelope", ErrInvalidEnvelope)
+	}
+	wrappedKey := data[offset : offset+wrappedKeyLength]
+	offset += wrappedKeyLength
+
+	return keyID, wrappedKey, offset, nil
+}
+
+// EncryptEnvelope encrypts a string with a newly generated data encryption key using AES-GCM, wraps the data
+// encryption key with the current key encryption key of the KMS and returns both as a KMS envelope
+//
+// Parameters:
+//
+//   - ctx: The context passed to the KMS
+//   - kms: The KMS that wraps the data encryption key
+//   - plainText: The plain text to encrypt
+//
+// Returns:
+//
+//   - A pointer to the KMS envelope in hexadecimal format
+//   - An error if any occurred during the encryption process
+func EncryptEnvelope(ctx context.Context, kms KMS, plainText []byte) (
+	*string,
+	error,
+) {
+	// Check if the KMS is nil
+	if kms == nil {
+		return nil, newError("EncryptEnvelope", ErrNilKMS)
+	}
+
+	// Generate the data encryption key
+	dataKey, err := gocryptorandombytes.Generate(DataKeySize)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(dataKey)
+
+	// Wrap the data encryption key with the current key encryption key
+	keyID, err := kms.KeyID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wrappedKey, err := kms.Encrypt(ctx, keyID, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encode the header
+	header, err := encodeHeader(keyID, wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt the plain text with the data encryption key, binding the header as additional data
+	payload, err := gocryptoaes.EncryptGCMWithAAD(plainText, dataKey, header)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the header followed by the payload in hexadecimal format
+	enc := hex.EncodeToString(header) + *payload
+
+	return &enc, nil
+}
+
+// DecryptEnvelope unwraps the data encryption key of a KMS envelope with the KMS and decrypts the value
+//
+// Parameters:
+//
+//   - ctx: The context passed to the KMS
+//   - kms: The KMS that unwraps the data encryption key
+//   - encryptedText: A pointer to the KMS envelope in hexadecimal format
+//
+// Returns:
+//
+//   - A pointer to the decrypted plain text string
+//   - An error if any occurred during the decryption process
+func DecryptEnvelope(
+	ctx context.Context,
+	kms KMS,
+	encryptedText *string,
+) (*string, error) {
+	// Check if the KMS or the encrypted text are nil
+	if kms == nil {
+		return nil, newError("DecryptEnvelope", ErrNilKMS)
+	}
+	if encryptedText == nil {
+		return nil, newError("DecryptEnvelope", gocryptoaes.ErrNilEncryptedText)
+	}
+
+	// Decode the encrypted text from a hexadecimal string
+	data, err := hex.DecodeString(*encryptedText)
+	if err != nil {
+		return nil, newError("DecryptEnvelope", ErrInvalidEncoding)
+	}
+
+	// Decode the header
+	keyID, wrappedKey, headerLength, err := decodeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unwrap the data encryption key
+	dataKey, err := kms.Decrypt(ctx, keyID, wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(dataKey)
+
+	// Decrypt the payload with the data encryption key, verifying the header as additional data
+	payload := (*encryptedText)[2*headerLength:]
+	return gocryptoaes.DecryptGCMWithAAD(
+		&payload,
+		dataKey,
+		data[:headerLength],
+	)
+}
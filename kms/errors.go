@@ -0,0 +1,38 @@
+package kms
+
+import (
+	"errors"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// packageName is the package name reported by the errors of the package
+const packageName = "kms"
+
+var (
+	ErrNilKMS                   = errors.New("kms is nil")
+	ErrInvalidEnvelope          = errors.New("invalid kms envelope")
+	ErrUnsupportedVersion       = errors.New("unsupported kms envelope version")
+	ErrKeyIDTooLong             = errors.New("key ID is too long")
+	ErrWrappedKeyTooLong        = errors.New("wrapped data encryption key is too long")
+	ErrKeyNotFound              = errors.New("key encryption key not found")
+	ErrInvalidLocalKMSFile      = errors.New("invalid local kms file")
+	ErrInvalidPassphrase        = errors.New("invalid passphrase")
+	ErrInvalidIterations        = errors.New("invalid number of iterations")
+	ErrInvalidDataEncryptionKey = errors.New("invalid data encryption key")
+	ErrInvalidEncoding          = gocrypto.ErrInvalidEncoding
+)
+
+// newError wraps an error with the operation that failed and the package name
+//
+// Parameters:
+//
+//   - op: The name of the operation
+//   - err: The error to wrap
+//
+// Returns:
+//
+//   - The wrapped error, or nil if err is nil
+func newError(op string, err error) error {
+	return gocrypto.NewError(packageName, op, err)
+}
@@ -0,0 +1,26 @@
+package kms
+
+import (
+	"context"
+)
+
+type (
+	// KMS is a key management service that holds key encryption keys (KEKs) and wraps and unwraps data encryption
+	// keys (DEKs) with them, so the key encryption keys never leave the service
+	KMS interface {
+		// KeyID returns the ID of the key encryption key used to wrap new data encryption keys
+		KeyID(ctx context.Context) (string, error)
+
+		// Encrypt wraps a data encryption key with the key encryption key identified by keyID
+		Encrypt(ctx context.Context, keyID string, dataKey []byte) (
+			[]byte,
+			error,
+		)
+
+		// Decrypt unwraps a data encryption key with the key encryption key identified by keyID
+		Decrypt(ctx context.Context, keyID string, wrappedKey []byte) (
+			[]byte,
+			error,
+		)
+	}
+)
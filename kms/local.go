@@ -0,0 +1,458 @@
+package kms
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	gocryptoaes "github.com/ralvarezdev/go-crypto/aes"
+	gocryptopbkdf2 "github.com/ralvarezdev/go-crypto/pbkdf2"
+	gocryptorandombytes "github.com/ralvarezdev/go-crypto/random/bytes"
+	gocryptorandomstrings "github.com/ralvarezdev/go-crypto/random/strings"
+)
+
+const (
+	// LocalKMSFileVersion is the version of the local KMS file format
+	LocalKMSFileVersion = 1
+
+	// DefaultLocalKMSIterations is the default number of PBKDF2 iterations used to derive the file key from the
+	// passphrase
+	DefaultLocalKMSIterations = 600000
+
+	// MinLocalKMSIterations is the minimum number of PBKDF2 iterations accepted to derive the file key from the
+	// passphrase
+	MinLocalKMSIterations = 100000
+
+	// MaxLocalKMSIterations is the maximum number of PBKDF2 iterations accepted to derive the file key from the
+	// passphrase, so a modified file cannot make opening it arbitrarily slow
+	MaxLocalKMSIterations = 10000000
+
+	// localKMSSaltSize is the size in bytes of the PBKDF2 salt
+	localKMSSaltSize = 16
+
+	// localKMSKeyIDSize is the size in bytes of the random key encryption key IDs
+	localKMSKeyIDSize = 8
+
+	// localKMSFileMode is the permission of the local KMS file
+	localKMSFileMode = 0o600
+)
+
+var (
+	// localKMSFileAAD is the additional authenticated data used to encrypt the local KMS file keys
+	localKMSFileAAD = []byte("go-crypto/kms/local")
+)
+
+type (
+	// LocalKMS is a KMS backed by a passphrase-protected file, intended as an offline stand-in for a real key
+	// management service. It is safe for concurrent use
+	LocalKMS struct {
+		mutex      sync.RWMutex
+		path       string
+		fileKey    []byte
+		salt       []byte
+		iterations int
+		primaryID  string
+		keys       map[string][]byte
+	}
+
+	// localKMSFile is the content of a local KMS file. Byte fields are encoded in base64
+	localKMSFile struct {
+		Version    int    `json:"version"`
+		Salt       []byte `json:"salt"`
+		Iterations int    `json:"iterations"`
+		Keys       []byte `json:"keys"`
+	}
+
+	// localKMSKeys is the decrypted key set of a local KMS file. The keys are kept as byte slices, so they can be
+	// cleared once they are no longer needed
+	localKMSKeys struct {
+		PrimaryID string            `json:"primary_id"`
+		Keys      map[string][]byte `json:"keys"`
+	}
+)
+
+// deriveFileKey derives the key that protects the local KMS file from the passphrase
+//
+// Parameters:
+//
+//   - passphrase: The passphrase
+//   - salt: The PBKDF2 salt
+//   - iterations: The number of PBKDF2 iterations
+//
+// Returns:
+//
+//   - The derived key
+func deriveFileKey(passphrase string, salt []byte, iterations int) []byte {
+	return gocryptopbkdf2.DeriveKey(
+		passphrase,
+		salt,
+		iterations,
+		32,
+		sha256.New,
+	)
+}
+
+// CreateLocalKMS creates a new local KMS file with a single key encryption key. It fails if the file already exists
+//
+// Parameters:
+//
+//   - path: The path of the local KMS file
+//   - passphrase: The passphrase that protects the file
+//   - iterations: The number of PBKDF2 iterations. If zero, DefaultLocalKMSIterations is used. It must be between
+//     MinLocalKMSIterations and MaxLocalKMSIterations
+//
+// Returns:
+//
+//   - A pointer to the LocalKMS
+//   - An error if any occurred during the creation
+func CreateLocalKMS(path, passphrase string, iterations int) (
+	*LocalKMS,
+	error,
+) {
+	// Check the number of iterations
+	if iterations == 0 {
+		iterations = DefaultLocalKMSIterations
+	}
+	if iterations < MinLocalKMSIterations || iterations > MaxLocalKMSIterations {
+		return nil, newError("CreateLocalKMS", ErrInvalidIterations)
+	}
+
+	// Generate the salt and derive the file key
+	salt, err := gocryptorandombytes.Generate(localKMSSaltSize)
+	if err != nil {
+		return nil, err
+	}
+
+	localKMS := &LocalKMS{
+		path:       path,
+		fileKey:    deriveFileKey(passphrase, salt, iterations),
+		salt:       salt,
+		iterations: iterations,
+		keys:       make(map[string][]byte),
+	}
+
+	// Generate the first key encryption key
+	if _, err = localKMS.addKey(); err != nil {
+		return nil, err
+	}
+
+	// Create the file, failing if it already exists
+	if err = localKMS.save(true); err != nil {
+		return nil, err
+	}
+	return localKMS, nil
+}
+
+// OpenLocalKMS opens an existing local KMS file
+//
+// Parameters:
+//
+//   - path: The path of the local KMS file
+//   - passphrase: The passphrase that protects the file
+//
+// Returns:
+//
+//   - A pointer to the LocalKMS
+//   - ErrInvalidPassphrase if the passphrase is wrong, or any other error that occurred while opening the file
+func OpenLocalKMS(path, passphrase string) (*LocalKMS, error) {
+	// Read and decode the file
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var file localKMSFile
+	if err = json.Unmarshal(content, &file); err != nil {
+		return nil, newError("OpenLocalKMS", ErrInvalidLocalKMSFile)
+	}
+	if file.Version != LocalKMSFileVersion {
+		return nil, newError("OpenLocalKMS", ErrInvalidLocalKMSFile)
+	}
+	if file.Iterations < MinLocalKMSIterations || file.Iterations > MaxLocalKMSIterations {
+		return nil, newError("OpenLocalKMS", ErrInvalidIterations)
+	}
+	if len(file.Salt) != localKMSSaltSize {
+		return nil, newError("OpenLocalKMS", ErrInvalidLocalKMSFile)
+	}
+
+	// Derive the file key and decrypt the key set
+	fileKey := deriveFileKey(passphrase, file.Salt, file.Iterations)
+	decryptedKeys, err := gocryptoaes.DecryptGCMBytes(
+		nil,
+		file.Keys,
+		fileKey,
+		localKMSFileAAD,
+	)
+	if err != nil {
+		clear(fileKey)
+		if errors.Is(err, gocryptoaes.ErrAuthenticationFailed) {
+			return nil, newError("OpenLocalKMS", ErrInvalidPassphrase)
+		}
+		return nil, newError("OpenLocalKMS", ErrInvalidLocalKMSFile)
+	}
+	var keySet localKMSKeys
+	err = json.Unmarshal(decryptedKeys, &keySet)
+	clear(decryptedKeys)
+	if err != nil {
+		clear(fileKey)
+		return nil, newError("OpenLocalKMS", ErrInvalidLocalKMSFile)
+	}
+
+	// Check the key encryption keys
+	_, ok := keySet.Keys[keySet.PrimaryID]
+	for _, key := range keySet.Keys {
+		if len(key) != DataKeySize {
+			ok = false
+		}
+	}
+	if !ok {
+		clear(fileKey)
+		for _, key := range keySet.Keys {
+			clear(key)
+		}
+		return nil, newError("OpenLocalKMS", ErrInvalidLocalKMSFile)
+	}
+
+	return &LocalKMS{
+		path:       path,
+		fileKey:    fileKey,
+		salt:       file.Salt,
+		iterations: file.Iterations,
+		primaryID:  keySet.PrimaryID,
+		keys:       keySet.Keys,
+	}, nil
+}
+
+// addKey generates a new key encryption key and makes it the primary key. The mutex must be held by the caller
+//
+// Returns:
+//
+//   - The ID of the new key
+//   - An error if any occurred while generating the key
+func (l *LocalKMS) addKey() (string, error) {
+	// Generate a unique key ID
+	var id string
+	for {
+		generatedID, err := gocryptorandomstrings.Generate(localKMSKeyIDSize)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := l.keys[generatedID]; !ok {
+			id = generatedID
+			break
+		}
+	}
+
+	// Generate the key
+	key, err := gocryptorandombytes.Generate(DataKeySize)
+	if err != nil {
+		return "", err
+	}
+
+	l.keys[id] = key
+	l.primaryID = id
+	return id, nil
+}
+
+// save writes the local KMS file. The mutex must be held by the caller
+//
+// Parameters:
+//
+//   - create: Whether the file must be created, failing if it already exists
+//
+// Returns:
+//
+//   - An error if any occurred while writing the file
+func (l *LocalKMS) save(create bool) error {
+	// Encode and encrypt the key set
+	encodedKeys, err := json.Marshal(
+		localKMSKeys{
+			PrimaryID: l.primaryID,
+			Keys:      l.keys,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	encryptedKeys, err := gocryptoaes.EncryptGCMBytes(
+		nil,
+		encodedKeys,
+		l.fileKey,
+		localKMSFileAAD,
+	)
+	clear(encodedKeys)
+	if err != nil {
+		return err
+	}
+
+	// Encode the file
+	content, err := json.MarshalIndent(
+		localKMSFile{
+			Version:    LocalKMSFileVersion,
+			Salt:       l.salt,
+			Iterations: l.iterations,
+			Keys:       encryptedKeys,
+		}, "", "  ",
+	)
+	if err != nil {
+		return err
+	}
+
+	// Create the file, failing if it already exists
+	if create {
+		file, openErr := os.OpenFile(
+			filepath.Clean(l.path),
+			os.O_WRONLY|os.O_CREATE|os.O_EXCL,
+			localKMSFileMode,
+		)
+		if openErr != nil {
+			return openErr
+		}
+		if _, err = file.Write(content); err != nil {
+			_ = file.Close()
+			return err
+		}
+		return file.Close()
+	}
+
+	// Replace the file atomically through a temporary file
+	temporaryPath := l.path + ".tmp"
+	if err = os.WriteFile(temporaryPath, content, localKMSFileMode); err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, l.path)
+}
+
+// RotateKey generates a new key encryption key, makes it the primary key and saves the file. Previous keys are kept
+// so existing envelopes can still be decrypted
+//
+// Returns:
+//
+//   - The ID of the new primary key
+//   - An error if any occurred while generating the key or saving the file
+func (l *LocalKMS) RotateKey() (string, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// Keep the previous primary key to restore it if saving fails
+	previousPrimaryID := l.primaryID
+	id, err := l.addKey()
+	if err != nil {
+		return "", err
+	}
+	if err = l.save(false); err != nil {
+		delete(l.keys, id)
+		l.primaryID = previousPrimaryID
+		return "", err
+	}
+	return id, nil
+}
+
+// KeyID returns the ID of the primary key encryption key
+//
+// Parameters:
+//
+//   - ctx: The context (unused)
+//
+// Returns:
+//
+//   - The ID of the primary key encryption key
+//   - An error if any occurred
+func (l *LocalKMS) KeyID(ctx context.Context) (string, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return l.primaryID, nil
+}
+
+// key returns the key encryption key with the given ID
+//
+// Parameters:
+//
+//   - keyID: The ID of the key encryption key
+//
+// Returns:
+//
+//   - The key encryption key
+//   - ErrKeyNotFound if there is no key with the given ID
+func (l *LocalKMS) key(keyID string) ([]byte, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	key, ok := l.keys[keyID]
+	if !ok {
+		return nil, newError("LocalKMS.key", ErrKeyNotFound)
+	}
+	return key, nil
+}
+
+// Encrypt wraps a data encryption key with the key encryption key identified by keyID, using AES-GCM with the key ID
+// as additional authenticated data
+//
+// Parameters:
+//
+//   - ctx: The context (unused)
+//   - keyID: The ID of the key encryption key
+//   - dataKey: The data encryption key to wrap
+//
+// Returns:
+//
+//   - The wrapped data encryption key
+//   - An error if any occurred during the wrapping process
+func (l *LocalKMS) Encrypt(
+	ctx context.Context,
+	keyID string,
+	dataKey []byte,
+) ([]byte, error) {
+	// Get the key encryption key
+	key, err := l.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wrap the data encryption key
+	return gocryptoaes.EncryptGCMBytes(nil, dataKey, key, []byte(keyID))
+}
+
+// Decrypt unwraps a data encryption key with the key encryption key identified by keyID
+//
+// Parameters:
+//
+//   - ctx: The context (unused)
+//   - keyID: The ID of the key encryption key
+//   - wrappedKey: The wrapped data encryption key
+//
+// Returns:
+//
+//   - The data encryption key
+//   - An error if any occurred during the unwrapping process
+func (l *LocalKMS) Decrypt(
+	ctx context.Context,
+	keyID string,
+	wrappedKey []byte,
+) ([]byte, error) {
+	// Get the key encryption key
+	key, err := l.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unwrap the data encryption key
+	dataKey, err := gocryptoaes.DecryptGCMBytes(
+		nil,
+		wrappedKey,
+		key,
+		[]byte(keyID),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(dataKey) != DataKeySize {
+		clear(dataKey)
+		return nil, newError("LocalKMS.Decrypt", ErrInvalidDataEncryptionKey)
+	}
+	return dataKey, nil
+}
@@ -0,0 +1,233 @@
+package kms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gocryptoaes "github.com/ralvarezdev/go-crypto/aes"
+)
+
+// testPassphrase is the passphrase of the local KMS files created by the tests
+const testPassphrase = "correct horse battery staple"
+
+// createTestLocalKMS creates a local KMS file in a temporary directory, using the minimum number of iterations
+func createTestLocalKMS(t *testing.T) (*LocalKMS, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "kms.json")
+	localKMS, err := CreateLocalKMS(path, testPassphrase, MinLocalKMSIterations)
+	if err != nil {
+		t.Fatalf("CreateLocalKMS: %v", err)
+	}
+	return localKMS, path
+}
+
+// rewriteLocalKMSFile decodes a local KMS file, modifies it and writes it back
+func rewriteLocalKMSFile(t *testing.T, path string, modify func(file *localKMSFile)) {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	var file localKMSFile
+	if err = json.Unmarshal(content, &file); err != nil {
+		t.Fatalf("decoding %s: %v", path, err)
+	}
+	modify(&file)
+	if content, err = json.Marshal(file); err != nil {
+		t.Fatalf("encoding %s: %v", path, err)
+	}
+	if err = os.WriteFile(path, content, localKMSFileMode); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+func TestLocalKMSEnvelopeRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	localKMS, path := createTestLocalKMS(t)
+
+	firstText, err := EncryptEnvelope(ctx, localKMS, []byte("first"))
+	if err != nil {
+		t.Fatalf("EncryptEnvelope: %v", err)
+	}
+
+	// Rotate the key encryption key, keeping the previous one to decrypt existing envelopes
+	previousKeyID, err := localKMS.KeyID(ctx)
+	if err != nil {
+		t.Fatalf("LocalKMS.KeyID: %v", err)
+	}
+	keyID, err := localKMS.RotateKey()
+	if err != nil {
+		t.Fatalf("LocalKMS.RotateKey: %v", err)
+	}
+	if keyID == previousKeyID {
+		t.Fatalf("LocalKMS.RotateKey kept the key ID %q", keyID)
+	}
+	secondText, err := EncryptEnvelope(ctx, localKMS, []byte("second"))
+	if err != nil {
+		t.Fatalf("EncryptEnvelope: %v", err)
+	}
+
+	// Reopen the file and decrypt both envelopes
+	reopened, err := OpenLocalKMS(path, testPassphrase)
+	if err != nil {
+		t.Fatalf("OpenLocalKMS: %v", err)
+	}
+	if reopenedKeyID, _ := reopened.KeyID(ctx); reopenedKeyID != keyID {
+		t.Fatalf("LocalKMS.KeyID = %q, want %q", reopenedKeyID, keyID)
+	}
+	for encryptedText, want := range map[*string]string{
+		firstText:  "first",
+		secondText: "second",
+	} {
+		plainText, err := DecryptEnvelope(ctx, reopened, encryptedText)
+		if err != nil {
+			t.Fatalf("DecryptEnvelope: %v", err)
+		}
+		if *plainText != want {
+			t.Fatalf("DecryptEnvelope = %q, want %q", *plainText, want)
+		}
+	}
+}
+
+func TestLocalKMSEnvelopeTampered(t *testing.T) {
+	ctx := context.Background()
+	localKMS, _ := createTestLocalKMS(t)
+
+	encryptedText, err := EncryptEnvelope(ctx, localKMS, []byte("data"))
+	if err != nil {
+		t.Fatalf("EncryptEnvelope: %v", err)
+	}
+
+	// Flip the last bit of the cipher text
+	tampered := []byte(*encryptedText)
+	if tampered[len(tampered)-1] == '0' {
+		tampered[len(tampered)-1] = '1'
+	} else {
+		tampered[len(tampered)-1] = '0'
+	}
+	tamperedText := string(tampered)
+
+	if _, err = DecryptEnvelope(
+		ctx,
+		localKMS,
+		&tamperedText,
+	); !errors.Is(err, gocryptoaes.ErrAuthenticationFailed) {
+		t.Fatalf("DecryptEnvelope of a tampered envelope: got %v, want %v", err, gocryptoaes.ErrAuthenticationFailed)
+	}
+}
+
+func TestLocalKMSUnknownKey(t *testing.T) {
+	ctx := context.Background()
+	localKMS, _ := createTestLocalKMS(t)
+	otherKMS, _ := createTestLocalKMS(t)
+
+	// The envelope references a key encryption key that only the other KMS holds
+	encryptedText, err := EncryptEnvelope(ctx, otherKMS, []byte("data"))
+	if err != nil {
+		t.Fatalf("EncryptEnvelope: %v", err)
+	}
+	if _, err = DecryptEnvelope(ctx, localKMS, encryptedText); !errors.Is(
+		err,
+		ErrKeyNotFound,
+	) {
+		t.Fatalf("DecryptEnvelope with an unknown key: got %v, want %v", err, ErrKeyNotFound)
+	}
+	if _, err = localKMS.Encrypt(ctx, "unknown", make([]byte, DataKeySize)); !errors.Is(
+		err,
+		ErrKeyNotFound,
+	) {
+		t.Fatalf("LocalKMS.Encrypt with an unknown key: got %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestOpenLocalKMSWrongPassphrase(t *testing.T) {
+	_, path := createTestLocalKMS(t)
+
+	if _, err := OpenLocalKMS(path, "wrong passphrase"); !errors.Is(
+		err,
+		ErrInvalidPassphrase,
+	) {
+		t.Fatalf("OpenLocalKMS with the wrong passphrase: got %v, want %v", err, ErrInvalidPassphrase)
+	}
+}
+
+func TestOpenLocalKMSInvalidFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(file *localKMSFile)
+		err    error
+	}{
+		{
+			name:   "unsupported version",
+			modify: func(file *localKMSFile) { file.Version = LocalKMSFileVersion + 1 },
+			err:    ErrInvalidLocalKMSFile,
+		},
+		{
+			name:   "too few iterations",
+			modify: func(file *localKMSFile) { file.Iterations = MinLocalKMSIterations - 1 },
+			err:    ErrInvalidIterations,
+		},
+		{
+			name:   "too many iterations",
+			modify: func(file *localKMSFile) { file.Iterations = MaxLocalKMSIterations + 1 },
+			err:    ErrInvalidIterations,
+		},
+		{
+			name:   "short salt",
+			modify: func(file *localKMSFile) { file.Salt = file.Salt[:localKMSSaltSize-1] },
+			err:    ErrInvalidLocalKMSFile,
+		},
+		{
+			name:   "long salt",
+			modify: func(file *localKMSFile) { file.Salt = make([]byte, 1<<20) },
+			err:    ErrInvalidLocalKMSFile,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				_, path := createTestLocalKMS(t)
+				rewriteLocalKMSFile(t, path, test.modify)
+
+				if _, err := OpenLocalKMS(path, testPassphrase); !errors.Is(
+					err,
+					test.err,
+				) {
+					t.Fatalf("OpenLocalKMS: got %v, want %v", err, test.err)
+				}
+			},
+		)
+	}
+}
+
+func TestCreateLocalKMSInvalid(t *testing.T) {
+	for _, iterations := range []int{
+		-1,
+		MinLocalKMSIterations - 1,
+		MaxLocalKMSIterations + 1,
+	} {
+		if _, err := CreateLocalKMS(
+			filepath.Join(t.TempDir(), "kms.json"),
+			testPassphrase,
+			iterations,
+		); !errors.Is(err, ErrInvalidIterations) {
+			t.Fatalf("CreateLocalKMS with %d iterations: got %v, want %v", iterations, err, ErrInvalidIterations)
+		}
+	}
+
+	// An existing file is never overwritten
+	_, path := createTestLocalKMS(t)
+	if _, err := CreateLocalKMS(
+		path,
+		testPassphrase,
+		MinLocalKMSIterations,
+	); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("CreateLocalKMS with an existing file: got %v, want %v", err, os.ErrExist)
+	}
+}
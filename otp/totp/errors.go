@@ -0,0 +1,32 @@
+package totp
+
+import (
+	"errors"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// packageName is the package name reported by the errors of the package
+const packageName = "totp"
+
+var (
+	ErrInvalidDigitCount   = errors.New("digit count must be between 6 and 8")
+	ErrInvalidHashLength   = errors.New("hash is too short to truncate")
+	ErrInvalidPeriod       = errors.New("period must be positive")
+	ErrInvalidSecretLength = errors.New("secret length must be positive")
+	ErrInvalidEncoding     = gocrypto.ErrInvalidEncoding
+)
+
+// newError wraps an error with the operation that failed and the package name
+//
+// Parameters:
+//
+//   - op: The name of the operation
+//   - err: The error to wrap
+//
+// Returns:
+//
+//   - The wrapped error, or nil if err is nil
+func newError(op string, err error) error {
+	return gocrypto.NewError(packageName, op, err)
+}
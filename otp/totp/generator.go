@@ -13,6 +13,10 @@ import (
 	"time"
 )
 
+// minTruncateHashLength is the minimum length in bytes of a hash to truncate, which is the SHA1 output size, so the
+// 4 bytes after the largest offset (15) are always within the hash
+const minTruncateHashLength = 20
+
 // Inspired by:
 // https://medium.com/@firateski/coding-totp-generator-with-go-a31668ef955e
 // https://medium.com/@nathanbcrocker/building-a-time-based-one-time-password-totp-generator-in-go-a-deep-dive-into-2fa-implementation-043c1000e09f
@@ -28,6 +32,10 @@ import (
 // - string: the generated secret in base32 encoding
 // - error: if any error occurs during the process
 func NewSecret(length int) (string, error) {
+	if length <= 0 {
+		return "", newError("NewSecret", ErrInvalidSecretLength)
+	}
+
 	// Create a byte slice with the length of N bytes
 	secret := make([]byte, length)
 
@@ -61,7 +69,7 @@ func ComputeHMAC(secret string, msg uint64, hashFn func() hash.Hash) (
 	secret = strings.ToUpper(secret)
 	secretByte, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
 	if err != nil {
-		return nil, err
+		return nil, newError("ComputeHMAC", ErrInvalidEncoding)
 	}
 
 	// Create a byte with the message value
@@ -96,6 +104,10 @@ func ComputeTimedHMAC(
 	period uint64,
 	hashFn func() hash.Hash,
 ) ([]byte, error) {
+	if period == 0 {
+		return nil, newError("ComputeTimedHMAC", ErrInvalidPeriod)
+	}
+
 	// Compute the message value
 	msg := uint64(computeTime.Unix()) / period
 
@@ -135,11 +147,10 @@ func ComputeTimedHMACSha1(secret string, computeTime time.Time, period uint64) (
 // - error: if any error occurs during the process
 func Truncate(truncateHash []byte, digits int) (string, error) {
 	if digits < DigitCountStart || digits > DigitCountEnd {
-		return "", fmt.Errorf(
-			"digit count must be between %v and %v",
-			DigitCountStart,
-			DigitCountEnd,
-		)
+		return "", newError("Truncate", ErrInvalidDigitCount)
+	}
+	if len(truncateHash) < minTruncateHashLength {
+		return "", newError("Truncate", ErrInvalidHashLength)
 	}
 
 	// Calculate the offset from the last byte of the hash. The offset is the last 4 bits of the last byte
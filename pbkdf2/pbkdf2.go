@@ -3,10 +3,12 @@ package pbkdf2
 import (
 	"hash"
 
+	gocrypto "github.com/ralvarezdev/go-crypto"
 	"golang.org/x/crypto/pbkdf2"
 )
 
-// DeriveKey derives a key from the password using the PBKDF2 algorithm
+// DeriveKey derives a key from the password using the PBKDF2 algorithm. To derive keys from a high-entropy master
+// key instead of a password, use the hkdf package
 //
 // Parameters:
 //
@@ -26,5 +28,60 @@ func DeriveKey(
 	keyLength int,
 	hashFn func() hash.Hash,
 ) []byte {
-	return pbkdf2.Key([]byte(password), salt, iterations, keyLength, hashFn)
+	return DeriveKeyBytes([]byte(password), salt, iterations, keyLength, hashFn)
+}
+
+// DeriveKeyBytes derives a key from the password bytes using the PBKDF2 algorithm, so the password can be cleared
+// after use
+//
+// Parameters:
+//
+//   - password: the password to derive the key from
+//   - salt: the salt to use for the key derivation
+//   - iterations: the number of iterations to use for the key derivation
+//   - keyLength: the length of the derived key in bytes
+//   - hashFn: the hash function to use for the key derivation (e.g., sha256.New)
+//
+// Returns:
+//
+//   - the derived key as a byte slice
+func DeriveKeyBytes(
+	password []byte,
+	salt []byte,
+	iterations int,
+	keyLength int,
+	hashFn func() hash.Hash,
+) []byte {
+	return pbkdf2.Key(password, salt, iterations, keyLength, hashFn)
+}
+
+// DeriveSecretKey derives a key from the password held by a SecretKey using the PBKDF2 algorithm
+//
+// Parameters:
+//
+//   - password: the password to derive the key from
+//   - salt: the salt to use for the key derivation
+//   - iterations: the number of iterations to use for the key derivation
+//   - keyLength: the length of the derived key in bytes
+//   - hashFn: the hash function to use for the key derivation (e.g., sha256.New)
+//
+// Returns:
+//
+//   - the derived key as a SecretKey
+//   - an error if the password is nil or was destroyed
+func DeriveSecretKey(
+	password *gocrypto.SecretKey,
+	salt []byte,
+	iterations int,
+	keyLength int,
+	hashFn func() hash.Hash,
+) (*gocrypto.SecretKey, error) {
+	passwordBytes, err := password.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	key := DeriveKeyBytes(passwordBytes, salt, iterations, keyLength, hashFn)
+	defer clear(key)
+	return gocrypto.NewSecretKey(key), nil
 }
@@ -0,0 +1,195 @@
+package gocrypto
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"runtime"
+)
+
+// redacted is the text that replaces the key material of a SecretKey in any output
+const redacted = "[REDACTED]"
+
+type (
+	// SecretKey holds key material, such as an encryption key or a password, that is redacted from fmt, JSON and slog
+	// output and can be zeroized with Destroy. A SecretKey must not be destroyed while it is in use
+	SecretKey struct {
+		key       []byte
+		destroyed bool
+	}
+)
+
+// NewSecretKey creates a new SecretKey that owns a copy of the key material. The caller should clear the given slice
+// once it is no longer needed
+//
+// Parameters:
+//
+//   - key: The key material
+//
+// Returns:
+//
+//   - A pointer to the SecretKey
+func NewSecretKey(key []byte) *SecretKey {
+	return &SecretKey{key: append(make([]byte, 0, len(key)), key...)}
+}
+
+// NewSecretKeyFromString creates a new SecretKey from a string, such as a password
+//
+// Parameters:
+//
+//   - key: The key material
+//
+// Returns:
+//
+//   - A pointer to the SecretKey
+func NewSecretKeyFromString(key string) *SecretKey {
+	return &SecretKey{key: []byte(key)}
+}
+
+// GenerateSecretKey creates a new SecretKey with random key material
+//
+// Parameters:
+//
+//   - size: The size in bytes of the key (e.g., 32 for AES-256)
+//
+// Returns:
+//
+//   - A pointer to the SecretKey
+//   - An error if the random bytes cannot be generated
+func GenerateSecretKey(size int) (*SecretKey, error) {
+	if size <= 0 {
+		return nil, newError("GenerateSecretKey", ErrInvalidSecretKeySize)
+	}
+
+	key := make([]byte, size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return &SecretKey{key: key}, nil
+}
+
+// Bytes returns the key material. The returned slice is shared with the SecretKey, so it must not be modified nor
+// retained, and it is zeroized by Destroy
+//
+// Returns:
+//
+//   - The key material
+//   - ErrNilSecretKey if the SecretKey is nil, or ErrSecretKeyDestroyed if it was destroyed
+func (s *SecretKey) Bytes() ([]byte, error) {
+	if s == nil {
+		return nil, newError("SecretKey.Bytes", ErrNilSecretKey)
+	}
+	if s.destroyed {
+		return nil, newError("SecretKey.Bytes", ErrSecretKeyDestroyed)
+	}
+	return s.key, nil
+}
+
+// Len returns the size in bytes of the key material, or zero if the SecretKey was destroyed
+//
+// Returns:
+//
+//   - The size in bytes of the key material
+func (s *SecretKey) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.key)
+}
+
+// Equal reports whether both SecretKeys hold the same key material, in constant time. Destroyed SecretKeys are never
+// equal
+//
+// Parameters:
+//
+//   - other: The other SecretKey
+//
+// Returns:
+//
+//   - True if the key material is the same
+func (s *SecretKey) Equal(other *SecretKey) bool {
+	if s == nil || other == nil || s.destroyed || other.destroyed {
+		return false
+	}
+	return subtle.ConstantTimeCompare(s.key, other.key) == 1
+}
+
+// Destroy zeroizes the key material. It is safe to call more than once
+func (s *SecretKey) Destroy() {
+	if s == nil {
+		return
+	}
+	clear(s.key)
+
+	// Keep the key material alive until it is cleared, so the write is not optimized away
+	runtime.KeepAlive(s.key)
+	s.key = nil
+	s.destroyed = true
+}
+
+// IsDestroyed reports whether the SecretKey was destroyed
+//
+// Returns:
+//
+//   - True if the SecretKey was destroyed
+func (s *SecretKey) IsDestroyed() bool {
+	return s != nil && s.destroyed
+}
+
+// String returns the redacted key
+//
+// Returns:
+//
+//   - The redacted text
+func (s SecretKey) String() string {
+	return redacted
+}
+
+// GoString returns the redacted key for the %#v verb
+//
+// Returns:
+//
+//   - The redacted text
+func (s SecretKey) GoString() string {
+	return redacted
+}
+
+// Format writes the redacted key for every fmt verb, including %x and %q
+//
+// Parameters:
+//
+//   - state: The fmt state
+//   - verb: The fmt verb
+func (s SecretKey) Format(state fmt.State, verb rune) {
+	_, _ = state.Write([]byte(redacted))
+}
+
+// MarshalJSON returns the redacted key as a JSON string
+//
+// Returns:
+//
+//   - The JSON string
+//   - Never an error
+func (s SecretKey) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+// MarshalText returns the redacted key, for encoders that use encoding.TextMarshaler
+//
+// Returns:
+//
+//   - The redacted text
+//   - Never an error
+func (s SecretKey) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// LogValue returns the redacted key for log/slog
+//
+// Returns:
+//
+//   - The slog value
+func (s SecretKey) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
@@ -0,0 +1,173 @@
+package gocrypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// testSecretKey is key material that is easy to spot in any output
+var testSecretKey = []byte("super secret key material 123456")
+
+// checkRedacted checks that the output contains the redacted text and no trace of the test key material
+func checkRedacted(t *testing.T, name, output string) {
+	t.Helper()
+
+	if !strings.Contains(output, redacted) {
+		t.Fatalf("%s = %q, want %q", name, output, redacted)
+	}
+	for _, leak := range []string{
+		string(testSecretKey),
+		hex.EncodeToString(testSecretKey),
+		strings.ToUpper(hex.EncodeToString(testSecretKey)),
+		"super",
+	} {
+		if strings.Contains(output, leak) {
+			t.Fatalf("%s = %q, leaks the key material", name, output)
+		}
+	}
+}
+
+func TestSecretKeyRedactedFmt(t *testing.T) {
+	secretKey := NewSecretKey(testSecretKey)
+	wrapper := struct {
+		Name  string
+		Key   *SecretKey
+		Value SecretKey
+	}{Name: "wrapper", Key: secretKey, Value: *secretKey}
+
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%X", "%d"} {
+		checkRedacted(t, "Sprintf("+verb+") of a pointer", fmt.Sprintf(verb, secretKey))
+		checkRedacted(t, "Sprintf("+verb+") of a value", fmt.Sprintf(verb, *secretKey))
+		checkRedacted(t, "Sprintf("+verb+") of a struct", fmt.Sprintf(verb, wrapper))
+	}
+	checkRedacted(t, "Sprint", fmt.Sprint(secretKey))
+	checkRedacted(t, "Sprintln", fmt.Sprintln(secretKey, *secretKey))
+	checkRedacted(t, "error", fmt.Errorf("using key %v", secretKey).Error())
+}
+
+func TestSecretKeyRedactedJSON(t *testing.T) {
+	secretKey := NewSecretKey(testSecretKey)
+
+	data, err := json.Marshal(
+		struct {
+			Key   *SecretKey     `json:"key"`
+			Value SecretKey      `json:"value"`
+			Map   map[string]any `json:"map"`
+			Keys  []*SecretKey   `json:"keys"`
+		}{
+			Key:   secretKey,
+			Value: *secretKey,
+			Map:   map[string]any{"key": secretKey},
+			Keys:  []*SecretKey{secretKey},
+		},
+	)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	checkRedacted(t, "json.Marshal", string(data))
+	if got := strings.Count(string(data), redacted); got != 4 {
+		t.Fatalf("json.Marshal = %s, want 4 redacted values", data)
+	}
+}
+
+func TestSecretKeyRedactedSlog(t *testing.T) {
+	secretKey := NewSecretKey(testSecretKey)
+
+	var buffer bytes.Buffer
+	for name, handler := range map[string]slog.Handler{
+		"JSON": slog.NewJSONHandler(&buffer, nil),
+		"text": slog.NewTextHandler(&buffer, nil),
+	} {
+		buffer.Reset()
+		logger := slog.New(handler)
+		logger.Info(
+			"encrypting",
+			"key", secretKey,
+			slog.Any("value", *secretKey),
+			slog.Group("group", "key", secretKey),
+		)
+		logger.With("key", secretKey).Info("with")
+		checkRedacted(t, name+" handler", buffer.String())
+	}
+}
+
+func TestSecretKeyDestroy(t *testing.T) {
+	secretKey := NewSecretKey(testSecretKey)
+	key, err := secretKey.Bytes()
+	if err != nil {
+		t.Fatalf("SecretKey.Bytes: %v", err)
+	}
+	if !bytes.Equal(key, testSecretKey) {
+		t.Fatalf("SecretKey.Bytes = %q, want %q", key, testSecretKey)
+	}
+
+	// The key material is zeroized in place
+	other := NewSecretKey(testSecretKey)
+	secretKey.Destroy()
+	if !bytes.Equal(key, make([]byte, len(testSecretKey))) {
+		t.Fatalf("SecretKey.Destroy left the key material %x", key)
+	}
+	if !secretKey.IsDestroyed() || secretKey.Len() != 0 {
+		t.Fatalf("SecretKey.IsDestroyed = %v, SecretKey.Len = %d", secretKey.IsDestroyed(), secretKey.Len())
+	}
+	if _, err = secretKey.Bytes(); !errors.Is(err, ErrSecretKeyDestroyed) {
+		t.Fatalf("SecretKey.Bytes after Destroy: got %v, want %v", err, ErrSecretKeyDestroyed)
+	}
+	if secretKey.Equal(other) || other.Equal(secretKey) {
+		t.Fatalf("SecretKey.Equal of a destroyed key returned true")
+	}
+
+	// Destroy is idempotent and safe on nil keys
+	secretKey.Destroy()
+	var nilKey *SecretKey
+	nilKey.Destroy()
+	if _, err = nilKey.Bytes(); !errors.Is(err, ErrNilSecretKey) {
+		t.Fatalf("SecretKey.Bytes of a nil key: got %v, want %v", err, ErrNilSecretKey)
+	}
+}
+
+func TestSecretKeyOwnsCopy(t *testing.T) {
+	material := bytes.Clone(testSecretKey)
+	secretKey := NewSecretKey(material)
+	clear(material)
+
+	key, err := secretKey.Bytes()
+	if err != nil {
+		t.Fatalf("SecretKey.Bytes: %v", err)
+	}
+	if !bytes.Equal(key, testSecretKey) {
+		t.Fatalf("SecretKey.Bytes = %q after the given slice was cleared, want %q", key, testSecretKey)
+	}
+	if !secretKey.Equal(NewSecretKeyFromString(string(testSecretKey))) {
+		t.Fatalf("SecretKey.Equal of the same key material returned false")
+	}
+}
+
+func TestGenerateSecretKey(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		if _, err := GenerateSecretKey(size); !errors.Is(
+			err,
+			ErrInvalidSecretKeySize,
+		) {
+			t.Fatalf("GenerateSecretKey(%d): got %v, want %v", size, err, ErrInvalidSecretKeySize)
+		}
+	}
+
+	first, err := GenerateSecretKey(32)
+	if err != nil {
+		t.Fatalf("GenerateSecretKey: %v", err)
+	}
+	second, err := GenerateSecretKey(32)
+	if err != nil {
+		t.Fatalf("GenerateSecretKey: %v", err)
+	}
+	if first.Len() != 32 || first.Equal(second) {
+		t.Fatalf("GenerateSecretKey returned %d bytes, equal keys %v", first.Len(), first.Equal(second))
+	}
+}
@@ -0,0 +1,57 @@
+package structs
+
+import (
+	"errors"
+	"fmt"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// packageName is the package name reported by the errors of the package
+const packageName = "structs"
+
+var (
+	ErrNilCipher            = errors.New("cipher is nil")
+	ErrInvalidStruct        = errors.New("value must be a non-nil pointer to a struct")
+	ErrInvalidTag           = errors.New("invalid crypto struct tag")
+	ErrUnsupportedFieldType = errors.New("encrypted field must be a string or a byte slice")
+	ErrInvalidEncoding      = gocrypto.ErrInvalidEncoding
+)
+
+// newError wraps an error with the operation that failed and the package name
+//
+// Parameters:
+//
+//   - op: The name of the operation
+//   - err: The error to wrap
+//
+// Returns:
+//
+//   - The wrapped error, or nil if err is nil
+func newError(op string, err error) error {
+	return gocrypto.NewError(packageName, op, err)
+}
+
+// newFieldError wraps an error of a struct field with the operation that failed, the package name and the field
+// path. Unlike newError, it also wraps errors that already carry the context of another package, such as those
+// returned by the cipher, so the failing field is always reported
+//
+// Parameters:
+//
+//   - op: The name of the operation
+//   - path: The field path
+//   - err: The error to wrap
+//
+// Returns:
+//
+//   - The wrapped error, or nil if err is nil
+func newFieldError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &gocrypto.Error{
+		Package: packageName,
+		Op:      op,
+		Err:     fmt.Errorf("%s: %w", path, err),
+	}
+}
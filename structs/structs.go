@@ -0,0 +1,362 @@
+package structs
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strings"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+)
+
+// Fields are selected for encryption with the crypto struct tag:
+//
+//	type User struct {
+//		Email   string  `crypto:"encrypt"`
+//		SSN     string  `crypto:"encrypt,aad"`
+//		Avatar  []byte  `crypto:"encrypt"`
+//		Address *Address
+//	}
+//
+// Tagged fields must be strings or byte slices, or pointers to them. Encrypted strings hold the cipher text in
+// hexadecimal format and encrypted byte slices hold the raw cipher text. Empty values are left as is, so optional fields
+// stay empty.
+//
+// With the aad option, the field path (the names of the fields from the root struct joined by dots, e.g.
+// "Address.Street") is bound to the cipher text as additional authenticated data, so an encrypted value cannot be
+// copied into another field. Slice and array indexes are not part of the path, so elements can be reordered.
+//
+// Nested structs, pointers, slices, arrays and interfaces holding pointers are traversed. Maps and unexported fields are
+// not traversed.
+
+const (
+	// TagName is the name of the struct tag that selects the fields to encrypt
+	TagName = "crypto"
+
+	// TagEncrypt is the struct tag value that marks a field to encrypt
+	TagEncrypt = "encrypt"
+
+	// TagOptionAAD is the struct tag option that binds the field path as additional authenticated data
+	TagOptionAAD = "aad"
+)
+
+type (
+	// field is a tagged field found while walking a struct
+	field struct {
+		value reflect.Value
+		path  string
+		aad   bool
+	}
+
+	// visitKey identifies an addressable value that was already visited
+	visitKey struct {
+		pointer uintptr
+		typ     reflect.Type
+	}
+
+	// walker collects the tagged fields of a struct
+	walker struct {
+		fields  []field
+		visited map[visitKey]struct{}
+	}
+)
+
+// parseTag parses the crypto struct tag of a field
+//
+// Parameters:
+//
+//   - tag: The tag value
+//
+// Returns:
+//
+//   - True if the field path must be bound as additional authenticated data, false otherwise
+//   - ErrInvalidTag if the tag is not valid
+func parseTag(tag string) (bool, error) {
+	options := strings.Split(tag, ",")
+	if options[0] != TagEncrypt {
+		return false, ErrInvalidTag
+	}
+
+	aad := false
+	for _, option := range options[1:] {
+		if option != TagOptionAAD {
+			return false, ErrInvalidTag
+		}
+		aad = true
+	}
+	return aad, nil
+}
+
+// visit marks an addressable value as visited, so values shared through pointers are processed only once
+//
+// Parameters:
+//
+//   - value: The addressable value
+//
+// Returns:
+//
+//   - True if the value was not visited before, false otherwise
+func (w *walker) visit(value reflect.Value) bool {
+	key := visitKey{
+		pointer: value.Addr().Pointer(),
+		typ:     value.Type(),
+	}
+	if _, ok := w.visited[key]; ok {
+		return false
+	}
+	w.visited[key] = struct{}{}
+	return true
+}
+
+// walk collects the tagged fields reachable from the value
+//
+// Parameters:
+//
+//   - value: The value to walk
+//   - path: The field path of the value
+//
+// Returns:
+//
+//   - An error if a tag is not valid or a tagged field has an unsupported type
+func (w *walker) walk(value reflect.Value, path string) error {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() || !w.visit(value.Elem()) {
+			return nil
+		}
+		return w.walk(value.Elem(), path)
+	case reflect.Interface:
+		if value.IsNil() || value.Elem().Kind() != reflect.Pointer {
+			return nil
+		}
+		return w.walk(value.Elem(), path)
+	case reflect.Slice, reflect.Array:
+		switch value.Type().Elem().Kind() {
+		case reflect.Struct, reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Array:
+		default:
+			return nil
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := w.walk(value.Index(i), path); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		structType := value.Type()
+		for i := 0; i < structType.NumField(); i++ {
+			structField := structType.Field(i)
+			if !structField.IsExported() {
+				continue
+			}
+
+			// Get the field path
+			fieldPath := structField.Name
+			if path != "" {
+				fieldPath = path + "." + structField.Name
+			}
+
+			// Walk the untagged fields
+			tag, ok := structField.Tag.Lookup(TagName)
+			if !ok {
+				if err := w.walk(value.Field(i), fieldPath); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Collect the tagged fields
+			aad, err := parseTag(tag)
+			if err != nil {
+				return newFieldError("collectFields", fieldPath, err)
+			}
+			if err = w.collect(value.Field(i), fieldPath, aad); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// collect adds a tagged field to the fields to process
+//
+// Parameters:
+//
+//   - value: The field value
+//   - path: The field path
+//   - aad: True if the field path must be bound as additional authenticated data, false otherwise
+//
+// Returns:
+//
+//   - ErrUnsupportedFieldType if the field is not a string or a byte slice, or a pointer to them
+func (w *walker) collect(value reflect.Value, path string, aad bool) error {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch {
+	case value.Kind() == reflect.String:
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8:
+	default:
+		return newFieldError("collectFields", path, ErrUnsupportedFieldType)
+	}
+
+	if w.visit(value) {
+		w.fields = append(w.fields, field{value: value, path: path, aad: aad})
+	}
+	return nil
+}
+
+// collectFields collects the tagged fields of the struct pointed to by v
+//
+// Parameters:
+//
+//   - v: A non-nil pointer to a struct
+//   - cipher: The cipher
+//
+// Returns:
+//
+//   - The tagged fields
+//   - An error if v is not a pointer to a struct, the cipher is nil or a tag is not valid
+func collectFields(v any, cipher gocrypto.Cipher) ([]field, error) {
+	if cipher == nil {
+		return nil, newError("collectFields", ErrNilCipher)
+	}
+
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil, newError("collectFields", ErrInvalidStruct)
+	}
+
+	w := &walker{visited: make(map[visitKey]struct{})}
+	w.visit(value.Elem())
+	if err := w.walk(value.Elem(), ""); err != nil {
+		return nil, err
+	}
+	return w.fields, nil
+}
+
+// additionalData returns the additional authenticated data of a field
+//
+// Returns:
+//
+//   - The field path if it must be bound, nil otherwise
+func (f *field) additionalData() []byte {
+	if !f.aad {
+		return nil
+	}
+	return []byte(f.path)
+}
+
+// EncryptStruct encrypts in place the tagged string and byte slice fields of a struct. The fields are only modified
+// if all of them were encrypted successfully
+//
+// Parameters:
+//
+//   - v: A non-nil pointer to the struct
+//   - cipher: The cipher to use for encryption, e.g. created with gocrypto.NewCipher or aes.NewKeyRingCipher
+//
+// Returns:
+//
+//   - An error if any occurred during the encryption process, prefixed with the field path when it is field specific
+func EncryptStruct(v any, cipher gocrypto.Cipher) error {
+	// Collect the tagged fields
+	fields, err := collectFields(v, cipher)
+	if err != nil {
+		return err
+	}
+
+	// Encrypt every field before modifying any of them
+	cipherTexts := make([][]byte, len(fields))
+	for i, f := range fields {
+		var plainText []byte
+		if f.value.Kind() == reflect.String {
+			plainText = []byte(f.value.String())
+		} else {
+			plainText = f.value.Bytes()
+		}
+		if len(plainText) == 0 {
+			continue
+		}
+
+		if cipherTexts[i], err = cipher.Encrypt(
+			plainText,
+			f.additionalData(),
+		); err != nil {
+			return newFieldError("EncryptStruct", f.path, err)
+		}
+	}
+
+	// Set the encrypted fields
+	for i, f := range fields {
+		if cipherTexts[i] == nil {
+			continue
+		}
+		if f.value.Kind() == reflect.String {
+			f.value.SetString(hex.EncodeToString(cipherTexts[i]))
+		} else {
+			f.value.SetBytes(cipherTexts[i])
+		}
+	}
+	return nil
+}
+
+// DecryptStruct decrypts in place the tagged string and byte slice fields of a struct encrypted with EncryptStruct.
+// The fields are only modified if all of them were decrypted successfully
+//
+// Parameters:
+//
+//   - v: A non-nil pointer to the struct
+//   - cipher: The cipher used for encryption
+//
+// Returns:
+//
+//   - An error if any occurred during the decryption process, prefixed with the field path when it is field specific
+func DecryptStruct(v any, cipher gocrypto.Cipher) error {
+	// Collect the tagged fields
+	fields, err := collectFields(v, cipher)
+	if err != nil {
+		return err
+	}
+
+	// Decrypt every field before modifying any of them
+	plainTexts := make([][]byte, len(fields))
+	for i, f := range fields {
+		var cipherText []byte
+		if f.value.Kind() == reflect.String {
+			if cipherText, err = hex.DecodeString(f.value.String()); err != nil {
+				return newFieldError(
+					"DecryptStruct",
+					f.path,
+					ErrInvalidEncoding,
+				)
+			}
+		} else {
+			cipherText = f.value.Bytes()
+		}
+		if len(cipherText) == 0 {
+			continue
+		}
+
+		if plainTexts[i], err = cipher.Decrypt(
+			cipherText,
+			f.additionalData(),
+		); err != nil {
+			return newFieldError("DecryptStruct", f.path, err)
+		}
+	}
+
+	// Set the decrypted fields
+	for i, f := range fields {
+		if plainTexts[i] == nil {
+			continue
+		}
+		if f.value.Kind() == reflect.String {
+			f.value.SetString(string(plainTexts[i]))
+		} else {
+			f.value.SetBytes(plainTexts[i])
+		}
+	}
+	return nil
+}
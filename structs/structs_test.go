@@ -0,0 +1,278 @@
+package structs
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+
+	gocrypto "github.com/ralvarezdev/go-crypto"
+	gocryptoaes "github.com/ralvarezdev/go-crypto/aes"
+)
+
+type (
+	// testAddress is a nested struct with an encrypted field
+	testAddress struct {
+		Street string `crypto:"encrypt,aad"`
+		City   string
+	}
+
+	// testContact is an element of a slice with an encrypted field
+	testContact struct {
+		Phone *string `crypto:"encrypt"`
+	}
+
+	// testUser is the struct encrypted by the tests
+	testUser struct {
+		Name     string
+		Email    string `crypto:"encrypt,aad"`
+		SSN      string `crypto:"encrypt,aad"`
+		Avatar   []byte `crypto:"encrypt"`
+		Nickname string `crypto:"encrypt"`
+		Address  *testAddress
+		Contacts []testContact
+		Previous []*testAddress
+		Extra    any
+		internal string
+	}
+
+	// failingCipher is a cipher that fails to encrypt a given plain text
+	failingCipher struct {
+		gocrypto.Cipher
+		plainText string
+	}
+)
+
+// errTestCipher is the error returned by failingCipher
+var errTestCipher = errors.New("test cipher failure")
+
+// Encrypt encrypts the plain text, failing if it is the configured plain text
+func (f *failingCipher) Encrypt(plainText, additionalData []byte) ([]byte, error) {
+	if string(plainText) == f.plainText {
+		return nil, errTestCipher
+	}
+	return f.Cipher.Encrypt(plainText, additionalData)
+}
+
+// newTestCipher creates an AES-GCM cipher with a random key
+func newTestCipher(t *testing.T) gocrypto.Cipher {
+	t.Helper()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+	cipher, err := gocrypto.NewCipher(gocryptoaes.CipherNameGCM, key)
+	if err != nil {
+		t.Fatalf("gocrypto.NewCipher: %v", err)
+	}
+	return cipher
+}
+
+// newTestUser creates a user whose encrypted fields are reached through pointers, slices and interfaces
+func newTestUser() *testUser {
+	phone := "+1 555 0100"
+	return &testUser{
+		Name:     "Jane",
+		Email:    "jane@example.com",
+		SSN:      "078-05-1120",
+		Avatar:   []byte{0x89, 'P', 'N', 'G'},
+		Address:  &testAddress{Street: "1 Main St", City: "Springfield"},
+		Contacts: []testContact{{Phone: &phone}, {}},
+		Previous: []*testAddress{{Street: "2 Elm St"}, nil},
+		Extra:    &testAddress{Street: "3 Oak St"},
+		internal: "not traversed",
+	}
+}
+
+func TestStructRoundTrip(t *testing.T) {
+	cipher := newTestCipher(t)
+	user := newTestUser()
+	want := newTestUser()
+
+	if err := EncryptStruct(user, cipher); err != nil {
+		t.Fatalf("EncryptStruct: %v", err)
+	}
+
+	// Every tagged field is encrypted, through pointers, slices and interfaces
+	encrypted := map[string][2]string{
+		"Email":           {user.Email, want.Email},
+		"SSN":             {user.SSN, want.SSN},
+		"Avatar":          {string(user.Avatar), string(want.Avatar)},
+		"Address.Street":  {user.Address.Street, want.Address.Street},
+		"Contacts.Phone":  {*user.Contacts[0].Phone, *want.Contacts[0].Phone},
+		"Previous.Street": {user.Previous[0].Street, want.Previous[0].Street},
+		"Extra.Street":    {user.Extra.(*testAddress).Street, want.Extra.(*testAddress).Street},
+	}
+	for path, values := range encrypted {
+		if values[0] == values[1] {
+			t.Fatalf("EncryptStruct did not encrypt %s", path)
+		}
+	}
+
+	// Untagged and empty fields are left as is
+	if user.Name != want.Name || user.Address.City != want.Address.City || user.internal != want.internal {
+		t.Fatalf("EncryptStruct modified an untagged field")
+	}
+	if user.Nickname != "" || user.Contacts[1].Phone != nil || user.Previous[1] != nil {
+		t.Fatalf("EncryptStruct modified an empty field")
+	}
+
+	if err := DecryptStruct(user, cipher); err != nil {
+		t.Fatalf("DecryptStruct: %v", err)
+	}
+	if user.Email != want.Email || user.SSN != want.SSN || !bytes.Equal(user.Avatar, want.Avatar) ||
+		user.Address.Street != want.Address.Street || *user.Contacts[0].Phone != *want.Contacts[0].Phone ||
+		user.Previous[0].Street != want.Previous[0].Street ||
+		user.Extra.(*testAddress).Street != want.Extra.(*testAddress).Street {
+		t.Fatalf("DecryptStruct = %+v, want %+v", user, want)
+	}
+}
+
+func TestStructSharedPointer(t *testing.T) {
+	cipher := newTestCipher(t)
+
+	// A value reachable through several pointers is encrypted once
+	address := &testAddress{Street: "1 Main St"}
+	user := &testUser{Address: address, Previous: []*testAddress{address}, Extra: address}
+	if err := EncryptStruct(user, cipher); err != nil {
+		t.Fatalf("EncryptStruct: %v", err)
+	}
+	if err := DecryptStruct(user, cipher); err != nil {
+		t.Fatalf("DecryptStruct: %v", err)
+	}
+	if address.Street != "1 Main St" {
+		t.Fatalf("DecryptStruct = %q, want %q", address.Street, "1 Main St")
+	}
+}
+
+func TestStructAADBindsFieldPath(t *testing.T) {
+	cipher := newTestCipher(t)
+	user := newTestUser()
+	if err := EncryptStruct(user, cipher); err != nil {
+		t.Fatalf("EncryptStruct: %v", err)
+	}
+
+	// An encrypted value copied into another field bound to its path is rejected
+	user.Email, user.SSN = user.SSN, user.Email
+	err := DecryptStruct(user, cipher)
+	if !errors.Is(err, gocrypto.ErrAuthenticationFailed) {
+		t.Fatalf("DecryptStruct with swapped fields: got %v, want %v", err, gocrypto.ErrAuthenticationFailed)
+	}
+	if !strings.Contains(err.Error(), "Email") {
+		t.Fatalf("DecryptStruct error %q does not report the field path", err)
+	}
+}
+
+func TestEncryptStructRollback(t *testing.T) {
+	// The last field fails, after the previous ones were encrypted
+	cipher := &failingCipher{Cipher: newTestCipher(t), plainText: "3 Oak St"}
+	user := newTestUser()
+
+	err := EncryptStruct(user, cipher)
+	if !errors.Is(err, errTestCipher) {
+		t.Fatalf("EncryptStruct: got %v, want %v", err, errTestCipher)
+	}
+	var cryptoErr *gocrypto.Error
+	if !errors.As(err, &cryptoErr) || cryptoErr.Package != packageName || cryptoErr.Op != "EncryptStruct" {
+		t.Fatalf("EncryptStruct error %v does not carry the package and the operation", err)
+	}
+	if !strings.Contains(err.Error(), "Extra.Street") {
+		t.Fatalf("EncryptStruct error %q does not report the field path", err)
+	}
+
+	// No field was modified
+	want := newTestUser()
+	if user.Email != want.Email || user.SSN != want.SSN || !bytes.Equal(user.Avatar, want.Avatar) ||
+		user.Address.Street != want.Address.Street || *user.Contacts[0].Phone != *want.Contacts[0].Phone ||
+		user.Previous[0].Street != want.Previous[0].Street {
+		t.Fatalf("EncryptStruct modified fields before failing: %+v", user)
+	}
+}
+
+func TestDecryptStructRollback(t *testing.T) {
+	cipher := newTestCipher(t)
+	user := newTestUser()
+	if err := EncryptStruct(user, cipher); err != nil {
+		t.Fatalf("EncryptStruct: %v", err)
+	}
+	encrypted := *user
+	encryptedStreet := user.Address.Street
+
+	// Tamper the last field, after the previous ones were decrypted
+	extra := user.Extra.(*testAddress)
+	extra.Street = extra.Street[:len(extra.Street)-2]
+	if err := DecryptStruct(user, cipher); !errors.Is(
+		err,
+		gocrypto.ErrAuthenticationFailed,
+	) {
+		t.Fatalf("DecryptStruct: got %v, want %v", err, gocrypto.ErrAuthenticationFailed)
+	}
+
+	// No field was modified
+	if user.Email != encrypted.Email || user.SSN != encrypted.SSN || !bytes.Equal(user.Avatar, encrypted.Avatar) ||
+		user.Address.Street != encryptedStreet {
+		t.Fatalf("DecryptStruct modified fields before failing: %+v", user)
+	}
+
+	// An invalid hexadecimal string is reported as an encoding error
+	user.Email = "not hexadecimal"
+	if err := DecryptStruct(user, cipher); !errors.Is(err, ErrInvalidEncoding) {
+		t.Fatalf("DecryptStruct of an invalid encoding: got %v, want %v", err, ErrInvalidEncoding)
+	}
+}
+
+func TestStructInvalid(t *testing.T) {
+	cipher := newTestCipher(t)
+
+	type invalidTag struct {
+		Email string `crypto:"encrypt,unknown"`
+	}
+	type unsupportedType struct {
+		Age int `crypto:"encrypt"`
+	}
+	type nestedInvalid struct {
+		Inner *unsupportedType
+	}
+
+	tests := []struct {
+		name   string
+		v      any
+		cipher gocrypto.Cipher
+		err    error
+		path   string
+	}{
+		{name: "nil cipher", v: &testUser{}, err: ErrNilCipher},
+		{name: "not a pointer", v: testUser{}, cipher: cipher, err: ErrInvalidStruct},
+		{name: "nil pointer", v: (*testUser)(nil), cipher: cipher, err: ErrInvalidStruct},
+		{name: "not a struct", v: new(string), cipher: cipher, err: ErrInvalidStruct},
+		{name: "invalid tag", v: &invalidTag{}, cipher: cipher, err: ErrInvalidTag, path: "Email"},
+		{name: "unsupported type", v: &unsupportedType{}, cipher: cipher, err: ErrUnsupportedFieldType, path: "Age"},
+		{
+			name:   "nested unsupported type",
+			v:      &nestedInvalid{Inner: &unsupportedType{}},
+			cipher: cipher,
+			err:    ErrUnsupportedFieldType,
+			path:   "Inner.Age",
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				for name, process := range map[string]func(any, gocrypto.Cipher) error{
+					"EncryptStruct": EncryptStruct,
+					"DecryptStruct": DecryptStruct,
+				} {
+					err := process(test.v, test.cipher)
+					if !errors.Is(err, test.err) {
+						t.Fatalf("%s: got %v, want %v", name, err, test.err)
+					}
+					if !strings.Contains(err.Error(), test.path) {
+						t.Fatalf("%s error %q does not report the field path %q", name, err, test.path)
+					}
+				}
+			},
+		)
+	}
+}